@@ -2,8 +2,10 @@ package main
 
 import (
 	"net"
-	"time"
+	"net/http"
 
+	"chat-grpc/Auth-service/interceptor"
+	"chat-grpc/Auth-service/internal/client"
 	"chat-grpc/Auth-service/internal/handler"
 	"chat-grpc/Auth-service/internal/repository"
 	"chat-grpc/Auth-service/internal/usecase"
@@ -15,8 +17,22 @@ import (
 
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
+// publicMethods are called without an access token: to get one or to check one.
+var publicMethods = []string{
+	proto_gen.AuthService_Create_FullMethodName,
+	proto_gen.AuthService_Login_FullMethodName,
+	proto_gen.AuthService_GetRefreshToken_FullMethodName,
+	proto_gen.AuthService_GetAccessToken_FullMethodName,
+	proto_gen.AuthService_CheckToken_FullMethodName,
+	proto_gen.AuthService_AuthenticateBot_FullMethodName,
+	proto_gen.AuthService_GetJWKS_FullMethodName,
+	proto_gen.AuthService_RequestPasswordReset_FullMethodName,
+	proto_gen.AuthService_ResetPassword_FullMethodName,
+}
+
 func main() {
 	cfg := config.LoadConfig()
 	log, err := logger.NewLogger()
@@ -45,11 +61,45 @@ func main() {
 	}
 
 	repo := repository.NewAuthRepository(dbUser, dbAuth, log)
-	jwt := jwt.NewJWTService("key", 15*time.Minute, log)
-	usecase := usecase.NewAuthService(repo, jwt, log)
+	keys, err := loadKeys(cfg, log)
+	if err != nil {
+		log.Fatal("Failed to load signing keys", zap.Error(err))
+	}
+	jwt := jwt.NewJWTService(keys, cfg.JWTRefreshSecret, cfg.AccessTokenDuration, cfg.RefreshTokenDuration, log)
+	notificationConn, err := grpc.NewClient(cfg.NotificationServiceAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatal("Failed to connect to notification service", zap.Error(err))
+	}
+	defer notificationConn.Close()
+
+	usecase := usecase.NewAuthService(repo, jwt, client.NewNotificationClient(notificationConn), usecase.PasswordResetConfig{
+		URL: cfg.PasswordResetURL,
+		TTL: cfg.PasswordResetTTL,
+	}, log)
+	if err := usecase.LoadRevocations(); err != nil {
+		log.Fatal("Failed to load revocation list", zap.Error(err))
+	}
+	go usecase.SyncRevocations(cfg.RevocationSyncInterval)
+	if err := usecase.LoadPolicy(); err != nil {
+		log.Fatal("Failed to load access policy", zap.Error(err))
+	}
+	go usecase.SyncPolicy(cfg.PolicySyncInterval)
+	authInterceptor := interceptor.NewAuthInterceptor(handler.NewLocalChecker(usecase, cfg.ServiceToken), log, publicMethods...)
 	handler := handler.NewAuthHandler(usecase, log)
 
-	grpcServer := grpc.NewServer()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/jwks.json", handler.ServeJWKS)
+	go func() {
+		log.Info("JWKS is served on ", zap.String("port", cfg.AuthHTTPPort))
+		if err := http.ListenAndServe(":"+cfg.AuthHTTPPort, mux); err != nil {
+			log.Fatal("Failed to serve JWKS", zap.Error(err))
+		}
+	}()
+
+	grpcServer := grpc.NewServer(
+		grpc.UnaryInterceptor(authInterceptor.Unary()),
+		grpc.StreamInterceptor(authInterceptor.Stream()),
+	)
 	proto_gen.RegisterAuthServiceServer(grpcServer, handler)
 
 	log.Info("gRPC Auth Service is running on ", zap.String("port", cfg.ServerPortAuth))
@@ -57,3 +107,14 @@ func main() {
 		log.Fatal("Failed to serve: %v", zap.Error(err))
 	}
 }
+
+// loadKeys reads the signing keys from JWT_KEYS_DIR. Without it a key is made
+// up at start, which is only good for a single instance in development.
+func loadKeys(cfg *config.Config, log *zap.Logger) (*jwt.KeySet, error) {
+	if cfg.JWTKeysDir == "" {
+		log.Warn("JWT_KEYS_DIR is not set, access tokens are signed with an ephemeral key")
+		return jwt.GenerateKeys()
+	}
+
+	return jwt.LoadKeys(cfg.JWTKeysDir, cfg.JWTActiveKeyID)
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"strings"
 
-	"chat-grpc/proto_gen"
+	"chat-grpc/pkg/verifier"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -12,13 +12,42 @@ import (
 	"google.golang.org/grpc/status"
 )
 
+type claimsKey struct{}
+
+// ClaimsFromContext returns the claims of the token the request was made with.
+func ClaimsFromContext(ctx context.Context) (*verifier.Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*verifier.Claims)
+	return claims, ok
+}
+
+// Checker verifies tokens and permissions for the interceptor.
+type Checker interface {
+	// Authenticate returns the claims of a valid token that was not revoked.
+	Authenticate(ctx context.Context, token string) (*verifier.Claims, error)
+	// Authorize returns a PermissionDenied status if the token may not be used
+	// to call the method.
+	Authorize(ctx context.Context, token string, claims *verifier.Claims, method string) error
+}
+
 type AuthInterceptor struct {
-	authClient *AuthClient
-	log        *zap.Logger
+	checker Checker
+	public  map[string]bool
+	log     *zap.Logger
 }
 
-func NewAuthInterceptor(authClient *AuthClient, log *zap.Logger) *AuthInterceptor {
-	return &AuthInterceptor{authClient: authClient, log: log}
+// NewAuthInterceptor builds an interceptor that lets publicMethods, given as
+// full gRPC method names, through without a token.
+func NewAuthInterceptor(checker Checker, log *zap.Logger, publicMethods ...string) *AuthInterceptor {
+	public := make(map[string]bool, len(publicMethods))
+	for _, method := range publicMethods {
+		public[method] = true
+	}
+
+	return &AuthInterceptor{
+		checker: checker,
+		public:  public,
+		log:     log,
+	}
 }
 
 func (a *AuthInterceptor) Unary() grpc.UnaryServerInterceptor {
@@ -28,27 +57,90 @@ func (a *AuthInterceptor) Unary() grpc.UnaryServerInterceptor {
 		info *grpc.UnaryServerInfo,
 		handler grpc.UnaryHandler,
 	) (interface{}, error) {
-		md, ok := metadata.FromIncomingContext(ctx)
-		if !ok {
-			return nil, status.Error(codes.Unauthenticated, "metadata is not provided")
-		}
-
-		authHeaders := md.Get("authorization")
-		if len(authHeaders) == 0 {
-			return nil, status.Error(codes.Unauthenticated, "authorization token is missing")
+		ctx, err := a.authorize(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
 		}
 
-		tokenStr := authHeaders[0]
-		a.log.Info("Received authorization token", zap.String("token", tokenStr))
-		str := strings.Split(tokenStr, "Bearer ")
-		a.log.Info("Received authorization token", zap.String("token", str[1]))
+		return handler(ctx, req)
+	}
+}
 
-		err := a.authClient.CheckToken(ctx, &proto_gen.CheckTokenRequest{Token: str[1]})
+func (a *AuthInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		ctx, err := a.authorize(ss.Context(), info.FullMethod)
 		if err != nil {
-			a.log.Info("Authorization failed:", zap.Error(err))
-			return nil, status.Error(codes.PermissionDenied, "authorization failed")
+			return err
 		}
 
-		return handler(ctx, req)
+		return handler(srv, &authStream{ServerStream: ss, ctx: ctx})
 	}
 }
+
+// authStream hands the claims to the stream handler.
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authStream) Context() context.Context {
+	return s.ctx
+}
+
+// authorize lets public methods through and puts the claims of the token into
+// the context of the others. A missing or bad token is Unauthenticated, a valid
+// token that may not be used is PermissionDenied.
+func (a *AuthInterceptor) authorize(ctx context.Context, method string) (context.Context, error) {
+	if a.public[method] {
+		return ctx, nil
+	}
+
+	token, err := BearerToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := a.checker.Authenticate(ctx, token)
+	if err != nil {
+		a.log.Info("Authentication failed", zap.String("method", method), zap.Error(err))
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	err = a.checker.Authorize(ctx, token, claims, method)
+	if status.Code(err) == codes.PermissionDenied {
+		a.log.Info("Permission denied", zap.String("method", method), zap.Int64("userID", claims.UserID), zap.String("role", claims.Role))
+		return nil, status.Error(codes.PermissionDenied, "permission denied")
+	}
+	if err != nil {
+		a.log.Error("Failed to check permissions", zap.String("method", method), zap.Error(err))
+		return nil, status.Error(codes.Unavailable, "failed to check permissions")
+	}
+
+	return context.WithValue(ctx, claimsKey{}, claims), nil
+}
+
+// BearerToken reads the token of the "authorization: Bearer <token>" header.
+func BearerToken(ctx context.Context) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "metadata is not provided")
+	}
+
+	authHeaders := md.Get("authorization")
+	if len(authHeaders) == 0 {
+		return "", status.Error(codes.Unauthenticated, "authorization token is missing")
+	}
+
+	scheme, token, ok := strings.Cut(strings.TrimSpace(authHeaders[0]), " ")
+	token = strings.TrimSpace(token)
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", status.Error(codes.Unauthenticated, "authorization header must be \"Bearer <token>\"")
+	}
+
+	return token, nil
+}
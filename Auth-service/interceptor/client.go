@@ -3,9 +3,11 @@ package interceptor
 import (
 	"context"
 
+	"chat-grpc/pkg/verifier"
 	proto "chat-grpc/proto_gen"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 )
 
 type AuthClientInterface interface {
@@ -13,26 +15,46 @@ type AuthClientInterface interface {
 	GetChatUsers(ctx context.Context, chatID int64) ([]int64, error)
 }
 
+// AuthClient calls the Auth service. Methods that read other users are called
+// with serviceToken, the SERVICE_TOKEN the Auth service is configured with.
 type AuthClient struct {
-	client proto.AuthServiceClient
-	log    *zap.Logger
+	client       proto.AuthServiceClient
+	serviceToken string
+	log          *zap.Logger
 }
 
-func NewAuthClient(conn *grpc.ClientConn, log *zap.Logger) *AuthClient {
+func NewAuthClient(conn *grpc.ClientConn, serviceToken string, log *zap.Logger) *AuthClient {
 	return &AuthClient{
-		client: proto.NewAuthServiceClient(conn),
-		log:    log,
+		client:       proto.NewAuthServiceClient(conn),
+		serviceToken: serviceToken,
+		log:          log,
 	}
 }
 
+func (a *AuthClient) asService(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+a.serviceToken)
+}
+
 func (a *AuthClient) CheckToken(ctx context.Context, req *proto.CheckTokenRequest) error {
 	_, err := a.client.CheckToken(ctx, req)
 	return err
 }
 
+// CheckAccess asks whether the token may be used to call the method.
+func (a *AuthClient) CheckAccess(ctx context.Context, token, method string) error {
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+	_, err := a.client.Check(ctx, &proto.CheckAccessRequest{EndpointAddress: method})
+	return err
+}
+
+// KeySource fetches the keys access tokens are signed with.
+func (a *AuthClient) KeySource() verifier.KeySource {
+	return verifier.FromAuthService(a.client)
+}
+
 func (a *AuthClient) GetChatUsersEmails(ctx context.Context, chatID int64) ([]string, error) {
 	req := &proto.GetChatUsersEmailsRequest{ChatId: chatID}
-	res, err := a.client.GetChatUsersEmails(ctx, req)
+	res, err := a.client.GetChatUsersEmails(a.asService(ctx), req)
 	if err != nil {
 		a.log.Error("failed to get emails from auth service", zap.Error(err))
 		return nil, err
@@ -42,7 +64,7 @@ func (a *AuthClient) GetChatUsersEmails(ctx context.Context, chatID int64) ([]st
 
 func (a *AuthClient) GetChatUsers(ctx context.Context, chatID int64) ([]int64, error) {
 	req := &proto.GetChatUsersRequest{ChatId: chatID}
-	res, err := a.client.GetChatUsers(ctx, req)
+	res, err := a.client.GetChatUsers(a.asService(ctx), req)
 	if err != nil {
 		a.log.Error("failed to get chat users from auth service", zap.Error(err))
 		return nil, err
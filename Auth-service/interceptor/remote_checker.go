@@ -0,0 +1,112 @@
+package interceptor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"chat-grpc/pkg/verifier"
+	"chat-grpc/proto_gen"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RemoteChecker verifies tokens with the public keys of the Auth service, the
+// service itself is only asked whether a token has been revoked and what a role
+// may call. Both answers are reused for checkInterval.
+//
+// While the Auth service can not be reached, requests that need a fresh answer
+// are refused. With failOpen they are served instead: a locally valid token is
+// accepted without the revocation check and the last access answer is reused,
+// so a revoked token keeps working until the service is back.
+type RemoteChecker struct {
+	authClient    *AuthClient
+	verifier      *verifier.Verifier
+	checkInterval time.Duration
+	failOpen      bool
+	tokens        *tokenCache
+	log           *zap.Logger
+
+	mu     sync.Mutex
+	access map[string]accessDecision
+}
+
+// accessDecision is the answer of the Auth service for a role and a method.
+type accessDecision struct {
+	allowed   bool
+	checkedAt time.Time
+}
+
+func NewRemoteChecker(authClient *AuthClient, verifier *verifier.Verifier, checkInterval time.Duration, failOpen bool, log *zap.Logger) *RemoteChecker {
+	return &RemoteChecker{
+		authClient:    authClient,
+		verifier:      verifier,
+		checkInterval: checkInterval,
+		failOpen:      failOpen,
+		tokens:        newTokenCache(),
+		log:           log,
+		access:        make(map[string]accessDecision),
+	}
+}
+
+// Authenticate returns the claims of a valid token that has not been revoked.
+func (c *RemoteChecker) Authenticate(ctx context.Context, token string) (*verifier.Claims, error) {
+	if cached, ok := c.tokens.get(token); ok && time.Since(cached.checkedAt) < c.checkInterval {
+		return cached.claims, nil
+	}
+
+	claims, err := c.verifier.Verify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.authClient.CheckToken(ctx, &proto_gen.CheckTokenRequest{Token: token})
+	switch status.Code(err) {
+	case codes.OK:
+	case codes.Unavailable, codes.DeadlineExceeded:
+		if !c.failOpen {
+			c.log.Warn("Auth service is unreachable, refusing the token", zap.Error(err))
+			return nil, status.Error(codes.Unavailable, "cannot check whether the token is revoked")
+		}
+		c.log.Warn("Auth service is unreachable, skipping revocation check", zap.Error(err))
+	default:
+		c.tokens.remove(token)
+		return nil, err
+	}
+
+	c.tokens.put(token, claims, time.Now())
+	return claims, nil
+}
+
+// Authorize asks the Auth service whether the role of the token may call the
+// method.
+func (c *RemoteChecker) Authorize(ctx context.Context, token string, claims *verifier.Claims, method string) error {
+	key := claims.Role + " " + method
+
+	c.mu.Lock()
+	decision, ok := c.access[key]
+	c.mu.Unlock()
+
+	if !ok || time.Since(decision.checkedAt) >= c.checkInterval {
+		err := c.authClient.CheckAccess(ctx, token, method)
+		switch status.Code(err) {
+		case codes.OK, codes.PermissionDenied:
+			decision = accessDecision{allowed: err == nil, checkedAt: time.Now()}
+			c.mu.Lock()
+			c.access[key] = decision
+			c.mu.Unlock()
+		default:
+			if !ok || !c.failOpen {
+				return err
+			}
+			c.log.Warn("Failed to check access, using the last answer", zap.String("method", method), zap.Error(err))
+		}
+	}
+
+	if !decision.allowed {
+		return status.Error(codes.PermissionDenied, "access denied")
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package interceptor
+
+import (
+	"sync"
+	"time"
+
+	"chat-grpc/pkg/verifier"
+)
+
+// tokenCache remembers tokens that passed verification until they expire, and
+// when the Auth service last confirmed they were not revoked.
+type tokenCache struct {
+	mu        sync.Mutex
+	tokens    map[string]*cachedToken
+	lastSweep time.Time
+}
+
+type cachedToken struct {
+	claims    *verifier.Claims
+	checkedAt time.Time
+}
+
+func newTokenCache() *tokenCache {
+	return &tokenCache{tokens: make(map[string]*cachedToken), lastSweep: time.Now()}
+}
+
+func (c *tokenCache) get(token string) (*cachedToken, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.tokens[token]
+	if !ok {
+		return nil, false
+	}
+	if expired(cached.claims) {
+		delete(c.tokens, token)
+		return nil, false
+	}
+
+	return cached, true
+}
+
+func (c *tokenCache) put(token string, claims *verifier.Claims, checkedAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tokens[token] = &cachedToken{claims: claims, checkedAt: checkedAt}
+
+	if time.Since(c.lastSweep) < time.Minute {
+		return
+	}
+	c.lastSweep = time.Now()
+	for token, cached := range c.tokens {
+		if expired(cached.claims) {
+			delete(c.tokens, token)
+		}
+	}
+}
+
+func (c *tokenCache) remove(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.tokens, token)
+}
+
+func expired(claims *verifier.Claims) bool {
+	return claims.ExpiresAt != nil && !time.Now().Before(claims.ExpiresAt.Time)
+}
@@ -0,0 +1,29 @@
+package entity
+
+// Policy maps full gRPC method names to the permission they require and roles
+// to the permissions granted to them. A method that requires no permission is
+// open to every authenticated caller.
+type Policy struct {
+	Methods map[string]string
+	Roles   map[Role]map[string]bool
+}
+
+const (
+	// ManageUsersPermission lets a role act on profiles of other users.
+	ManageUsersPermission = "users.manage"
+	// ReadUsersPermission lets a role read profiles of other users.
+	ReadUsersPermission = "users.read"
+)
+
+func (p *Policy) Allows(role Role, method string) bool {
+	permission, ok := p.Methods[method]
+	if !ok {
+		return true
+	}
+
+	return p.Grants(role, permission)
+}
+
+func (p *Policy) Grants(role Role, permission string) bool {
+	return p.Roles[role][permission]
+}
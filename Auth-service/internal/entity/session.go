@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+// Session is one login of a user on one device. Its refresh token is bound to
+// it, so every device can be signed out on its own.
+type Session struct {
+	ID         int64
+	UserID     int64
+	DeviceName string
+	UserAgent  string
+	IP         string
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+	ExpiresAt  time.Time
+}
@@ -7,6 +7,10 @@ type Role int
 const (
 	UserRole Role = iota
 	AdminRole
+	BotRole
+	ModeratorRole
+	// ServiceRole is held by other services of the chat, never by a user.
+	ServiceRole
 )
 
 type User struct {
@@ -25,6 +29,12 @@ func (r Role) StringRole() string {
 		return "user"
 	case AdminRole:
 		return "admin"
+	case BotRole:
+		return "bot"
+	case ModeratorRole:
+		return "moderator"
+	case ServiceRole:
+		return "service"
 	default:
 		return "unknown role"
 	}
@@ -36,6 +46,12 @@ func ParseRole(roleStr string) Role {
 		return AdminRole
 	case "user":
 		return UserRole
+	case "bot":
+		return BotRole
+	case "moderator":
+		return ModeratorRole
+	case "service":
+		return ServiceRole
 	default:
 		return UserRole
 	}
@@ -22,8 +22,19 @@ func NewAuthHandler(uc *usecase.AuthService, log *zap.Logger) *AuthHandler {
 	return &AuthHandler{usecase: uc, log: log}
 }
 
+// Create registers a user. Self-registration always makes a UserRole user, only
+// a caller who manages users may pick another role.
 func (h *AuthHandler) Create(ctx context.Context, req *proto_gen.CreateUserRequest) (*proto_gen.CreateUserResponse, error) {
-	id, err := h.usecase.CreateUser(req.Name, req.Email, req.Password, entity.Role(req.Role))
+	role := entity.Role(req.Role)
+	// bots sign in with an api key, never with a password
+	if role == entity.BotRole {
+		return nil, status.Error(codes.InvalidArgument, "bot accounts are created with CreateBot")
+	}
+	if role != entity.UserRole && !h.managesUsers(ctx) {
+		return nil, status.Error(codes.PermissionDenied, "only user managers can create accounts with other roles")
+	}
+
+	id, err := h.usecase.CreateUser(req.Name, req.Email, req.Password, role)
 	if err != nil {
 		return nil, err
 	}
@@ -32,7 +43,7 @@ func (h *AuthHandler) Create(ctx context.Context, req *proto_gen.CreateUserReque
 }
 
 func (h *AuthHandler) Login(ctx context.Context, req *proto_gen.LoginRequest) (*proto_gen.LoginResponse, error) {
-	refreshToken, err := h.usecase.Login(req.Email, req.Password)
+	refreshToken, err := h.usecase.Login(req.Email, req.Password, deviceInfo(ctx, req.DeviceName))
 	if err != nil {
 		return nil, err
 	}
@@ -58,7 +69,17 @@ func (h *AuthHandler) GetAccessToken(ctx context.Context, req *proto_gen.AccessT
 	return &proto_gen.AccessTokenResponse{AccessToken: accessToken}, nil
 }
 
+// Get returns the profile of the caller, other profiles only a caller who may
+// read users gets.
 func (h *AuthHandler) Get(ctx context.Context, req *proto_gen.GetUserRequest) (*proto_gen.GetUserResponse, error) {
+	claims, err := h.caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.Id != claims.UserID && !h.usecase.HasPermission(claims, entity.ReadUsersPermission) {
+		return nil, status.Error(codes.PermissionDenied, "only your own profile can be read")
+	}
+
 	user, err := h.usecase.GetUser(req.Id)
 	if err != nil {
 		return nil, err
@@ -94,8 +115,18 @@ func (h *AuthHandler) GetList(ctx context.Context, req *proto_gen.AuthEmpty) (*p
 	return &proto_gen.GetListResponse{Users: usersProto}, nil
 }
 
+// Update changes the profile of the caller, other profiles only a caller who
+// manages users may change.
 func (h *AuthHandler) Update(ctx context.Context, req *proto_gen.UpdateUserRequest) (*proto_gen.AuthEmpty, error) {
-	err := h.usecase.UpdateUser(req.Id, req.Name, req.Email)
+	claims, err := h.caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.Id != claims.UserID && !h.usecase.HasPermission(claims, entity.ManageUsersPermission) {
+		return nil, status.Error(codes.PermissionDenied, "only your own profile can be updated")
+	}
+
+	err = h.usecase.UpdateUser(req.Id, req.Name, req.Email)
 	if err != nil {
 		return nil, err
 	}
@@ -112,12 +143,18 @@ func (h *AuthHandler) Delete(ctx context.Context, req *proto_gen.DeleteUserReque
 	return &proto_gen.AuthEmpty{}, nil
 }
 
+// Check answers whether the token of the request may call the method named by
+// endpoint_address, e.g. "/auth.AuthService/Delete".
 func (h *AuthHandler) Check(ctx context.Context, req *proto_gen.CheckAccessRequest) (*proto_gen.AuthEmpty, error) {
-	err := h.usecase.CheckToken(req.EndpointAddress)
+	claims, err := h.caller(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	if !h.usecase.CheckAccess(claims, req.EndpointAddress) {
+		return nil, status.Error(codes.PermissionDenied, "access denied")
+	}
+
 	return &proto_gen.AuthEmpty{}, nil
 }
 
@@ -139,3 +176,30 @@ func (h *AuthHandler) GetChatUsersEmails(ctx context.Context, req *proto_gen.Get
 
 	return &proto_gen.GetChatUsersEmailsResponse{Emails: emails}, nil
 }
+
+func (h *AuthHandler) CreateBot(ctx context.Context, req *proto_gen.CreateBotRequest) (*proto_gen.BotKeyResponse, error) {
+	id, apiKey, err := h.usecase.CreateBot(req.Name, req.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto_gen.BotKeyResponse{Id: id, ApiKey: apiKey}, nil
+}
+
+func (h *AuthHandler) RotateBotKey(ctx context.Context, req *proto_gen.RotateBotKeyRequest) (*proto_gen.BotKeyResponse, error) {
+	apiKey, err := h.usecase.RotateBotKey(req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto_gen.BotKeyResponse{Id: req.Id, ApiKey: apiKey}, nil
+}
+
+func (h *AuthHandler) AuthenticateBot(ctx context.Context, req *proto_gen.AuthenticateBotRequest) (*proto_gen.AccessTokenResponse, error) {
+	accessToken, err := h.usecase.AuthenticateBot(req.ApiKey)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid api key")
+	}
+
+	return &proto_gen.AccessTokenResponse{AccessToken: accessToken}, nil
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"chat-grpc/Auth-service/internal/entity"
+	"chat-grpc/Auth-service/internal/usecase"
+	"chat-grpc/pkg/verifier"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// LocalChecker answers the questions of the auth interceptor of this service in
+// process, other services ask over gRPC.
+//
+// Other services call this one with serviceToken instead of an access token and
+// get the service role. An empty serviceToken is never accepted.
+type LocalChecker struct {
+	usecase      *usecase.AuthService
+	serviceToken string
+}
+
+func NewLocalChecker(uc *usecase.AuthService, serviceToken string) *LocalChecker {
+	return &LocalChecker{usecase: uc, serviceToken: serviceToken}
+}
+
+func (c *LocalChecker) Authenticate(ctx context.Context, token string) (*verifier.Claims, error) {
+	if c.serviceToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(c.serviceToken)) == 1 {
+		return &verifier.Claims{Role: entity.ServiceRole.StringRole(), Type: verifier.AccessTokenType}, nil
+	}
+
+	return c.usecase.VerifyAccessToken(token)
+}
+
+func (c *LocalChecker) Authorize(ctx context.Context, token string, claims *verifier.Claims, method string) error {
+	if !c.usecase.CheckAccess(claims, method) {
+		return status.Error(codes.PermissionDenied, "access denied")
+	}
+
+	return nil
+}
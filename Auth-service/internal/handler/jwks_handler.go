@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"chat-grpc/pkg/verifier"
+	"chat-grpc/proto_gen"
+	"go.uber.org/zap"
+)
+
+func (h *AuthHandler) GetJWKS(ctx context.Context, req *proto_gen.AuthEmpty) (*proto_gen.JWKSResponse, error) {
+	resp := &proto_gen.JWKSResponse{}
+	for _, key := range h.usecase.JWKS() {
+		resp.Keys = append(resp.Keys, verifier.JWKToProto(key))
+	}
+
+	return resp, nil
+}
+
+// ServeJWKS serves the public keys at /.well-known/jwks.json.
+func (h *AuthHandler) ServeJWKS(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if err := json.NewEncoder(w).Encode(verifier.JWKS{Keys: h.usecase.JWKS()}); err != nil {
+		h.log.Error("failed to write jwks", zap.Error(err))
+	}
+}
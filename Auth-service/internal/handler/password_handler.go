@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"chat-grpc/Auth-service/internal"
+	"chat-grpc/Auth-service/internal/repository"
+	"chat-grpc/Auth-service/internal/usecase"
+	"chat-grpc/proto_gen"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (h *AuthHandler) ChangePassword(ctx context.Context, req *proto_gen.ChangePasswordRequest) (*proto_gen.AuthEmpty, error) {
+	claims, err := h.caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := internal.ValidatePassword(req.NewPassword); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	err = h.usecase.ChangePassword(claims, req.OldPassword, req.NewPassword)
+	if errors.Is(err, usecase.ErrWrongPassword) {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	if err != nil {
+		h.log.Error("failed to change password", zap.Int64("userID", claims.UserID), zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to change password")
+	}
+
+	return &proto_gen.AuthEmpty{}, nil
+}
+
+func (h *AuthHandler) RequestPasswordReset(ctx context.Context, req *proto_gen.RequestPasswordResetRequest) (*proto_gen.AuthEmpty, error) {
+	if req.Email == "" {
+		return nil, status.Error(codes.InvalidArgument, "email is required")
+	}
+
+	if err := h.usecase.RequestPasswordReset(ctx, req.Email); err != nil {
+		h.log.Error("failed to request password reset", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to request password reset")
+	}
+
+	return &proto_gen.AuthEmpty{}, nil
+}
+
+func (h *AuthHandler) ResetPassword(ctx context.Context, req *proto_gen.ResetPasswordRequest) (*proto_gen.AuthEmpty, error) {
+	if err := internal.ValidatePassword(req.NewPassword); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	err := h.usecase.ResetPassword(req.Token, req.NewPassword)
+	if errors.Is(err, repository.ErrInvalidResetToken) {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	if err != nil {
+		h.log.Error("failed to reset password", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to reset password")
+	}
+
+	return &proto_gen.AuthEmpty{}, nil
+}
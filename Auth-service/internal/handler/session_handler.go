@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"chat-grpc/Auth-service/interceptor"
+	"chat-grpc/Auth-service/internal/entity"
+	"chat-grpc/Auth-service/internal/repository"
+	"chat-grpc/Auth-service/internal/usecase"
+	"chat-grpc/Auth-service/jwt"
+	"chat-grpc/proto_gen"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// deviceInfo describes the client that logs in, as far as the connection tells.
+func deviceInfo(ctx context.Context, deviceName string) entity.Session {
+	device := entity.Session{DeviceName: deviceName}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ua := md.Get("user-agent"); len(ua) > 0 {
+			device.UserAgent = ua[0]
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		device.IP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(device.IP); err == nil {
+			device.IP = host
+		}
+	}
+
+	return device
+}
+
+// caller returns the claims of the access token of the request, RPCs that act
+// on behalf of a user take the user from it. The interceptor has checked the
+// token already unless the method is public.
+func (h *AuthHandler) caller(ctx context.Context) (*jwt.Claims, error) {
+	if claims, ok := interceptor.ClaimsFromContext(ctx); ok {
+		return claims, nil
+	}
+
+	token, err := interceptor.BearerToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := h.usecase.VerifyAccessToken(token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	return claims, nil
+}
+
+// managesUsers tells whether the request carries a token of a user who may
+// manage other users.
+func (h *AuthHandler) managesUsers(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md.Get("authorization")) == 0 {
+		return false
+	}
+
+	claims, err := h.caller(ctx)
+	return err == nil && h.usecase.HasPermission(claims, entity.ManageUsersPermission)
+}
+
+func (h *AuthHandler) ListSessions(ctx context.Context, req *proto_gen.AuthEmpty) (*proto_gen.ListSessionsResponse, error) {
+	claims, err := h.caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := h.usecase.ListSessions(claims.UserID, claims.SessionID)
+	if errors.Is(err, usecase.ErrNoSession) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		h.log.Error("failed to list sessions", zap.Int64("userID", claims.UserID), zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to list sessions")
+	}
+
+	resp := &proto_gen.ListSessionsResponse{}
+	for _, s := range sessions {
+		resp.Sessions = append(resp.Sessions, &proto_gen.Session{
+			Id:         s.ID,
+			DeviceName: s.DeviceName,
+			UserAgent:  s.UserAgent,
+			Ip:         s.IP,
+			CreatedAt:  timestamppb.New(s.CreatedAt),
+			LastUsedAt: timestamppb.New(s.LastUsedAt),
+			ExpiresAt:  timestamppb.New(s.ExpiresAt),
+			Current:    s.ID == claims.SessionID,
+		})
+	}
+
+	return resp, nil
+}
+
+func (h *AuthHandler) RevokeSession(ctx context.Context, req *proto_gen.RevokeSessionRequest) (*proto_gen.AuthEmpty, error) {
+	claims, err := h.caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = h.usecase.RevokeSession(claims.UserID, req.SessionId)
+	if errors.Is(err, repository.ErrSessionNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		h.log.Error("failed to revoke session", zap.Int64("sessionID", req.SessionId), zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to revoke session")
+	}
+
+	return &proto_gen.AuthEmpty{}, nil
+}
+
+func (h *AuthHandler) RevokeAllOtherSessions(ctx context.Context, req *proto_gen.AuthEmpty) (*proto_gen.AuthEmpty, error) {
+	claims, err := h.caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = h.usecase.RevokeAllOtherSessions(claims.UserID, claims.SessionID)
+	if errors.Is(err, usecase.ErrNoSession) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		h.log.Error("failed to revoke other sessions", zap.Int64("userID", claims.UserID), zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to revoke sessions")
+	}
+
+	return &proto_gen.AuthEmpty{}, nil
+}
+
+func (h *AuthHandler) Logout(ctx context.Context, req *proto_gen.AuthEmpty) (*proto_gen.AuthEmpty, error) {
+	claims, err := h.caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.usecase.Logout(claims); err != nil {
+		h.log.Error("failed to log out", zap.Int64("userID", claims.UserID), zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to log out")
+	}
+
+	return &proto_gen.AuthEmpty{}, nil
+}
@@ -8,18 +8,37 @@ import (
 	"fmt"
 
 	"chat-grpc/Auth-service/internal/entity"
+	"github.com/lib/pq"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 )
 
-type AuthRepo struct {
+type AuthRepo interface {
+	CreateUser(name, email, password string, role entity.Role) (int64, error)
+	Login(username, pass string) (string, error)
+	GetUser(id int64) (*entity.User, error)
+	GetList() ([]*entity.User, error)
+	UpdateUser(id int64, name, email string) error
+	DeleteUser(id int64) error
+	GetUserByUsername(username string) (*entity.User, error)
+	GetUserByUsernameAndValidatePassword(email, pass string) (*entity.User, error)
+	GetEmailByUserID(userID int64) (string, error)
+	GetChatUsersEmails(chatID int64) ([]string, error)
+	BotRepo
+	PasswordRepo
+	RBACRepo
+	RevocationRepo
+	SessionRepo
+}
+
+type authRepository struct {
 	dbUser *sql.DB
 	dbAuth *sql.DB
 	log    *zap.Logger
 }
 
-func NewAuthRepository(dbUser *sql.DB, dbAuth *sql.DB, log *zap.Logger) *AuthRepo {
-	return &AuthRepo{dbUser: dbUser, dbAuth: dbAuth, log: log}
+func NewAuthRepository(dbUser *sql.DB, dbAuth *sql.DB, log *zap.Logger) AuthRepo {
+	return &authRepository{dbUser: dbUser, dbAuth: dbAuth, log: log}
 }
 
 func hashToken(token string) string {
@@ -27,25 +46,7 @@ func hashToken(token string) string {
 	return hex.EncodeToString(hash[:])
 }
 
-func (a *AuthRepo) SaveRefreshToken(userID int64, token string) error {
-	hashedToken := hashToken(token)
-
-	_, err := a.dbUser.Exec(`
-		INSERT INTO refresh_tokens (user_id, token) 
-		VALUES ($1, $2) 
-		ON CONFLICT (user_id) DO UPDATE SET token = $2`,
-		userID, hashedToken,
-	)
-
-	if err != nil {
-		a.log.Error("Failed to save refresh token", zap.Error(err))
-	}
-
-	a.log.Info("Success save refresh token")
-	return err
-}
-
-func (a *AuthRepo) CreateUser(name, email, password string, role entity.Role) (int64, error) {
+func (a *authRepository) CreateUser(name, email, password string, role entity.Role) (int64, error) {
 	a.log.Info("Creating user", zap.String("name", name), zap.String("email", email), zap.String("role", role.StringRole()))
 
 	var id int64
@@ -61,7 +62,7 @@ func (a *AuthRepo) CreateUser(name, email, password string, role entity.Role) (i
 	return id, nil
 }
 
-func (a *AuthRepo) Login(username, pass string) (string, error) {
+func (a *authRepository) Login(username, pass string) (string, error) {
 	a.log.Info("Login attempt", zap.String("username", username))
 
 	var hashPassword string
@@ -81,7 +82,7 @@ func (a *AuthRepo) Login(username, pass string) (string, error) {
 	return "refresh_token", nil
 }
 
-func (a *AuthRepo) GetUser(id int64) (*entity.User, error) {
+func (a *authRepository) GetUser(id int64) (*entity.User, error) {
 	var user entity.User
 	var roleStr string
 
@@ -98,7 +99,7 @@ func (a *AuthRepo) GetUser(id int64) (*entity.User, error) {
 	return &user, nil
 }
 
-func (a *AuthRepo) GetList() ([]*entity.User, error) {
+func (a *authRepository) GetList() ([]*entity.User, error) {
 	query := `SELECT id, name, email, role, created_at, updated_at FROM users`
 	rows, err := a.dbUser.Query(query)
 	if err != nil {
@@ -126,7 +127,7 @@ func (a *AuthRepo) GetList() ([]*entity.User, error) {
 	return users, nil
 }
 
-func (a *AuthRepo) UpdateUser(id int64, name, email string) error {
+func (a *authRepository) UpdateUser(id int64, name, email string) error {
 	a.log.Info("Update user", zap.Int64("id", id))
 
 	query := `UPDATE users SET name = $1, email = $2, updated_at = NOW() WHERE id = $3`
@@ -140,7 +141,7 @@ func (a *AuthRepo) UpdateUser(id int64, name, email string) error {
 	return nil
 }
 
-func (a *AuthRepo) DeleteUser(id int64) error {
+func (a *authRepository) DeleteUser(id int64) error {
 	a.log.Info("Delete user", zap.Int64("id", id))
 
 	query := `DELETE FROM users WHERE id = $1`
@@ -154,7 +155,7 @@ func (a *AuthRepo) DeleteUser(id int64) error {
 	return nil
 }
 
-func (a *AuthRepo) GetUserByUsername(username string) (*entity.User, error) {
+func (a *authRepository) GetUserByUsername(username string) (*entity.User, error) {
 	var user entity.User
 	var roleStr string
 
@@ -171,7 +172,7 @@ func (a *AuthRepo) GetUserByUsername(username string) (*entity.User, error) {
 	return &user, nil
 }
 
-func (a *AuthRepo) GetUserByUsernameAndValidatePassword(email, pass string) (*entity.User, error) {
+func (a *authRepository) GetUserByUsernameAndValidatePassword(email, pass string) (*entity.User, error) {
 	a.log.Info("Login attempt", zap.String("email", email))
 
 	var user entity.User
@@ -194,36 +195,7 @@ func (a *AuthRepo) GetUserByUsernameAndValidatePassword(email, pass string) (*en
 	return &user, nil
 }
 
-func (a *AuthRepo) CheckRefreshToken(userID int64, token string) error {
-	hashedToken := hashToken(token)
-
-	var dbToken string
-	err := a.dbUser.QueryRow(`SELECT token FROM refresh_tokens WHERE user_id = $1`, userID).Scan(&dbToken)
-	if err != nil {
-		a.log.Warn("Refresh token not found", zap.Error(err))
-		return errors.New("invalid refresh token")
-	}
-
-	if hashedToken != dbToken {
-		a.log.Warn("Refresh token mismatch")
-		return errors.New("invalid refresh token")
-	}
-
-	return nil
-}
-
-func (a *AuthRepo) DeleteRefreshToken(userID int64) error {
-	_, err := a.dbUser.Exec(`DELETE FROM refresh_tokens WHERE user_id = $1`, userID)
-	if err != nil {
-		a.log.Error("Failed to delete refresh token", zap.Error(err))
-		return err
-	}
-
-	a.log.Info("Refresh token deleted", zap.Int64("userID", userID))
-	return nil
-}
-
-func (a *AuthRepo) GetEmailByUserID(userID int64) (string, error) {
+func (a *authRepository) GetEmailByUserID(userID int64) (string, error) {
 	var email string
 	query := `SELECT email FROM users WHERE id = $1`
 	err := a.dbUser.QueryRow(query, userID).Scan(&email)
@@ -233,7 +205,7 @@ func (a *AuthRepo) GetEmailByUserID(userID int64) (string, error) {
 	return email, nil
 }
 
-func (a *AuthRepo) GetChatUsersEmails(chatID int64) ([]string, error) {
+func (a *authRepository) GetChatUsersEmails(chatID int64) ([]string, error) {
 	var usersId []int64
 	var emails []string
 	query := `SELECT user_id FROM chat_users WHERE chat_id = $1`
@@ -253,10 +225,18 @@ func (a *AuthRepo) GetChatUsersEmails(chatID int64) ([]string, error) {
 
 	a.log.Info("users len", zap.Int("len", len(usersId)))
 
-	for _, userId := range usersId {
-		email, err := a.GetEmailByUserID(userId)
-		if err != nil {
-			return nil, err
+	// bots have no mailbox, skip them
+	rowsEmails, err := a.dbUser.Query(`SELECT email FROM users WHERE id = ANY($1) AND role <> $2`,
+		pq.Array(usersId), entity.BotRole.StringRole())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get emails: %w", err)
+	}
+	defer rowsEmails.Close()
+
+	for rowsEmails.Next() {
+		var email string
+		if err := rowsEmails.Scan(&email); err != nil {
+			return nil, fmt.Errorf("failed to scan email: %w", err)
 		}
 		emails = append(emails, email)
 	}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"errors"
+
+	"chat-grpc/Auth-service/internal/entity"
+	"go.uber.org/zap"
+)
+
+type BotRepo interface {
+	CreateBot(name, email, apiKey string) (int64, error)
+	RotateBotKey(botID int64, apiKey string) error
+	GetBotByAPIKey(apiKey string) (*entity.User, error)
+}
+
+// CreateBot creates a bot user together with its first API key. Bots have no
+// password, so they can never log in interactively.
+func (a *authRepository) CreateBot(name, email, apiKey string) (int64, error) {
+	a.log.Info("Creating bot", zap.String("name", name))
+
+	tx, err := a.dbUser.Begin()
+	if err != nil {
+		a.log.Error("Failed to begin transaction", zap.Error(err))
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var id int64
+	query := `INSERT INTO users (name, email, password_hash, role) VALUES ($1, $2, '', $3) RETURNING id`
+	err = tx.QueryRow(query, name, email, entity.BotRole.StringRole()).Scan(&id)
+	if err != nil {
+		a.log.Error("Failed to insert bot user", zap.Error(err))
+		return 0, err
+	}
+
+	_, err = tx.Exec(`INSERT INTO api_keys (user_id, key_hash) VALUES ($1, $2)`, id, hashToken(apiKey))
+	if err != nil {
+		a.log.Error("Failed to save bot api key", zap.Error(err))
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		a.log.Error("Failed to commit bot", zap.Error(err))
+		return 0, err
+	}
+
+	a.log.Info("Successful create bot", zap.Int64("userid:", id))
+	return id, nil
+}
+
+// RotateBotKey revokes every active key of the bot and stores the new one.
+func (a *authRepository) RotateBotKey(botID int64, apiKey string) error {
+	tx, err := a.dbUser.Begin()
+	if err != nil {
+		a.log.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	var roleStr string
+	err = tx.QueryRow(`SELECT role FROM users WHERE id = $1`, botID).Scan(&roleStr)
+	if err != nil {
+		a.log.Error("Bot not found", zap.Int64("id", botID), zap.Error(err))
+		return err
+	}
+	if entity.ParseRole(roleStr) != entity.BotRole {
+		return errors.New("user is not a bot")
+	}
+
+	_, err = tx.Exec(`UPDATE api_keys SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`, botID)
+	if err != nil {
+		a.log.Error("Failed to revoke bot api keys", zap.Error(err))
+		return err
+	}
+
+	_, err = tx.Exec(`INSERT INTO api_keys (user_id, key_hash) VALUES ($1, $2)`, botID, hashToken(apiKey))
+	if err != nil {
+		a.log.Error("Failed to save bot api key", zap.Error(err))
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		a.log.Error("Failed to commit bot key rotation", zap.Error(err))
+		return err
+	}
+
+	a.log.Info("Bot api key rotated", zap.Int64("id", botID))
+	return nil
+}
+
+func (a *authRepository) GetBotByAPIKey(apiKey string) (*entity.User, error) {
+	var user entity.User
+	var roleStr string
+
+	query := `SELECT u.id, u.name, u.email, u.role
+			  FROM api_keys k
+			  JOIN users u ON u.id = k.user_id
+			  WHERE k.key_hash = $1 AND k.revoked_at IS NULL`
+	err := a.dbUser.QueryRow(query, hashToken(apiKey)).Scan(&user.ID, &user.Name, &user.Email, &roleStr)
+	if err != nil {
+		a.log.Warn("Bot api key not found", zap.Error(err))
+		return nil, errors.New("invalid api key")
+	}
+
+	user.Role = entity.ParseRole(roleStr)
+	if user.Role != entity.BotRole {
+		return nil, errors.New("invalid api key")
+	}
+
+	return &user, nil
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"chat-grpc/Auth-service/internal/entity"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+	ErrTooManyResets     = errors.New("too many password reset requests")
+)
+
+type PasswordRepo interface {
+	GetUserByEmail(email string) (*entity.User, error)
+	UpdatePassword(userID int64, passwordHash string) error
+	CreatePasswordReset(userID int64, token string, expiresAt time.Time, maxPending, maxRecent int, window time.Duration) error
+	ResetPassword(token, passwordHash string) (int64, error)
+}
+
+func (a *authRepository) GetUserByEmail(email string) (*entity.User, error) {
+	var user entity.User
+	var roleStr string
+
+	query := `SELECT id, name, email, role FROM users WHERE email = $1`
+	err := a.dbUser.QueryRow(query, email).Scan(&user.ID, &user.Name, &user.Email, &roleStr)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Role = entity.ParseRole(roleStr)
+	return &user, nil
+}
+
+func (a *authRepository) UpdatePassword(userID int64, passwordHash string) error {
+	_, err := a.dbUser.Exec(
+		`UPDATE users SET password_hash = $1, updated_at = NOW() WHERE id = $2`,
+		passwordHash, userID,
+	)
+	if err != nil {
+		a.log.Error("Failed to update password", zap.Int64("userID", userID), zap.Error(err))
+		return err
+	}
+
+	a.log.Info("Password updated", zap.Int64("userID", userID))
+	return nil
+}
+
+// CreatePasswordReset stores the hash of a reset token, the token itself is
+// only sent to the user. It returns ErrTooManyResets when the user already has
+// maxPending unused tokens or was given maxRecent tokens within window.
+func (a *authRepository) CreatePasswordReset(userID int64, token string, expiresAt time.Time, maxPending, maxRecent int, window time.Duration) error {
+	tx, err := a.dbUser.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// concurrent requests of the same user are counted one after another
+	if _, err := tx.Exec(`SELECT id FROM users WHERE id = $1 FOR UPDATE`, userID); err != nil {
+		a.log.Error("Failed to lock user for password reset", zap.Int64("userID", userID), zap.Error(err))
+		return err
+	}
+
+	var pending, recent int
+	err = tx.QueryRow(`
+		SELECT COUNT(*) FILTER (WHERE used_at IS NULL AND expires_at > NOW()),
+		       COUNT(*) FILTER (WHERE created_at > NOW() - make_interval(secs => $2))
+		FROM password_resets WHERE user_id = $1`,
+		userID, window.Seconds(),
+	).Scan(&pending, &recent)
+	if err != nil {
+		a.log.Error("Failed to count password resets", zap.Int64("userID", userID), zap.Error(err))
+		return err
+	}
+	if pending >= maxPending || recent >= maxRecent {
+		return ErrTooManyResets
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO password_resets (user_id, token_hash, expires_at) VALUES ($1, $2, $3)`,
+		userID, hashToken(token), expiresAt.UTC(),
+	)
+	if err != nil {
+		a.log.Error("Failed to create password reset", zap.Int64("userID", userID), zap.Error(err))
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ResetPassword uses up the reset token and sets the new password in one
+// transaction. The other pending reset tokens of the user stop working too.
+func (a *authRepository) ResetPassword(token, passwordHash string) (int64, error) {
+	tx, err := a.dbUser.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var userID int64
+	err = tx.QueryRow(`
+		UPDATE password_resets SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id`,
+		hashToken(token),
+	).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrInvalidResetToken
+	}
+	if err != nil {
+		a.log.Error("Failed to use password reset", zap.Error(err))
+		return 0, err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE password_resets SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL`,
+		userID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE users SET password_hash = $1, updated_at = NOW() WHERE id = $2`,
+		passwordHash, userID,
+	)
+	if err != nil {
+		a.log.Error("Failed to reset password", zap.Int64("userID", userID), zap.Error(err))
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	a.log.Info("Password reset", zap.Int64("userID", userID))
+	return userID, nil
+}
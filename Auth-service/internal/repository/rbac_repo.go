@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"chat-grpc/Auth-service/internal/entity"
+	"go.uber.org/zap"
+)
+
+type RBACRepo interface {
+	GetPolicy() (*entity.Policy, error)
+}
+
+// GetPolicy loads the permissions of the methods and the grants of the roles.
+func (a *authRepository) GetPolicy() (*entity.Policy, error) {
+	policy := &entity.Policy{
+		Methods: make(map[string]string),
+		Roles:   make(map[entity.Role]map[string]bool),
+	}
+
+	rows, err := a.dbUser.Query(`SELECT method, permission FROM method_permissions`)
+	if err != nil {
+		a.log.Error("Failed to load method permissions", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var method, permission string
+		if err := rows.Scan(&method, &permission); err != nil {
+			return nil, err
+		}
+		policy.Methods[method] = permission
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	grantRows, err := a.dbUser.Query(`SELECT role, permission FROM role_permissions`)
+	if err != nil {
+		a.log.Error("Failed to load role permissions", zap.Error(err))
+		return nil, err
+	}
+	defer grantRows.Close()
+
+	for grantRows.Next() {
+		var roleStr, permission string
+		if err := grantRows.Scan(&roleStr, &permission); err != nil {
+			return nil, err
+		}
+
+		role := entity.ParseRole(roleStr)
+		if role.StringRole() != roleStr {
+			a.log.Warn("Permission granted to unknown role", zap.String("role", roleStr), zap.String("permission", permission))
+			continue
+		}
+		if policy.Roles[role] == nil {
+			policy.Roles[role] = make(map[string]bool)
+		}
+		policy.Roles[role][permission] = true
+	}
+
+	return policy, grantRows.Err()
+}
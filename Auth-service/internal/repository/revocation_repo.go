@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Revocations are the live entries of the revocation list. Tokens maps a jti to
+// the expiry of its token, Users maps a user to the moment before which all of
+// the tokens of the user were revoked, Sessions maps a revoked session to the
+// time of the revocation.
+type Revocations struct {
+	Tokens   map[string]time.Time
+	Users    map[int64]time.Time
+	Sessions map[int64]time.Time
+}
+
+type RevocationRepo interface {
+	RevokeToken(jti string, userID int64, expiresAt time.Time) error
+	RevokeUserTokens(userID int64, before, expiresAt time.Time) error
+	GetRevocations(sessionsWithin time.Duration) (*Revocations, error)
+}
+
+// RevokeToken puts a single token on the list until it expires.
+func (a *authRepository) RevokeToken(jti string, userID int64, expiresAt time.Time) error {
+	_, err := a.dbUser.Exec(`
+		INSERT INTO revoked_tokens (jti, user_id, expires_at) VALUES ($1, $2, $3)
+		ON CONFLICT (jti) DO NOTHING`,
+		jti, userID, expiresAt.UTC(),
+	)
+	if err != nil {
+		a.log.Error("Failed to revoke token", zap.Int64("userID", userID), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// RevokeUserTokens revokes every token of the user issued before the given
+// moment. The entry is kept until the last of those tokens expires.
+func (a *authRepository) RevokeUserTokens(userID int64, before, expiresAt time.Time) error {
+	_, err := a.dbUser.Exec(`
+		INSERT INTO revoked_users (user_id, revoked_before, expires_at) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET revoked_before = $2, expires_at = $3`,
+		userID, before.UTC(), expiresAt.UTC(),
+	)
+	if err != nil {
+		a.log.Error("Failed to revoke user tokens", zap.Int64("userID", userID), zap.Error(err))
+		return err
+	}
+
+	a.log.Info("User tokens revoked", zap.Int64("userID", userID))
+	return nil
+}
+
+// GetRevocations loads the entries of tokens that have not expired yet and
+// deletes the rest. Sessions revoked longer than sessionsWithin ago have no
+// live access tokens left and are skipped.
+func (a *authRepository) GetRevocations(sessionsWithin time.Duration) (*Revocations, error) {
+	if _, err := a.dbUser.Exec(`DELETE FROM revoked_tokens WHERE expires_at <= NOW()`); err != nil {
+		a.log.Error("Failed to prune revoked tokens", zap.Error(err))
+		return nil, err
+	}
+	if _, err := a.dbUser.Exec(`DELETE FROM revoked_users WHERE expires_at <= NOW()`); err != nil {
+		a.log.Error("Failed to prune revoked users", zap.Error(err))
+		return nil, err
+	}
+
+	revocations := &Revocations{
+		Tokens: make(map[string]time.Time),
+		Users:  make(map[int64]time.Time),
+	}
+
+	rows, err := a.dbUser.Query(`SELECT jti, expires_at FROM revoked_tokens`)
+	if err != nil {
+		a.log.Error("Failed to load revoked tokens", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var jti string
+		var expiresAt time.Time
+		if err := rows.Scan(&jti, &expiresAt); err != nil {
+			return nil, err
+		}
+		revocations.Tokens[jti] = expiresAt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	userRows, err := a.dbUser.Query(`SELECT user_id, revoked_before FROM revoked_users`)
+	if err != nil {
+		a.log.Error("Failed to load revoked users", zap.Error(err))
+		return nil, err
+	}
+	defer userRows.Close()
+
+	for userRows.Next() {
+		var userID int64
+		var before time.Time
+		if err := userRows.Scan(&userID, &before); err != nil {
+			return nil, err
+		}
+		revocations.Users[userID] = before
+	}
+
+	if err := userRows.Err(); err != nil {
+		return nil, err
+	}
+
+	revocations.Sessions, err = a.revokedSessions(sessionsWithin)
+	if err != nil {
+		return nil, err
+	}
+
+	return revocations, nil
+}
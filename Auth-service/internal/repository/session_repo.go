@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"chat-grpc/Auth-service/internal/entity"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrSessionNotFound     = errors.New("session not found")
+	// ErrRefreshTokenReused means a refresh token that was already rotated out
+	// came back, so it has been copied and the session must not be trusted.
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+)
+
+type SessionRepo interface {
+	CreateSession(session *entity.Session) (int64, error)
+	SetSessionToken(sessionID int64, token string, expiresAt time.Time) error
+	UseSession(sessionID, userID int64, token string) error
+	RotateSessionToken(sessionID, userID int64, oldToken, newToken string, expiresAt time.Time) error
+	ListSessions(userID int64) ([]*entity.Session, error)
+	RevokeSession(userID, sessionID int64) error
+	RevokeOtherSessions(userID, keepID int64) ([]int64, error)
+}
+
+// CreateSession opens a session without a refresh token, the token carries the
+// session id and is stored with SetSessionToken once it is signed.
+func (a *authRepository) CreateSession(session *entity.Session) (int64, error) {
+	var id int64
+	query := `INSERT INTO sessions (user_id, device_name, user_agent, ip, expires_at)
+			  VALUES ($1, $2, $3, $4, $5) RETURNING id`
+	err := a.dbUser.QueryRow(query, session.UserID, session.DeviceName, session.UserAgent, session.IP, session.ExpiresAt).Scan(&id)
+	if err != nil {
+		a.log.Error("Failed to create session", zap.Int64("userID", session.UserID), zap.Error(err))
+		return 0, err
+	}
+
+	a.log.Info("Session created", zap.Int64("userID", session.UserID), zap.Int64("sessionID", id))
+	return id, nil
+}
+
+// SetSessionToken binds a new refresh token to the session, the previous one
+// stops working.
+func (a *authRepository) SetSessionToken(sessionID int64, token string, expiresAt time.Time) error {
+	_, err := a.dbUser.Exec(`
+		UPDATE sessions SET refresh_token_hash = $1, expires_at = $2, last_used_at = NOW()
+		WHERE id = $3`,
+		hashToken(token), expiresAt, sessionID,
+	)
+	if err != nil {
+		a.log.Error("Failed to save refresh token", zap.Int64("sessionID", sessionID), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// UseSession checks that the refresh token is the current token of a live
+// session of the user and marks the session as used.
+func (a *authRepository) UseSession(sessionID, userID int64, token string) error {
+	res, err := a.dbUser.Exec(`
+		UPDATE sessions SET last_used_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND refresh_token_hash = $3
+		  AND revoked_at IS NULL AND expires_at > NOW()`,
+		sessionID, userID, hashToken(token),
+	)
+	if err != nil {
+		a.log.Error("Failed to check session", zap.Int64("sessionID", sessionID), zap.Error(err))
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return a.sessionTokenError(sessionID, userID)
+	}
+
+	return nil
+}
+
+// RotateSessionToken replaces the refresh token of the session with a new one
+// if oldToken is still the current token. Two rotations of the same token can
+// not both succeed.
+func (a *authRepository) RotateSessionToken(sessionID, userID int64, oldToken, newToken string, expiresAt time.Time) error {
+	res, err := a.dbUser.Exec(`
+		UPDATE sessions SET refresh_token_hash = $1, expires_at = $2, last_used_at = NOW()
+		WHERE id = $3 AND user_id = $4 AND refresh_token_hash = $5
+		  AND revoked_at IS NULL AND expires_at > NOW()`,
+		hashToken(newToken), expiresAt, sessionID, userID, hashToken(oldToken),
+	)
+	if err != nil {
+		a.log.Error("Failed to rotate refresh token", zap.Int64("sessionID", sessionID), zap.Error(err))
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return a.sessionTokenError(sessionID, userID)
+	}
+
+	return nil
+}
+
+// sessionTokenError explains why a signed refresh token of the session was not
+// accepted. The token names a live session but is not its current token only
+// if it was rotated out before.
+func (a *authRepository) sessionTokenError(sessionID, userID int64) error {
+	var live bool
+	err := a.dbUser.QueryRow(`
+		SELECT revoked_at IS NULL AND expires_at > NOW() FROM sessions
+		WHERE id = $1 AND user_id = $2`,
+		sessionID, userID,
+	).Scan(&live)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		a.log.Error("Failed to check session", zap.Int64("sessionID", sessionID), zap.Error(err))
+		return err
+	}
+
+	if live {
+		a.log.Warn("Rotated refresh token reused", zap.Int64("sessionID", sessionID), zap.Int64("userID", userID))
+		return ErrRefreshTokenReused
+	}
+
+	a.log.Warn("Refresh token does not match a live session", zap.Int64("sessionID", sessionID))
+	return ErrInvalidRefreshToken
+}
+
+// revokedSessions returns the sessions revoked within the given time with the
+// time of the revocation, access tokens issued for them may still be around.
+func (a *authRepository) revokedSessions(within time.Duration) (map[int64]time.Time, error) {
+	rows, err := a.dbUser.Query(`
+		SELECT id, revoked_at FROM sessions
+		WHERE revoked_at > NOW() - make_interval(secs => $1)`,
+		within.Seconds(),
+	)
+	if err != nil {
+		a.log.Error("Failed to load revoked sessions", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make(map[int64]time.Time)
+	for rows.Next() {
+		var id int64
+		var revokedAt time.Time
+		if err := rows.Scan(&id, &revokedAt); err != nil {
+			return nil, err
+		}
+		sessions[id] = revokedAt
+	}
+
+	return sessions, rows.Err()
+}
+
+// ListSessions returns the live sessions of the user, the most recently used first.
+func (a *authRepository) ListSessions(userID int64) ([]*entity.Session, error) {
+	rows, err := a.dbUser.Query(`
+		SELECT id, user_id, device_name, user_agent, ip, created_at, last_used_at, expires_at
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY last_used_at DESC`,
+		userID,
+	)
+	if err != nil {
+		a.log.Error("Failed to list sessions", zap.Int64("userID", userID), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*entity.Session
+	for rows.Next() {
+		s := &entity.Session{}
+		err := rows.Scan(&s.ID, &s.UserID, &s.DeviceName, &s.UserAgent, &s.IP, &s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt)
+		if err != nil {
+			a.log.Error("Failed to scan session", zap.Error(err))
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+
+	return sessions, rows.Err()
+}
+
+func (a *authRepository) RevokeSession(userID, sessionID int64) error {
+	res, err := a.dbUser.Exec(`
+		UPDATE sessions SET revoked_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`,
+		sessionID, userID,
+	)
+	if err != nil {
+		a.log.Error("Failed to revoke session", zap.Int64("sessionID", sessionID), zap.Error(err))
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrSessionNotFound
+	}
+
+	a.log.Info("Session revoked", zap.Int64("userID", userID), zap.Int64("sessionID", sessionID))
+	return nil
+}
+
+// RevokeOtherSessions revokes every session of the user except keepID and
+// returns the ids of the sessions it revoked.
+func (a *authRepository) RevokeOtherSessions(userID, keepID int64) ([]int64, error) {
+	rows, err := a.dbUser.Query(`
+		UPDATE sessions SET revoked_at = NOW()
+		WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL
+		RETURNING id`,
+		userID, keepID,
+	)
+	if err != nil {
+		a.log.Error("Failed to revoke sessions", zap.Int64("userID", userID), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	a.log.Info("Other sessions revoked", zap.Int64("userID", userID), zap.Int("count", len(ids)))
+	return ids, nil
+}
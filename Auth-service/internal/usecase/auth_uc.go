@@ -2,26 +2,37 @@ package usecase
 
 import (
 	"errors"
+	"sync/atomic"
+	"time"
 
 	"chat-grpc/Auth-service/internal"
+	"chat-grpc/Auth-service/internal/client"
 	"chat-grpc/Auth-service/internal/entity"
 	"chat-grpc/Auth-service/internal/repository"
 	"chat-grpc/Auth-service/jwt"
+	"chat-grpc/pkg/verifier"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthService struct {
-	repo       *repository.AuthRepo
-	jwtService *jwt.JWTService
-	log        *zap.Logger
+	repo          repository.AuthRepo
+	jwtService    *jwt.JWTService
+	notifications client.NotificationClient
+	passwordReset PasswordResetConfig
+	revocations   *revocationList
+	policy        atomic.Pointer[entity.Policy]
+	log           *zap.Logger
 }
 
-func NewAuthService(repo *repository.AuthRepo, jwtService *jwt.JWTService, log *zap.Logger) *AuthService {
+func NewAuthService(repo repository.AuthRepo, jwtService *jwt.JWTService, notifications client.NotificationClient, passwordReset PasswordResetConfig, log *zap.Logger) *AuthService {
 	return &AuthService{
-		repo:       repo,
-		jwtService: jwtService,
-		log:        log,
+		repo:          repo,
+		jwtService:    jwtService,
+		notifications: notifications,
+		passwordReset: passwordReset,
+		revocations:   newRevocationList(),
+		log:           log,
 	}
 }
 
@@ -52,28 +63,50 @@ func (s *AuthService) CreateUser(name, email, password string, role entity.Role)
 	return userID, nil
 }
 
-func (s *AuthService) Login(email, pass string) (string, error) {
+// Login opens a new session for the device, sessions on other devices stay valid.
+func (s *AuthService) Login(email, pass string, device entity.Session) (string, error) {
 	user, err := s.repo.GetUserByUsernameAndValidatePassword(email, pass)
 	if err != nil {
 		s.log.Error("Login failed", zap.Error(err))
 		return "", err
 	}
 
-	refreshToken, err := s.jwtService.GenerateRefreshToken(user.ID, user.Role)
+	device.UserID = user.ID
+	device.ExpiresAt = time.Now().Add(s.jwtService.RefreshTokenDuration)
+	sessionID, err := s.repo.CreateSession(&device)
+	if err != nil {
+		return "", err
+	}
+
+	refreshToken, err := s.issueRefreshToken(user.ID, sessionID)
+	if err != nil {
+		return "", err
+	}
+
+	s.log.Info("User logged in successfully", zap.String("email", email), zap.Int64("sessionID", sessionID))
+	return refreshToken, nil
+}
+
+// issueRefreshToken signs a refresh token for the session and makes it the only
+// valid refresh token of the session.
+func (s *AuthService) issueRefreshToken(userID int64, sessionID int64) (string, error) {
+	expiresAt := time.Now().Add(s.jwtService.RefreshTokenDuration)
+	refreshToken, err := s.jwtService.GenerateRefreshToken(userID, sessionID)
 	if err != nil {
 		s.log.Error("Failed to generate refresh token", zap.Error(err))
 		return "", err
 	}
 
-	if err := s.repo.SaveRefreshToken(user.ID, refreshToken); err != nil {
+	if err := s.repo.SetSessionToken(sessionID, refreshToken, expiresAt); err != nil {
 		s.log.Error("Failed to save refresh token", zap.Error(err))
 		return "", err
 	}
 
-	s.log.Info("User logged in successfully", zap.String("email", email))
 	return refreshToken, nil
 }
 
+// GetNewAccessToken issues an access token with the current role of the user,
+// the refresh token carries none.
 func (s *AuthService) GetNewAccessToken(refreshToken string) (string, error) {
 	claims, err := s.jwtService.VerifyRefreshToken(refreshToken)
 	if err != nil {
@@ -81,11 +114,17 @@ func (s *AuthService) GetNewAccessToken(refreshToken string) (string, error) {
 		return "", errors.New("invalid refresh token")
 	}
 
-	if err := s.repo.CheckRefreshToken(claims.UserID, refreshToken); err != nil {
+	if err := s.repo.UseSession(claims.SessionID, claims.UserID, refreshToken); err != nil {
+		return "", s.refreshFailed(claims, err)
+	}
+
+	user, err := s.repo.GetUser(claims.UserID)
+	if err != nil {
+		s.log.Warn("User of refresh token not found", zap.Int64("userID", claims.UserID), zap.Error(err))
 		return "", err
 	}
 
-	accessToken, err := s.jwtService.GenerateAccessToken(claims.UserID, entity.ParseRole(claims.Role))
+	accessToken, err := s.jwtService.GenerateAccessToken(user.ID, user.Role, claims.SessionID)
 	if err != nil {
 		s.log.Error("Failed to generate access token", zap.Error(err))
 		return "", err
@@ -102,29 +141,39 @@ func (s *AuthService) GetNewRefreshToken(oldToken string) (string, error) {
 		return "", errors.New("invalid refresh token")
 	}
 
-	if err := s.repo.CheckRefreshToken(claims.UserID, oldToken); err != nil {
-		return "", err
-	}
-
-	if err := s.repo.DeleteRefreshToken(claims.UserID); err != nil {
-		return "", err
-	}
-
-	newRefreshToken, err := s.jwtService.GenerateRefreshToken(claims.UserID, entity.ParseRole(claims.Role))
+	newRefreshToken, err := s.jwtService.GenerateRefreshToken(claims.UserID, claims.SessionID)
 	if err != nil {
 		s.log.Error("Failed to generate refresh token", zap.Error(err))
 		return "", err
 	}
 
-	if err := s.repo.SaveRefreshToken(claims.UserID, newRefreshToken); err != nil {
-		s.log.Error("Failed to save new refresh token", zap.Error(err))
-		return "", err
+	expiresAt := time.Now().Add(s.jwtService.RefreshTokenDuration)
+	err = s.repo.RotateSessionToken(claims.SessionID, claims.UserID, oldToken, newRefreshToken, expiresAt)
+	if err != nil {
+		return "", s.refreshFailed(claims, err)
 	}
 
 	s.log.Info("Refresh token updated", zap.Int64("userID", claims.UserID))
 	return newRefreshToken, nil
 }
 
+// refreshFailed revokes the session when a rotated out refresh token is used
+// again. Either the client or someone holding a stolen copy used it, there is
+// no telling which, so the whole session goes.
+func (s *AuthService) refreshFailed(claims *jwt.Claims, err error) error {
+	if !errors.Is(err, repository.ErrRefreshTokenReused) {
+		return err
+	}
+
+	if err := s.revokeSession(claims.UserID, claims.SessionID); err != nil && !errors.Is(err, repository.ErrSessionNotFound) {
+		s.log.Error("Failed to revoke session after refresh token reuse", zap.Int64("sessionID", claims.SessionID), zap.Error(err))
+		return err
+	}
+
+	s.log.Warn("Session revoked after refresh token reuse", zap.Int64("userID", claims.UserID), zap.Int64("sessionID", claims.SessionID))
+	return err
+}
+
 func (s *AuthService) GetUser(id int64) (*entity.User, error) {
 	user, err := s.repo.GetUser(id)
 	if err != nil {
@@ -160,7 +209,14 @@ func (s *AuthService) UpdateUser(id int64, name, email string) error {
 	return nil
 }
 
+// DeleteUser also revokes the tokens of the user, they would stay valid until
+// expiry otherwise.
 func (s *AuthService) DeleteUser(id int64) error {
+	if err := s.revokeUserTokens(id); err != nil {
+		s.log.Error("Failed to revoke tokens of deleted user", zap.Int64("userID", id), zap.Error(err))
+		return err
+	}
+
 	err := s.repo.DeleteUser(id)
 	if err != nil {
 		s.log.Error("Failed to delete user", zap.Int64("userID", id), zap.Error(err))
@@ -171,15 +227,30 @@ func (s *AuthService) DeleteUser(id int64) error {
 	return nil
 }
 
-func (s *AuthService) CheckToken(accessToken string) error {
+// VerifyAccessToken returns the claims of a valid access token that has not
+// been revoked.
+func (s *AuthService) VerifyAccessToken(accessToken string) (*jwt.Claims, error) {
 	if accessToken == "" {
-		return errors.New("empty token")
+		return nil, errors.New("empty token")
 	}
 
-	_, err := s.jwtService.VerifyAccessToken(accessToken)
+	claims, err := s.jwtService.VerifyAccessToken(accessToken)
 	if err != nil {
 		s.log.Warn("Invalid access token", zap.Error(err))
-		return errors.New("invalid token")
+		return nil, errors.New("invalid token")
+	}
+
+	if s.revocations.revoked(claims) {
+		s.log.Warn("Revoked access token", zap.Int64("userID", claims.UserID), zap.String("jti", claims.ID))
+		return nil, errors.New("token revoked")
+	}
+
+	return claims, nil
+}
+
+func (s *AuthService) CheckToken(accessToken string) error {
+	if _, err := s.VerifyAccessToken(accessToken); err != nil {
+		return err
 	}
 
 	s.log.Info("Access token is valid")
@@ -189,3 +260,8 @@ func (s *AuthService) CheckToken(accessToken string) error {
 func (s *AuthService) GetChatUsersEmails(chatID int64) ([]string, error) {
 	return s.repo.GetChatUsersEmails(chatID)
 }
+
+// JWKS returns the keys other services verify access tokens with.
+func (s *AuthService) JWKS() []verifier.JWK {
+	return s.jwtService.Keys.JWKS()
+}
@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+
+	"go.uber.org/zap"
+)
+
+const (
+	apiKeyPrefix = "bot_"
+	apiKeyBytes  = 32
+	botEmailHost = "@bots.local"
+)
+
+func generateAPIKey() (string, error) {
+	b := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return apiKeyPrefix + hex.EncodeToString(b), nil
+}
+
+// CreateBot registers a bot account and returns its id and API key.
+// The key is shown only once, only its hash is stored.
+func (s *AuthService) CreateBot(name, email string) (int64, string, error) {
+	if name == "" {
+		return 0, "", errors.New("name field cannot be empty")
+	}
+
+	if email == "" {
+		email = name + botEmailHost
+	}
+
+	existingUser, _ := s.repo.GetUserByUsername(name)
+	if existingUser != nil {
+		s.log.Warn("Attempt to create an already existing user", zap.String("name", name))
+		return 0, "", errors.New("user with this name already exists")
+	}
+
+	apiKey, err := generateAPIKey()
+	if err != nil {
+		s.log.Error("Failed to generate api key", zap.Error(err))
+		return 0, "", err
+	}
+
+	botID, err := s.repo.CreateBot(name, email, apiKey)
+	if err != nil {
+		s.log.Error("Failed to create bot", zap.Error(err))
+		return 0, "", err
+	}
+
+	s.log.Info("Bot created successfully", zap.Int64("botID", botID))
+	return botID, apiKey, nil
+}
+
+// RotateBotKey replaces the API key of the bot and revokes the access tokens
+// issued for the old one: a key is rotated when it may have leaked. The key is
+// replaced first, so the old one cannot get a new token after the revocation.
+func (s *AuthService) RotateBotKey(botID int64) (string, error) {
+	apiKey, err := generateAPIKey()
+	if err != nil {
+		s.log.Error("Failed to generate api key", zap.Error(err))
+		return "", err
+	}
+
+	if err := s.repo.RotateBotKey(botID, apiKey); err != nil {
+		s.log.Error("Failed to rotate bot key", zap.Int64("botID", botID), zap.Error(err))
+		return "", err
+	}
+
+	if err := s.revokeUserTokens(botID); err != nil {
+		s.log.Error("Failed to revoke tokens of rotated bot key", zap.Int64("botID", botID), zap.Error(err))
+		return "", err
+	}
+
+	return apiKey, nil
+}
+
+// AuthenticateBot exchanges a bot API key for a regular access token.
+func (s *AuthService) AuthenticateBot(apiKey string) (string, error) {
+	if apiKey == "" {
+		return "", errors.New("empty api key")
+	}
+
+	bot, err := s.repo.GetBotByAPIKey(apiKey)
+	if err != nil {
+		return "", err
+	}
+
+	accessToken, err := s.jwtService.GenerateAccessToken(bot.ID, bot.Role, 0)
+	if err != nil {
+		s.log.Error("Failed to generate access token", zap.Error(err))
+		return "", err
+	}
+
+	s.log.Info("Bot authenticated", zap.Int64("botID", bot.ID))
+	return accessToken, nil
+}
@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"chat-grpc/Auth-service/internal/entity"
+	"chat-grpc/Auth-service/internal/repository"
+	"chat-grpc/Auth-service/jwt"
+	"chat-grpc/proto_gen"
+	"go.uber.org/zap"
+)
+
+type fakeReset struct {
+	userID    int64
+	token     string
+	createdAt time.Time
+	expiresAt time.Time
+	used      bool
+}
+
+// fakeRepo keeps users, their sessions and password reset tokens in memory.
+// Reset tokens are throttled the way the database counts them.
+type fakeRepo struct {
+	repository.AuthRepo
+
+	mu           sync.Mutex
+	users        map[int64]*entity.User
+	resets       []*fakeReset
+	sessions     map[int64][]int64
+	revokedUsers map[int64]time.Time
+}
+
+func newFakeRepo(users ...*entity.User) *fakeRepo {
+	r := &fakeRepo{
+		users:        make(map[int64]*entity.User),
+		sessions:     make(map[int64][]int64),
+		revokedUsers: make(map[int64]time.Time),
+	}
+	for _, u := range users {
+		r.users[u.ID] = u
+	}
+
+	return r
+}
+
+func (r *fakeRepo) GetUser(id int64) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.users[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	copied := *u
+
+	return &copied, nil
+}
+
+func (r *fakeRepo) GetUserByEmail(email string) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, u := range r.users {
+		if u.Email == email {
+			copied := *u
+			return &copied, nil
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+func (r *fakeRepo) UpdatePassword(userID int64, passwordHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.users[userID].Password = passwordHash
+	return nil
+}
+
+func (r *fakeRepo) CreatePasswordReset(userID int64, token string, expiresAt time.Time, maxPending, maxRecent int, window time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	var pending, recent int
+	for _, reset := range r.resets {
+		if reset.userID != userID {
+			continue
+		}
+		if !reset.used && reset.expiresAt.After(now) {
+			pending++
+		}
+		if reset.createdAt.After(now.Add(-window)) {
+			recent++
+		}
+	}
+	if pending >= maxPending || recent >= maxRecent {
+		return repository.ErrTooManyResets
+	}
+
+	r.resets = append(r.resets, &fakeReset{userID: userID, token: token, createdAt: now, expiresAt: expiresAt})
+	return nil
+}
+
+func (r *fakeRepo) ResetPassword(token, passwordHash string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var found *fakeReset
+	for _, reset := range r.resets {
+		if reset.token == token && !reset.used && reset.expiresAt.After(time.Now()) {
+			found = reset
+		}
+	}
+	if found == nil {
+		return 0, repository.ErrInvalidResetToken
+	}
+
+	for _, reset := range r.resets {
+		if reset.userID == found.userID {
+			reset.used = true
+		}
+	}
+	r.users[found.userID].Password = passwordHash
+
+	return found.userID, nil
+}
+
+func (r *fakeRepo) RevokeOtherSessions(userID, keepID int64) ([]int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var revoked, kept []int64
+	for _, id := range r.sessions[userID] {
+		if id == keepID {
+			kept = append(kept, id)
+		} else {
+			revoked = append(revoked, id)
+		}
+	}
+	r.sessions[userID] = kept
+
+	return revoked, nil
+}
+
+func (r *fakeRepo) RevokeUserTokens(userID int64, before, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.revokedUsers[userID] = before
+	return nil
+}
+
+// fakeNotifications collects the emails instead of sending them.
+type fakeNotifications struct {
+	mu     sync.Mutex
+	emails []*proto_gen.SendEmailRequest
+}
+
+func (n *fakeNotifications) SendEmail(ctx context.Context, req *proto_gen.SendEmailRequest) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.emails = append(n.emails, req)
+	return nil
+}
+
+func (n *fakeNotifications) sent() []*proto_gen.SendEmailRequest {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return append([]*proto_gen.SendEmailRequest(nil), n.emails...)
+}
+
+func newTestService(repo *fakeRepo) (*AuthService, *fakeNotifications) {
+	notifications := &fakeNotifications{}
+	jwtService := jwt.NewJWTService(nil, "refresh_secret_key", time.Minute, time.Hour, zap.NewNop())
+	s := NewAuthService(repo, jwtService, notifications, PasswordResetConfig{
+		URL: "https://chat.example/reset",
+		TTL: time.Hour,
+	}, zap.NewNop())
+
+	return s, notifications
+}
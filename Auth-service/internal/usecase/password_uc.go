@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"chat-grpc/Auth-service/internal/entity"
+	"chat-grpc/Auth-service/internal/repository"
+	"chat-grpc/Auth-service/jwt"
+	"chat-grpc/proto_gen"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrWrongPassword = errors.New("incorrect password")
+
+const (
+	// maxPendingResets is how many unused reset links a user may have at once.
+	maxPendingResets = 3
+	// maxResetsPerWindow bounds the reset emails sent to a user in resetWindow.
+	maxResetsPerWindow = 5
+	resetWindow        = time.Hour
+)
+
+// PasswordResetConfig tells where the reset link leads and how long it works.
+type PasswordResetConfig struct {
+	URL string
+	TTL time.Duration
+}
+
+// ChangePassword sets a new password for the user of the token. Every session
+// and token of the user is revoked, the user has to log in again. The handler
+// has validated the new password.
+func (s *AuthService) ChangePassword(claims *jwt.Claims, oldPassword, newPassword string) error {
+	user, err := s.repo.GetUser(claims.UserID)
+	if err != nil {
+		return err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(oldPassword)); err != nil {
+		s.log.Warn("Password change with a wrong password", zap.Int64("userID", claims.UserID))
+		return ErrWrongPassword
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		s.log.Error("Failed to hash password", zap.Error(err))
+		return errors.New("failed to secure password")
+	}
+
+	if err := s.repo.UpdatePassword(claims.UserID, string(hashedPassword)); err != nil {
+		return err
+	}
+
+	return s.revokeUserTokens(claims.UserID)
+}
+
+// RequestPasswordReset emails a single-use reset link to the user. An unknown
+// email, a throttled request and a failed email are not errors, the caller must
+// not learn who is registered.
+func (s *AuthService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.repo.GetUserByEmail(email)
+	if errors.Is(err, sql.ErrNoRows) {
+		s.log.Info("Password reset requested for unknown email")
+		return nil
+	}
+	if err != nil {
+		s.log.Error("Failed to find user for password reset", zap.Error(err))
+		return err
+	}
+	if user.Role == entity.BotRole {
+		s.log.Info("Password reset requested for a bot", zap.Int64("userID", user.ID))
+		return nil
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+	token := hex.EncodeToString(b)
+
+	expiresAt := time.Now().Add(s.passwordReset.TTL)
+	err = s.repo.CreatePasswordReset(user.ID, token, expiresAt, maxPendingResets, maxResetsPerWindow, resetWindow)
+	if errors.Is(err, repository.ErrTooManyResets) {
+		s.log.Warn("Password reset throttled", zap.Int64("userID", user.ID))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	link := s.passwordReset.URL + "?token=" + url.QueryEscape(token)
+	err = s.notifications.SendEmail(ctx, &proto_gen.SendEmailRequest{
+		To:      user.Email,
+		Subject: "Password reset",
+		Body: fmt.Sprintf("To set a new password follow the link: %s\nThe link works once and expires in %s. "+
+			"If you did not ask for a password reset, ignore this email.", link, s.passwordReset.TTL),
+	})
+	if err != nil {
+		s.log.Error("Failed to send password reset email", zap.Int64("userID", user.ID), zap.Error(err))
+		return nil
+	}
+
+	s.log.Info("Password reset email sent", zap.Int64("userID", user.ID))
+	return nil
+}
+
+// ResetPassword sets a new password with a reset token and signs the user out
+// of every session. The handler has validated the new password.
+func (s *AuthService) ResetPassword(token, newPassword string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		s.log.Error("Failed to hash password", zap.Error(err))
+		return errors.New("failed to secure password")
+	}
+
+	userID, err := s.repo.ResetPassword(token, string(hashedPassword))
+	if err != nil {
+		return err
+	}
+
+	return s.revokeUserTokens(userID)
+}
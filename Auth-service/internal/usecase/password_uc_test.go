@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"chat-grpc/Auth-service/internal/entity"
+	"chat-grpc/Auth-service/internal/repository"
+	"chat-grpc/Auth-service/jwt"
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const testEmail = "alice@example.com"
+
+func newTestUser(t *testing.T, password string) *entity.User {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	return &entity.User{ID: 1, Name: "alice", Email: testEmail, Password: string(hash), Role: entity.UserRole}
+}
+
+// resetToken takes the token out of the link in a reset email.
+func resetToken(t *testing.T, body string) string {
+	t.Helper()
+
+	_, rest, ok := strings.Cut(body, "?token=")
+	require.True(t, ok, body)
+	escaped, _, _ := strings.Cut(rest, "\n")
+	token, err := url.QueryUnescape(escaped)
+	require.NoError(t, err)
+
+	return token
+}
+
+// issuedBefore is a token of the session issued before the call under test.
+func issuedBefore(userID, sessionID int64) *jwt.Claims {
+	return &jwt.Claims{
+		UserID:    userID,
+		SessionID: sessionID,
+		RegisteredClaims: gojwt.RegisteredClaims{
+			IssuedAt: gojwt.NewNumericDate(time.Now().Add(-time.Second)),
+		},
+	}
+}
+
+func TestPasswordResetIsThrottled(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepo(newTestUser(t, "old_password"))
+	s, notifications := newTestService(repo)
+
+	for range maxPendingResets + 1 {
+		require.NoError(t, s.RequestPasswordReset(ctx, testEmail))
+	}
+	emails := notifications.sent()
+	require.Len(t, emails, maxPendingResets, "only three links may be pending")
+
+	// using a link voids the pending ones, the hourly limit still counts them
+	require.NoError(t, s.ResetPassword(resetToken(t, emails[0].Body), "new_password"))
+	for range maxResetsPerWindow {
+		require.NoError(t, s.RequestPasswordReset(ctx, testEmail))
+	}
+	require.Len(t, notifications.sent(), maxResetsPerWindow, "only five links may be sent in an hour")
+}
+
+func TestPasswordResetOfUnknownEmailOrBotSendsNothing(t *testing.T) {
+	ctx := context.Background()
+	bot := &entity.User{ID: 2, Name: "helper", Email: "helper@example.com", Role: entity.BotRole}
+	s, notifications := newTestService(newFakeRepo(bot))
+
+	require.NoError(t, s.RequestPasswordReset(ctx, "nobody@example.com"))
+	require.NoError(t, s.RequestPasswordReset(ctx, bot.Email))
+	require.Empty(t, notifications.sent())
+}
+
+func TestPasswordResetTokenWorksOnce(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepo(newTestUser(t, "old_password"))
+	s, notifications := newTestService(repo)
+
+	require.NoError(t, s.RequestPasswordReset(ctx, testEmail))
+	require.NoError(t, s.RequestPasswordReset(ctx, testEmail))
+	emails := notifications.sent()
+	require.Len(t, emails, 2)
+	first, second := resetToken(t, emails[0].Body), resetToken(t, emails[1].Body)
+	require.NotEqual(t, first, second)
+
+	require.NoError(t, s.ResetPassword(first, "new_password"))
+	user, err := repo.GetUser(1)
+	require.NoError(t, err)
+	require.NoError(t, bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("new_password")))
+
+	require.ErrorIs(t, s.ResetPassword(first, "another_password"), repository.ErrInvalidResetToken)
+	require.ErrorIs(t, s.ResetPassword(second, "another_password"), repository.ErrInvalidResetToken,
+		"the other pending links stop working too")
+	require.ErrorIs(t, s.ResetPassword("forged", "another_password"), repository.ErrInvalidResetToken)
+}
+
+func TestPasswordResetRevokesAllSessions(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepo(newTestUser(t, "old_password"))
+	repo.sessions[1] = []int64{10, 11}
+	s, notifications := newTestService(repo)
+
+	require.NoError(t, s.RequestPasswordReset(ctx, testEmail))
+	claims := issuedBefore(1, 10)
+	require.False(t, s.revocations.revoked(claims))
+
+	require.NoError(t, s.ResetPassword(resetToken(t, notifications.sent()[0].Body), "new_password"))
+
+	require.Empty(t, repo.sessions[1])
+	require.Contains(t, repo.revokedUsers, int64(1))
+	require.True(t, s.revocations.revoked(claims))
+	require.True(t, s.revocations.revoked(issuedBefore(1, 11)))
+}
+
+func TestChangePassword(t *testing.T) {
+	repo := newFakeRepo(newTestUser(t, "old_password"))
+	repo.sessions[1] = []int64{10}
+	s, _ := newTestService(repo)
+	claims := issuedBefore(1, 10)
+
+	require.ErrorIs(t, s.ChangePassword(claims, "wrong_password", "new_password"), ErrWrongPassword)
+	require.Equal(t, []int64{10}, repo.sessions[1])
+	require.False(t, s.revocations.revoked(claims))
+
+	require.NoError(t, s.ChangePassword(claims, "old_password", "new_password"))
+	require.Empty(t, repo.sessions[1])
+	require.True(t, s.revocations.revoked(claims))
+}
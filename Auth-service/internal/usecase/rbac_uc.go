@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"time"
+
+	"chat-grpc/Auth-service/internal/entity"
+	"chat-grpc/Auth-service/jwt"
+	"go.uber.org/zap"
+)
+
+// LoadPolicy reads the permissions of the methods and roles, it must be done
+// before the first access check.
+func (s *AuthService) LoadPolicy() error {
+	policy, err := s.repo.GetPolicy()
+	if err != nil {
+		return err
+	}
+
+	s.policy.Store(policy)
+	return nil
+}
+
+// SyncPolicy reloads the policy every interval, so grants changed in the
+// tables apply without a restart.
+func (s *AuthService) SyncPolicy(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := s.LoadPolicy(); err != nil {
+			s.log.Error("Failed to sync access policy", zap.Error(err))
+		}
+	}
+}
+
+// CheckAccess tells whether the holder of the token may call the method.
+func (s *AuthService) CheckAccess(claims *jwt.Claims, method string) bool {
+	policy := s.policy.Load()
+	if policy == nil {
+		return false
+	}
+
+	allowed := policy.Allows(entity.ParseRole(claims.Role), method)
+	if !allowed {
+		s.log.Info("Access denied", zap.Int64("userID", claims.UserID), zap.String("role", claims.Role), zap.String("method", method))
+	}
+
+	return allowed
+}
+
+// HasPermission tells whether the role of the token was granted the permission.
+func (s *AuthService) HasPermission(claims *jwt.Claims, permission string) bool {
+	policy := s.policy.Load()
+
+	return policy != nil && policy.Grants(entity.ParseRole(claims.Role), permission)
+}
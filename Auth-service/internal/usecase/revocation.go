@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"sync"
+	"time"
+
+	"chat-grpc/Auth-service/internal/repository"
+	"chat-grpc/Auth-service/jwt"
+	"go.uber.org/zap"
+)
+
+// revocationList is the in-memory copy of the revocation list, so CheckToken
+// does not hit the database. Entries live as long as the tokens they revoke,
+// revocations made by other replicas arrive with the next sync.
+type revocationList struct {
+	mu       sync.RWMutex
+	tokens   map[string]time.Time
+	users    map[int64]time.Time
+	sessions map[int64]time.Time
+}
+
+func newRevocationList() *revocationList {
+	return &revocationList{
+		tokens:   make(map[string]time.Time),
+		users:    make(map[int64]time.Time),
+		sessions: make(map[int64]time.Time),
+	}
+}
+
+func (l *revocationList) revoked(claims *jwt.Claims) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if _, ok := l.tokens[claims.ID]; ok {
+		return true
+	}
+	if _, ok := l.sessions[claims.SessionID]; ok && claims.SessionID != 0 {
+		return true
+	}
+
+	before, ok := l.users[claims.UserID]
+	return ok && claims.IssuedAt != nil && claims.IssuedAt.Before(before)
+}
+
+func (l *revocationList) addToken(jti string, expiresAt time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens[jti] = expiresAt
+}
+
+func (l *revocationList) addUser(userID int64, before time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.users[userID] = before
+}
+
+func (l *revocationList) addSession(sessionID int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sessions[sessionID] = time.Now()
+}
+
+func (l *revocationList) replace(revocations *repository.Revocations) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens = revocations.Tokens
+	l.users = revocations.Users
+	l.sessions = revocations.Sessions
+}
+
+// LoadRevocations reads the revocation list, it must be done before the first
+// token is checked.
+func (s *AuthService) LoadRevocations() error {
+	revocations, err := s.repo.GetRevocations(s.jwtService.AccessTokenDuration)
+	if err != nil {
+		return err
+	}
+
+	s.revocations.replace(revocations)
+	return nil
+}
+
+// SyncRevocations reloads the revocation list every interval.
+func (s *AuthService) SyncRevocations(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := s.LoadRevocations(); err != nil {
+			s.log.Error("Failed to sync revocation list", zap.Error(err))
+		}
+	}
+}
+
+// revokeToken puts the token on the revocation list until it expires.
+func (s *AuthService) revokeToken(claims *jwt.Claims) error {
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return nil
+	}
+
+	if err := s.repo.RevokeToken(claims.ID, claims.UserID, claims.ExpiresAt.Time); err != nil {
+		return err
+	}
+
+	s.revocations.addToken(claims.ID, claims.ExpiresAt.Time)
+	return nil
+}
+
+// revokeSession ends the session and revokes the access tokens issued for it.
+func (s *AuthService) revokeSession(userID, sessionID int64) error {
+	if err := s.repo.RevokeSession(userID, sessionID); err != nil {
+		return err
+	}
+
+	s.revocations.addSession(sessionID)
+	return nil
+}
+
+// revokeOtherSessions ends every session of the user except keepID and returns
+// how many it ended.
+func (s *AuthService) revokeOtherSessions(userID, keepID int64) (int, error) {
+	ids, err := s.repo.RevokeOtherSessions(userID, keepID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		s.revocations.addSession(id)
+	}
+	return len(ids), nil
+}
+
+// revokeUserTokens signs the user out of every session and revokes all of the
+// tokens issued to the user so far.
+func (s *AuthService) revokeUserTokens(userID int64) error {
+	if _, err := s.revokeOtherSessions(userID, 0); err != nil {
+		return err
+	}
+
+	// Tokens carry the issue time in jwt.TimePrecision. The cutoff is the start
+	// of the next tick, which is awaited, so every token issued so far is before
+	// it and every token issued from now on is not.
+	before := time.Now().Truncate(jwt.TimePrecision).Add(jwt.TimePrecision)
+	if err := s.repo.RevokeUserTokens(userID, before, before.Add(s.jwtService.AccessTokenDuration)); err != nil {
+		return err
+	}
+	s.revocations.addUser(userID, before)
+	time.Sleep(time.Until(before))
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"errors"
+
+	"chat-grpc/Auth-service/internal/entity"
+	"chat-grpc/Auth-service/internal/repository"
+	"chat-grpc/Auth-service/jwt"
+	"go.uber.org/zap"
+)
+
+var ErrNoSession = errors.New("token is not bound to a session")
+
+func (s *AuthService) ListSessions(userID, currentSessionID int64) ([]*entity.Session, error) {
+	if currentSessionID == 0 {
+		return nil, ErrNoSession
+	}
+
+	return s.repo.ListSessions(userID)
+}
+
+func (s *AuthService) RevokeSession(userID, sessionID int64) error {
+	return s.revokeSession(userID, sessionID)
+}
+
+// RevokeAllOtherSessions signs the user out everywhere except the current session.
+func (s *AuthService) RevokeAllOtherSessions(userID, currentSessionID int64) error {
+	if currentSessionID == 0 {
+		return ErrNoSession
+	}
+
+	n, err := s.revokeOtherSessions(userID, currentSessionID)
+	if err != nil {
+		return err
+	}
+
+	s.log.Info("Signed out of other sessions", zap.Int64("userID", userID), zap.Int("revoked", n))
+	return nil
+}
+
+// Logout ends the session of the token, its refresh token stops working and the
+// access token itself is revoked.
+func (s *AuthService) Logout(claims *jwt.Claims) error {
+	if claims.SessionID != 0 {
+		err := s.revokeSession(claims.UserID, claims.SessionID)
+		if err != nil && !errors.Is(err, repository.ErrSessionNotFound) {
+			return err
+		}
+	}
+
+	if err := s.revokeToken(claims); err != nil {
+		return err
+	}
+
+	s.log.Info("User logged out", zap.Int64("userID", claims.UserID), zap.Int64("sessionID", claims.SessionID))
+	return nil
+}
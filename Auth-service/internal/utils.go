@@ -13,6 +13,10 @@ func Validate(name, email, pass string) error {
 		return errors.New("email field cannot be empty")
 	}
 
+	return ValidatePassword(pass)
+}
+
+func ValidatePassword(pass string) error {
 	if len(pass) < 4 {
 		return errors.New("pass must be more than 4 char")
 	}
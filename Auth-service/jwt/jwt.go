@@ -1,92 +1,128 @@
 package jwt
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"time"
 
 	"chat-grpc/Auth-service/internal/entity"
+	"chat-grpc/pkg/verifier"
 	"github.com/golang-jwt/jwt/v5"
 	"go.uber.org/zap"
 )
 
-type JWTService struct {
-	Key           string
-	TokenDuration time.Duration
-	log           *zap.Logger
+const (
+	AccessTokenType  = verifier.AccessTokenType
+	RefreshTokenType = verifier.RefreshTokenType
+)
+
+// TimePrecision is the precision of the issue and expiry times in the tokens.
+// Whole seconds would make a token issued right after the revocation of all of
+// the user's tokens indistinguishable from one issued right before it.
+const TimePrecision = time.Millisecond
+
+func init() {
+	jwt.TimePrecision = TimePrecision
 }
 
-type Claims struct {
-	UserID int64
-	Role   string
-	jwt.RegisteredClaims
+// JWTService signs access tokens with the active key of the key set, so other
+// services can verify them with the public keys. Refresh tokens only ever come
+// back to this service and are signed with a secret.
+type JWTService struct {
+	Keys                 *KeySet
+	RefreshKey           string
+	AccessTokenDuration  time.Duration
+	RefreshTokenDuration time.Duration
+	log                  *zap.Logger
 }
 
-func NewJWTService(key string, tokenDuration time.Duration, log *zap.Logger) *JWTService {
+type Claims = verifier.Claims
+
+func NewJWTService(keys *KeySet, refreshKey string, accessTokenDuration, refreshTokenDuration time.Duration, log *zap.Logger) *JWTService {
 	return &JWTService{
-		Key:           key,
-		TokenDuration: tokenDuration,
-		log:           log,
+		Keys:                 keys,
+		RefreshKey:           refreshKey,
+		AccessTokenDuration:  accessTokenDuration,
+		RefreshTokenDuration: refreshTokenDuration,
+		log:                  log,
 	}
 }
 
-func (j *JWTService) GenerateAccessToken(userID int64, role entity.Role) (string, error) {
-	claims := &Claims{
-		UserID: userID,
-		Role:   role.StringRole(),
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.TokenDuration)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
-	}
+// newTokenID returns the jti of a token, it is what the revocation list stores.
+func newTokenID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+func (j *JWTService) GenerateAccessToken(userID int64, role entity.Role, sessionID int64) (string, error) {
+	token := jwt.NewWithClaims(j.Keys.signingMethod(), newClaims(userID, role.StringRole(), sessionID, AccessTokenType, j.AccessTokenDuration))
+	token.Header["kid"] = j.Keys.activeID
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.Key))
+	return token.SignedString(j.Keys.active)
 }
 
-func (j *JWTService) GenerateRefreshToken(userID int64, role entity.Role) (string, error) {
-	claims := &Claims{
-		UserID: userID,
-		Role:   role.StringRole(),
+// GenerateRefreshToken signs a refresh token without a role: the role of an
+// access token is read from the user when it is issued, so a changed role
+// applies from the next refresh.
+func (j *JWTService) GenerateRefreshToken(userID int64, sessionID int64) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, newClaims(userID, "", sessionID, RefreshTokenType, j.RefreshTokenDuration))
+
+	return token.SignedString([]byte(j.RefreshKey))
+}
+
+func newClaims(userID int64, role string, sessionID int64, tokenType string, duration time.Duration) *Claims {
+	return &Claims{
+		UserID:    userID,
+		Role:      role,
+		SessionID: sessionID,
+		Type:      tokenType,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.TokenDuration)),
+			ID:        newTokenID(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(duration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.Key))
 }
 
 func (j *JWTService) VerifyAccessToken(tokenStr string) (*Claims, error) {
-	j.log.Info(tokenStr)
-	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(j.Key), nil
-	})
+	claims, err := j.verify(tokenStr, AccessTokenType, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := j.Keys.public[kid]
+		if !ok {
+			return nil, fmt.Errorf("%w %q", verifier.ErrUnknownKey, kid)
+		}
+		return key, nil
+	}, jwt.SigningMethodEdDSA.Alg(), jwt.SigningMethodRS256.Alg())
 	if err != nil {
-		j.log.Info("Error with parsing token", zap.String("token", tokenStr), zap.Error(err))
+		j.log.Info("Error with parsing token", zap.Error(err))
 		return nil, err
 	}
 
-	claims, ok := token.Claims.(*Claims)
-	if !ok || !token.Valid {
-		return nil, errors.New("incorrect token")
-	}
-
 	return claims, nil
 }
 
 func (j *JWTService) VerifyRefreshToken(tokenStr string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(j.Key), nil
-	})
+	return j.verify(tokenStr, RefreshTokenType, func(token *jwt.Token) (interface{}, error) {
+		return []byte(j.RefreshKey), nil
+	}, jwt.SigningMethodHS256.Alg())
+}
 
+func (j *JWTService) verify(tokenStr, tokenType string, keyFunc jwt.Keyfunc, methods ...string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, keyFunc, jwt.WithValidMethods(methods))
 	if err != nil {
 		return nil, err
 	}
 
 	claims, ok := token.Claims.(*Claims)
 	if !ok || !token.Valid {
-		return nil, errors.New("invalid refresh token")
+		return nil, errors.New("incorrect token")
+	}
+	if claims.Type != tokenType {
+		return nil, fmt.Errorf("%s token used as %s token", claims.Type, tokenType)
 	}
 
 	return claims, nil
@@ -0,0 +1,165 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"chat-grpc/pkg/verifier"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// KeySet holds the key access tokens are signed with and the public keys they
+// are verified with. Keys are named by their kid.
+//
+// To rotate, add the new private key and make it active, then keep the old one
+// until the tokens signed with it expire. An old key may be replaced by its
+// public key alone, it then only verifies.
+type KeySet struct {
+	activeID string
+	active   crypto.Signer
+	public   map[string]crypto.PublicKey
+}
+
+// LoadKeys reads the PEM files of dir, the file name without ".pem" is the kid.
+// activeID names the signing key, by default it is the last private key in
+// the order of the names. Ed25519 and RSA keys are supported.
+func LoadKeys(dir, activeID string) (*KeySet, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	ks := &KeySet{public: make(map[string]crypto.PublicKey)}
+	private := make(map[string]crypto.Signer)
+	var lastPrivate string
+	for _, path := range paths {
+		kid := strings.TrimSuffix(filepath.Base(path), ".pem")
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		signer, public, err := parseKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", kid, err)
+		}
+		if signer != nil {
+			private[kid] = signer
+			lastPrivate = kid
+		}
+		ks.public[kid] = public
+	}
+
+	if activeID == "" {
+		activeID = lastPrivate
+	}
+	signer, ok := private[activeID]
+	if !ok {
+		return nil, fmt.Errorf("no private key %q in %s", activeID, dir)
+	}
+	ks.activeID = activeID
+	ks.active = signer
+
+	return ks, nil
+}
+
+// GenerateKeys returns a set with a fresh Ed25519 key. Tokens signed with it
+// stop working when the process exits.
+func GenerateKeys() (*KeySet, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, 4)
+	rand.Read(b)
+	kid := "ephemeral-" + hex.EncodeToString(b)
+
+	return &KeySet{
+		activeID: kid,
+		active:   private,
+		public:   map[string]crypto.PublicKey{kid: public},
+	}, nil
+}
+
+func parseKey(data []byte) (crypto.Signer, crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, nil, errors.New("no PEM block")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		switch key := key.(type) {
+		case ed25519.PrivateKey:
+			return key, key.Public(), nil
+		case *rsa.PrivateKey:
+			return key, key.Public(), nil
+		default:
+			return nil, nil, fmt.Errorf("unsupported key type %T", key)
+		}
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		return key, key.Public(), nil
+	case "PUBLIC KEY":
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		switch key.(type) {
+		case ed25519.PublicKey, *rsa.PublicKey:
+			return nil, key, nil
+		default:
+			return nil, nil, fmt.Errorf("unsupported key type %T", key)
+		}
+	default:
+		return nil, nil, fmt.Errorf("unsupported PEM block %s", block.Type)
+	}
+}
+
+func (ks *KeySet) signingMethod() jwt.SigningMethod {
+	if _, ok := ks.active.(*rsa.PrivateKey); ok {
+		return jwt.SigningMethodRS256
+	}
+
+	return jwt.SigningMethodEdDSA
+}
+
+// JWKS returns the public keys for the GetJWKS RPC and the jwks.json endpoint.
+func (ks *KeySet) JWKS() []verifier.JWK {
+	kids := make([]string, 0, len(ks.public))
+	for kid := range ks.public {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	keys := make([]verifier.JWK, 0, len(kids))
+	for _, kid := range kids {
+		jwk, err := verifier.NewJWK(kid, ks.public[kid])
+		if err != nil {
+			continue
+		}
+		keys = append(keys, jwk)
+	}
+
+	return keys
+}
@@ -0,0 +1,101 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"chat-grpc/Auth-service/internal/entity"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func writePrivateKey(t *testing.T, dir, kid string) ed25519.PrivateKey {
+	t.Helper()
+
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	require.NoError(t, err)
+	writePEM(t, dir, kid, "PRIVATE KEY", der)
+
+	return private
+}
+
+func writePublicKey(t *testing.T, dir, kid string, key crypto.PublicKey) {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+	writePEM(t, dir, kid, "PUBLIC KEY", der)
+}
+
+func writePEM(t *testing.T, dir, kid, blockType string, der []byte) {
+	t.Helper()
+
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, kid+".pem"), data, 0o600))
+}
+
+func newService(keys *KeySet) *JWTService {
+	return NewJWTService(keys, "refresh_secret_key", time.Minute, time.Hour, zap.NewNop())
+}
+
+func TestLoadKeysPicksActiveKey(t *testing.T) {
+	dir := t.TempDir()
+	writePrivateKey(t, dir, "2024-01")
+	writePrivateKey(t, dir, "2024-02")
+
+	keys, err := LoadKeys(dir, "")
+	require.NoError(t, err)
+	require.Equal(t, "2024-02", keys.activeID, "the last private key by name")
+
+	keys, err = LoadKeys(dir, "2024-01")
+	require.NoError(t, err)
+	require.Equal(t, "2024-01", keys.activeID)
+	require.Len(t, keys.JWKS(), 2)
+
+	_, err = LoadKeys(dir, "2023-12")
+	require.Error(t, err)
+}
+
+func TestPublicOnlyKeyVerifiesButDoesNotSign(t *testing.T) {
+	oldDir := t.TempDir()
+	oldPrivate := writePrivateKey(t, oldDir, "old")
+	oldKeys, err := LoadKeys(oldDir, "")
+	require.NoError(t, err)
+	token, err := newService(oldKeys).GenerateAccessToken(1, entity.UserRole, 0)
+	require.NoError(t, err)
+
+	// after the rotation only the public half of the old key is kept
+	dir := t.TempDir()
+	writePublicKey(t, dir, "old", oldPrivate.Public())
+	writePrivateKey(t, dir, "new")
+
+	keys, err := LoadKeys(dir, "")
+	require.NoError(t, err)
+	require.Equal(t, "new", keys.activeID)
+
+	claims, err := newService(keys).VerifyAccessToken(token)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), claims.UserID)
+
+	_, err = LoadKeys(dir, "old")
+	require.ErrorContains(t, err, "no private key")
+}
+
+func TestLoadKeysWithoutPrivateKeyFails(t *testing.T) {
+	dir := t.TempDir()
+	public, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	writePublicKey(t, dir, "old", public)
+
+	_, err = LoadKeys(dir, "")
+	require.Error(t, err)
+}
@@ -1,22 +1,37 @@
 package main
 
 import (
+	"context"
+	"expvar"
 	"net"
+	"net/http"
+	"time"
 
 	"chat-grpc/Auth-service/interceptor"
-	"chat-grpc/Chat-service/internal/broker"
+	"chat-grpc/Chat-service/command"
+	"chat-grpc/Chat-service/internal/entity"
 	"chat-grpc/Chat-service/internal/handler"
+	"chat-grpc/Chat-service/internal/outbox"
 	"chat-grpc/Chat-service/internal/repository"
 	"chat-grpc/Chat-service/internal/usecase"
+	"chat-grpc/Chat-service/internal/webhook"
+	"chat-grpc/Chat-service/moderation"
 	"chat-grpc/pkg"
+	"chat-grpc/pkg/broker"
 	"chat-grpc/pkg/config"
 	"chat-grpc/pkg/logger"
+	"chat-grpc/pkg/verifier"
 	"chat-grpc/proto_gen"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
+const serviceName = "chat-service"
+
+// keepCommandRequests is how long a retried command is recognised as a duplicate.
+const keepCommandRequests = 24 * time.Hour
+
 func main() {
 	cfg := config.LoadConfig()
 	log, err := logger.NewLogger()
@@ -37,15 +52,56 @@ func main() {
 	}
 	defer dbUsers.Close()
 
-	broker, err := broker.NewNatsBroker(cfg.NatsUrl)
+	broker, err := broker.New(cfg, serviceName, log)
 	if err != nil {
-		log.Fatal("failed to connect to NATS", zap.Error(err))
+		log.Fatal("failed to connect to the broker", zap.Error(err))
 	}
 	defer broker.Close()
 
 	chatRepo := repository.NewChatRepository(db, dbUsers, log)
-	chatUseCase := usecase.NewChatUseCase(chatRepo, log, broker)
-	chatHandler := handler.NewChatService(chatUseCase, log)
+	commands := command.NewDefaultRegistry()
+	moderator := moderation.WithRules(moderation.NewFilter(), moderation.Rules{
+		Words:          cfg.ModerationWords,
+		BlockedDomains: cfg.ModerationBlockedDomains,
+	})
+	rateLimits := entity.RateLimits{
+		UserPerMinute: cfg.RateLimitUserPerMinute,
+		UserBurst:     cfg.RateLimitUserBurst,
+		ChatPerMinute: cfg.RateLimitChatPerMinute,
+		ChatBurst:     cfg.RateLimitChatBurst,
+	}
+	userRateLimit := entity.UserRateLimit{
+		PerMinute: cfg.RateLimitGlobalPerMinute,
+		Burst:     cfg.RateLimitGlobalBurst,
+	}
+	chatUseCase := usecase.NewChatUseCase(chatRepo, log, broker, commands, moderator, rateLimits, userRateLimit)
+	go prune(chatRepo, log)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	relay := outbox.NewRelay(chatRepo, broker, pkg.ChatConnString(), log)
+	go relay.Run(ctx)
+	chatHandler := handler.NewChatService(chatUseCase, log, cfg.StreamQueueSize, handler.ParseOverflowPolicy(cfg.StreamOverflowPolicy))
+
+	dispatcher := webhook.NewDispatcher(chatRepo, log, cfg.WebhookTimeout, cfg.WebhookWorkers, cfg.WebhookMaxAttempts, cfg.WebhookMaxFailures)
+	webhookSub, err := dispatcher.Start(broker)
+	if err != nil {
+		log.Fatal("Failed to start webhook dispatcher", zap.Error(err))
+	}
+	defer webhookSub.Unsubscribe()
+	go dispatcher.Run(ctx)
+
+	incomingHandler := handler.NewIncomingWebhookHandler(chatUseCase, log, cfg.IncomingWebhookRate, cfg.IncomingWebhookBurst)
+	mux := http.NewServeMux()
+	mux.Handle("/hooks/", incomingHandler)
+	go func() {
+		log.Info("Incoming webhooks are served on ", zap.String("port", cfg.IncomingWebhookPort))
+		if err := http.ListenAndServe(":"+cfg.IncomingWebhookPort, mux); err != nil {
+			log.Fatal("Failed to serve incoming webhooks", zap.Error(err))
+		}
+	}()
+	go serveMetrics(cfg.MetricsAddr, log)
 
 	listener, err := net.Listen("tcp", ":"+cfg.ServerPortChat)
 	if err != nil {
@@ -60,11 +116,14 @@ func main() {
 
 	log.Info("Successfully connected to auth service")
 
-	authClient := interceptor.NewAuthClient(conn, log)
-	authInterceptor := interceptor.NewAuthInterceptor(authClient, log)
+	authClient := interceptor.NewAuthClient(conn, cfg.ServiceToken, log)
+	tokenVerifier := verifier.New(authClient.KeySource(), cfg.JWKSRefreshInterval)
+	authChecker := interceptor.NewRemoteChecker(authClient, tokenVerifier, cfg.RevocationCheckInterval, cfg.AuthFailOpen, log)
+	authInterceptor := interceptor.NewAuthInterceptor(authChecker, log)
 
 	grpcServer := grpc.NewServer(
 		grpc.UnaryInterceptor(authInterceptor.Unary()),
+		grpc.StreamInterceptor(authInterceptor.Stream()),
 	)
 	proto_gen.RegisterChatServiceServer(grpcServer, chatHandler)
 
@@ -73,3 +132,43 @@ func main() {
 		log.Fatal("Failed to serve", zap.Error(err))
 	}
 }
+
+// serveMetrics serves expvar on its own listener, which must not be reachable
+// from outside: the public incoming webhook port never exposes it.
+func serveMetrics(addr string, log *zap.Logger) {
+	if addr == "" {
+		log.Info("METRICS_ADDR is empty, metrics are not served")
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /debug/vars", expvar.Handler())
+
+	log.Info("Metrics are served on ", zap.String("addr", addr))
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		log.Fatal("Failed to serve metrics", zap.Error(err))
+	}
+}
+
+// prune periodically removes full rate limit buckets so the table only holds
+// recently active users and chats, and forgets old command client ids.
+func prune(repo repository.ChatRepo, log *zap.Logger) {
+	ticker := time.NewTicker(10 * time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		n, err := repo.PruneTokenBuckets(context.Background())
+		if err != nil {
+			log.Warn("Failed to prune rate limit buckets", zap.Error(err))
+		} else {
+			log.Debug("Pruned rate limit buckets", zap.Int64("count", n))
+		}
+
+		n, err = repo.PruneCommandRequests(context.Background(), keepCommandRequests)
+		if err != nil {
+			log.Warn("Failed to prune command requests", zap.Error(err))
+		} else {
+			log.Debug("Pruned command requests", zap.Int64("count", n))
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"chat-grpc/proto_gen"
+)
+
+const shrug = `¯\_(ツ)_/¯`
+
+// MaxRemindAfter is the longest delay of a reminder.
+const MaxRemindAfter = 24 * time.Hour
+
+// NewDefaultRegistry returns a registry with the built-in commands.
+// More commands can be added to it with Register.
+func NewDefaultRegistry() *Registry {
+	r := NewRegistry()
+	for _, cmd := range []*Command{
+		Topic(), Invite(), Mute(), Remind(), Me(), Shrug(), Help(r),
+	} {
+		// built-in names are unique, Register cannot fail here
+		_ = r.Register(cmd)
+	}
+
+	return r
+}
+
+func Topic() *Command {
+	return &Command{
+		Name:        "topic",
+		Usage:       "/topic <text>",
+		Description: "change the chat topic",
+		MinArgs:     1,
+		Permission:  AdminPermission,
+		Handler: func(ctx context.Context, b Backend, call *Call) (*Reply, error) {
+			if err := b.SetTopic(ctx, call.ChatID, call.Raw); err != nil {
+				return nil, err
+			}
+
+			return &Reply{
+				Text: fmt.Sprintf("%s changed the topic to %q", call.From, call.Raw),
+				Event: &proto_gen.SystemEvent{
+					Type:  "topic_changed",
+					Actor: call.From,
+					Data:  map[string]string{"topic": call.Raw},
+				},
+			}, nil
+		},
+	}
+}
+
+func Invite() *Command {
+	return &Command{
+		Name:        "invite",
+		Usage:       "/invite <username>",
+		Description: "add a user to the chat",
+		MinArgs:     1,
+		Permission:  AdminPermission,
+		Handler: func(ctx context.Context, b Backend, call *Call) (*Reply, error) {
+			username := call.Args[0]
+			if err := b.Invite(ctx, call.ChatID, username); err != nil {
+				return nil, err
+			}
+
+			return &Reply{
+				Text: fmt.Sprintf("%s added %s to the chat", call.From, username),
+				Event: &proto_gen.SystemEvent{
+					Type:  "member_added",
+					Actor: call.From,
+					Data:  map[string]string{"username": username},
+				},
+			}, nil
+		},
+	}
+}
+
+func Mute() *Command {
+	return &Command{
+		Name:        "mute",
+		Usage:       "/mute <username> <duration, e.g. 30m>",
+		Description: "forbid a member to post for a while",
+		MinArgs:     2,
+		Permission:  AdminPermission,
+		Handler: func(ctx context.Context, b Backend, call *Call) (*Reply, error) {
+			username := call.Args[0]
+			d, err := time.ParseDuration(call.Args[1])
+			if err != nil || d <= 0 {
+				return nil, Errorf("invalid duration %q", call.Args[1])
+			}
+
+			until := time.Now().Add(d)
+			if err := b.Mute(ctx, call.ChatID, username, until); err != nil {
+				return nil, err
+			}
+
+			return &Reply{
+				Text: fmt.Sprintf("%s muted %s for %s", call.From, username, d),
+				Event: &proto_gen.SystemEvent{
+					Type:  "member_muted",
+					Actor: call.From,
+					Data:  map[string]string{"username": username, "until": until.UTC().Format(time.RFC3339)},
+				},
+			}, nil
+		},
+	}
+}
+
+func Remind() *Command {
+	return &Command{
+		Name:        "remind",
+		Usage:       "/remind <duration, e.g. 10m> <text>",
+		Description: "send yourself a reminder",
+		MinArgs:     2,
+		Permission:  MemberPermission,
+		Handler: func(ctx context.Context, b Backend, call *Call) (*Reply, error) {
+			d, err := time.ParseDuration(call.Args[0])
+			if err != nil || d <= 0 {
+				return nil, Errorf("invalid duration %q", call.Args[0])
+			}
+			if d > MaxRemindAfter {
+				return nil, Errorf("reminders can be set for at most %s", MaxRemindAfter)
+			}
+
+			text := strings.TrimSpace(strings.TrimPrefix(call.Raw, call.Args[0]))
+			if err := b.Remind(call.ChatID, call.From, d, text); err != nil {
+				return nil, err
+			}
+
+			return &Reply{Text: fmt.Sprintf("I will remind you in %s", d), Ephemeral: true}, nil
+		},
+	}
+}
+
+func Me() *Command {
+	return &Command{
+		Name:        "me",
+		Usage:       "/me <action>",
+		Description: "describe what you are doing",
+		MinArgs:     1,
+		Permission:  MemberPermission,
+		Handler: func(ctx context.Context, b Backend, call *Call) (*Reply, error) {
+			return &Reply{Text: fmt.Sprintf("* %s %s", call.From, call.Raw)}, nil
+		},
+	}
+}
+
+func Shrug() *Command {
+	return &Command{
+		Name:        "shrug",
+		Usage:       "/shrug [text]",
+		Description: "append " + shrug + " to your message",
+		Permission:  MemberPermission,
+		Handler: func(ctx context.Context, b Backend, call *Call) (*Reply, error) {
+			return &Reply{Text: strings.TrimSpace(call.Raw + " " + shrug)}, nil
+		},
+	}
+}
+
+func Help(r *Registry) *Command {
+	return &Command{
+		Name:        "help",
+		Usage:       "/help",
+		Description: "list available commands",
+		Permission:  MemberPermission,
+		Handler: func(ctx context.Context, b Backend, call *Call) (*Reply, error) {
+			var sb strings.Builder
+			for _, cmd := range r.Commands() {
+				fmt.Fprintf(&sb, "%s — %s\n", cmd.Usage, cmd.Description)
+			}
+
+			return &Reply{Text: strings.TrimSpace(sb.String()), Ephemeral: true}, nil
+		},
+	}
+}
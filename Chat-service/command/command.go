@@ -0,0 +1,195 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"chat-grpc/proto_gen"
+)
+
+const Prefix = "/"
+
+type Permission int
+
+const (
+	MemberPermission Permission = iota
+	AdminPermission
+)
+
+// Backend is the set of chat operations available to command handlers.
+// It is implemented by the Chat-service use case.
+type Backend interface {
+	Permission(ctx context.Context, chatID int64, username string) (Permission, error)
+	SetTopic(ctx context.Context, chatID int64, topic string) error
+	Invite(ctx context.Context, chatID int64, username string) error
+	Mute(ctx context.Context, chatID int64, username string, until time.Time) error
+	Remind(chatID int64, username string, after time.Duration, text string) error
+}
+
+type Call struct {
+	ChatID int64
+	From   string
+	Name   string
+	Args   []string
+	// Raw is everything after the command name, as typed.
+	Raw string
+}
+
+// Reply is what a command sends back. An ephemeral reply is delivered only to
+// the caller's streams and is never stored; otherwise it is broadcast to the chat
+// as a message from the caller, optionally carrying a system event.
+type Reply struct {
+	Text      string
+	Ephemeral bool
+	Event     *proto_gen.SystemEvent
+}
+
+type Handler func(ctx context.Context, b Backend, call *Call) (*Reply, error)
+
+type Command struct {
+	Name        string
+	Usage       string
+	Description string
+	MinArgs     int
+	Permission  Permission
+	Handler     Handler
+}
+
+// Error is a user-facing command failure (unknown command, bad arguments,
+// missing permission). It is shown to the caller instead of failing the request.
+type Error struct {
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func Errorf(format string, args ...interface{}) error {
+	return &Error{Message: fmt.Sprintf(format, args...)}
+}
+
+type Registry struct {
+	mu       sync.RWMutex
+	commands map[string]*Command
+}
+
+func NewRegistry() *Registry {
+	return &Registry{commands: make(map[string]*Command)}
+}
+
+func (r *Registry) Register(cmd *Command) error {
+	if cmd.Name == "" || cmd.Handler == nil {
+		return errors.New("command must have a name and a handler")
+	}
+
+	name := strings.ToLower(cmd.Name)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.commands[name]; ok {
+		return fmt.Errorf("command /%s is already registered", name)
+	}
+	r.commands[name] = cmd
+
+	return nil
+}
+
+func (r *Registry) Lookup(name string) (*Command, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cmd, ok := r.commands[strings.ToLower(name)]
+	return cmd, ok
+}
+
+func (r *Registry) Commands() []*Command {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cmds := make([]*Command, 0, len(r.commands))
+	for _, cmd := range r.commands {
+		cmds = append(cmds, cmd)
+	}
+	sort.Slice(cmds, func(i, j int) bool { return cmds[i].Name < cmds[j].Name })
+
+	return cmds
+}
+
+func (r *Registry) Dispatch(ctx context.Context, b Backend, call *Call) (*Reply, error) {
+	cmd, ok := r.Lookup(call.Name)
+	if !ok {
+		return nil, Errorf("unknown command /%s, see /help", call.Name)
+	}
+
+	if len(call.Args) < cmd.MinArgs {
+		return nil, Errorf("usage: %s", cmd.Usage)
+	}
+
+	perm, err := b.Permission(ctx, call.ChatID, call.From)
+	if err != nil {
+		return nil, err
+	}
+
+	if perm < cmd.Permission {
+		return nil, Errorf("/%s is available to chat admins only", cmd.Name)
+	}
+
+	return cmd.Handler(ctx, b, call)
+}
+
+// Parse recognises "/name args..." messages. Arguments are split on spaces,
+// double quotes group words. A leading "//" escapes the slash and is not a command.
+func Parse(chatID int64, from, text string) (*Call, bool) {
+	if !strings.HasPrefix(text, Prefix) || strings.HasPrefix(text, Prefix+Prefix) {
+		return nil, false
+	}
+
+	body := strings.TrimPrefix(text, Prefix)
+	name, raw, _ := strings.Cut(body, " ")
+	if name == "" {
+		return nil, false
+	}
+
+	return &Call{
+		ChatID: chatID,
+		From:   from,
+		Name:   strings.ToLower(name),
+		Args:   splitArgs(raw),
+		Raw:    strings.TrimSpace(raw),
+	}, true
+}
+
+func splitArgs(s string) []string {
+	var args []string
+	var cur strings.Builder
+	inQuotes, hasArg := false, false
+
+	for _, r := range s {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			hasArg = true
+		case r == ' ' && !inQuotes:
+			if hasArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				hasArg = false
+			}
+		default:
+			cur.WriteRune(r)
+			hasArg = true
+		}
+	}
+	if hasArg {
+		args = append(args, cur.String())
+	}
+
+	return args
+}
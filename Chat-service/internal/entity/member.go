@@ -0,0 +1,28 @@
+package entity
+
+type ChatRole int
+
+const (
+	MemberChatRole ChatRole = iota
+	AdminChatRole
+)
+
+func (r ChatRole) StringRole() string {
+	switch r {
+	case MemberChatRole:
+		return "member"
+	case AdminChatRole:
+		return "admin"
+	default:
+		return "unknown role"
+	}
+}
+
+func ParseChatRole(roleStr string) ChatRole {
+	switch roleStr {
+	case "admin":
+		return AdminChatRole
+	default:
+		return MemberChatRole
+	}
+}
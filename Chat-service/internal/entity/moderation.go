@@ -0,0 +1,59 @@
+package entity
+
+import "time"
+
+type ModerationAction int
+
+const (
+	FlagModeration ModerationAction = iota
+	MaskModeration
+	BlockModeration
+	OffModeration
+)
+
+func (a ModerationAction) StringAction() string {
+	switch a {
+	case FlagModeration:
+		return "flag"
+	case MaskModeration:
+		return "mask"
+	case BlockModeration:
+		return "block"
+	case OffModeration:
+		return "off"
+	default:
+		return "unknown action"
+	}
+}
+
+func ParseModerationAction(actionStr string) ModerationAction {
+	switch actionStr {
+	case "mask":
+		return MaskModeration
+	case "block":
+		return BlockModeration
+	case "off":
+		return OffModeration
+	default:
+		return FlagModeration
+	}
+}
+
+// ModerationSettings are the per-chat moderation rules. They extend the global
+// rules of the service, Action decides what happens to a matching message.
+type ModerationSettings struct {
+	ChatID         int64
+	Action         ModerationAction
+	Words          []string
+	Patterns       []string
+	BlockedDomains []string
+}
+
+type Report struct {
+	ID        int64
+	MessageID int64
+	ChatID    int64
+	Reporter  string
+	Reason    string
+	CreatedAt time.Time
+}
@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+// OutboxEntry is a message waiting to be published to the broker. It is written
+// in the same transaction as the message itself.
+type OutboxEntry struct {
+	ID        int64
+	ChatID    int64
+	Payload   []byte
+	CreatedAt time.Time
+}
@@ -0,0 +1,26 @@
+package entity
+
+import "time"
+
+type Poll struct {
+	ID          int64
+	ChatID      int64
+	Creator     string
+	Question    string
+	Options     []string
+	MultiChoice bool
+	Anonymous   bool
+	ClosesAt    *time.Time
+	Closed      bool
+	Votes       []PollVote
+	CreatedAt   time.Time
+}
+
+type PollVote struct {
+	Username    string
+	OptionIndex int
+}
+
+func (p *Poll) IsClosed(now time.Time) bool {
+	return p.Closed || (p.ClosesAt != nil && !now.Before(*p.ClosesAt))
+}
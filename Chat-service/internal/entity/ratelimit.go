@@ -0,0 +1,62 @@
+package entity
+
+import "fmt"
+
+// RateLimits bound how many messages can be sent to a chat: by each user and by
+// the whole chat. A zero rate disables that limit.
+type RateLimits struct {
+	ChatID        int64
+	UserPerMinute int
+	UserBurst     int
+	ChatPerMinute int
+	ChatBurst     int
+}
+
+// UserRateLimit bounds how many messages a user can send to all chats together,
+// so a user in many chats cannot multiply the per-chat limit. A zero rate
+// disables it.
+type UserRateLimit struct {
+	PerMinute int
+	Burst     int
+}
+
+// Buckets returns the bucket a message from username has to take a token from.
+func (l UserRateLimit) Buckets(username string) []TokenBucket {
+	if l.PerMinute <= 0 {
+		return nil
+	}
+
+	return []TokenBucket{{
+		Key:   fmt.Sprintf("user:%s", username),
+		Rate:  float64(l.PerMinute) / 60,
+		Burst: float64(max(l.Burst, 1)),
+	}}
+}
+
+// TokenBucket is a bucket of Burst tokens refilled at Rate tokens per second.
+type TokenBucket struct {
+	Key   string
+	Rate  float64
+	Burst float64
+}
+
+// Buckets returns the buckets a message from username has to take a token from.
+func (l *RateLimits) Buckets(username string) []TokenBucket {
+	var buckets []TokenBucket
+	if l.UserPerMinute > 0 {
+		buckets = append(buckets, TokenBucket{
+			Key:   fmt.Sprintf("chat:%d:user:%s", l.ChatID, username),
+			Rate:  float64(l.UserPerMinute) / 60,
+			Burst: float64(max(l.UserBurst, 1)),
+		})
+	}
+	if l.ChatPerMinute > 0 {
+		buckets = append(buckets, TokenBucket{
+			Key:   fmt.Sprintf("chat:%d", l.ChatID),
+			Rate:  float64(l.ChatPerMinute) / 60,
+			Burst: float64(max(l.ChatBurst, 1)),
+		})
+	}
+
+	return buckets
+}
@@ -0,0 +1,64 @@
+package entity
+
+import "time"
+
+type Webhook struct {
+	ID           int64
+	ChatID       int64
+	Creator      string
+	URL          string
+	Secret       string
+	EventTypes   []string
+	Active       bool
+	FailureCount int
+	CreatedAt    time.Time
+}
+
+type WebhookDelivery struct {
+	WebhookID  int64
+	MessageID  int64
+	EventType  string
+	Attempt    int
+	StatusCode int
+	Error      string
+	Success    bool
+}
+
+// WebhookJob is an event waiting to be delivered to a webhook. Jobs are stored,
+// so deliveries that are still being retried survive a restart.
+type WebhookJob struct {
+	ID        int64
+	WebhookID int64
+	EventID   string
+	EventType string
+	MessageID int64
+	Body      []byte
+	// Attempts counts the deliveries tried so far, including the current one.
+	Attempts int
+}
+
+// Accepts reports whether the webhook is subscribed to the event type.
+// A webhook without event types receives every event of the chat.
+func (w *Webhook) Accepts(eventType string) bool {
+	if len(w.EventTypes) == 0 {
+		return true
+	}
+
+	for _, t := range w.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IncomingWebhook lets an external system post into a chat with a secret token.
+// Its messages are authored by the admin who created it.
+type IncomingWebhook struct {
+	ID        int64
+	ChatID    int64
+	Creator   string
+	Name      string
+	CreatedAt time.Time
+}
@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"chat-grpc/Auth-service/interceptor"
+	"chat-grpc/Chat-service/internal/usecase"
+	"chat-grpc/pkg/broker"
+	"chat-grpc/proto_gen"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// botRole is the role in the tokens of bot accounts.
+const botRole = "bot"
+
+func (cs *ChatService) AddBot(ctx context.Context, req *proto_gen.AddBotRequest) (*proto_gen.ChatEmpty, error) {
+	from, err := cs.caller(ctx, req.From)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cs.useCase.AddBot(req.ChatId, from, req.BotName)
+	if err != nil {
+		cs.log.Error("failed to add bot", zap.Error(err))
+		return nil, errors.New("failed to add bot")
+	}
+
+	return &proto_gen.ChatEmpty{}, nil
+}
+
+// BotConnect streams every event of the bot's chats to the bot and posts its replies.
+// The stream must be opened with a bot token and the first request must be a
+// hello, the bot name in it must be the name of the token's account. A chat the
+// bot is added to while connected is joined on its "member_added" or
+// "bot_added" event.
+func (cs *ChatService) BotConnect(stream proto_gen.ChatService_BotConnectServer) error {
+	ctx := stream.Context()
+
+	claims, ok := interceptor.ClaimsFromContext(ctx)
+	if !ok || claims.Role != botRole {
+		return status.Error(codes.PermissionDenied, "not a bot account")
+	}
+
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	hello := first.GetHello()
+	if hello == nil {
+		return status.Error(codes.InvalidArgument, "first bot request must be a hello")
+	}
+
+	botName, err := cs.caller(ctx, hello.BotName)
+	if err != nil {
+		return err
+	}
+
+	chatIDs, err := cs.useCase.GetBotChats(ctx, botName)
+	if err != nil {
+		cs.log.Warn("bot connect rejected", zap.String("bot", botName), zap.Error(err))
+		return status.Error(codes.PermissionDenied, "not a bot account")
+	}
+
+	queue := newStreamQueue(cs.streamQueue, cs.overflowPolicy)
+	defer queue.close()
+
+	chats := newBotChats(cs.useCase, botName, queue)
+	defer chats.close()
+
+	watch, err := cs.useCase.Subscribe("chat.*", chats.watch)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to NATS: %w", err)
+	}
+	defer watch.Unsubscribe()
+
+	for _, chatID := range chatIDs {
+		if _, err := chats.join(chatID); err != nil {
+			return fmt.Errorf("failed to subscribe to NATS: %w", err)
+		}
+	}
+
+	cs.log.Info("Bot connected", zap.String("bot", botName), zap.Int("chats", len(chatIDs)))
+
+	// replies are read in the background, so that a bot that stops reading its
+	// events is still disconnected by the queue
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	replies := make(chan error, 1)
+	go func() {
+		replies <- cs.botReplies(stream, botName)
+		cancel()
+	}()
+
+	if err := queue.run(ctx, stream.Send); err != nil {
+		cs.log.Warn("failed to send message to bot", zap.String("bot", botName), zap.Error(err))
+		return err
+	}
+
+	return <-replies
+}
+
+// botReplies posts the replies of the bot until it closes the stream. The bot
+// must still be a member of the chat it replies in.
+func (cs *ChatService) botReplies(stream proto_gen.ChatService_BotConnectServer, botName string) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		reply := req.GetReply()
+		if reply == nil {
+			continue
+		}
+
+		member, err := cs.useCase.IsChatMember(stream.Context(), reply.ChatId, botName)
+		if err != nil {
+			cs.log.Error("failed to check bot membership", zap.String("bot", botName), zap.Error(err))
+			return errors.New("failed to send message")
+		}
+		if !member {
+			return status.Errorf(codes.PermissionDenied, "bot is not a member of chat %d", reply.ChatId)
+		}
+
+		msg := &proto_gen.Message{
+			ChatId:    reply.ChatId,
+			From:      botName,
+			Text:      reply.Text,
+			Timestamp: timestamppb.Now(),
+			Metadata:  reply.Metadata,
+		}
+		delete(msg.Metadata, usecase.IntegrationMetadataKey)
+		err = cs.useCase.SendMessage(msg)
+		var rateErr *usecase.RateLimitError
+		if errors.As(err, &rateErr) {
+			stream.SetTrailer(retryAfterMD(rateErr.RetryAfter))
+			return status.Error(codes.ResourceExhausted, rateErr.Error())
+		}
+		if err != nil {
+			cs.log.Error("failed to send bot reply", zap.String("bot", botName), zap.Error(err))
+			return errors.New("failed to send message")
+		}
+	}
+}
+
+// botChats are the subscriptions of a connected bot, one per chat it is in.
+type botChats struct {
+	useCase usecase.ChatUseCaseInterface
+	botName string
+	queue   *streamQueue
+
+	mu     sync.Mutex
+	subs   map[int64]broker.Subscription
+	closed bool
+}
+
+func newBotChats(useCase usecase.ChatUseCaseInterface, botName string, queue *streamQueue) *botChats {
+	return &botChats{useCase: useCase, botName: botName, queue: queue, subs: make(map[int64]broker.Subscription)}
+}
+
+// join subscribes to the chat and reports whether the bot was not in it yet.
+func (b *botChats) join(chatID int64) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed || b.subs[chatID] != nil {
+		return false, nil
+	}
+
+	sub, err := b.useCase.Subscribe(broker.Subject(chatID), b.push)
+	if err != nil {
+		return false, err
+	}
+	b.subs[chatID] = sub
+
+	return true, nil
+}
+
+// watch joins the chats the bot is added to. The event itself was published
+// before the subscription, so it is handed to the bot here.
+func (b *botChats) watch(msg *proto_gen.Message) {
+	event := msg.GetSystemEvent()
+	if event == nil {
+		return
+	}
+
+	switch {
+	case event.Type == "member_added" && event.Data["username"] == b.botName:
+	case event.Type == "bot_added" && event.Data["bot"] == b.botName:
+	default:
+		return
+	}
+
+	joined, err := b.join(msg.ChatId)
+	if err != nil || !joined {
+		return
+	}
+	b.push(msg)
+}
+
+func (b *botChats) push(msg *proto_gen.Message) {
+	if msg.Recipient != "" && msg.Recipient != b.botName {
+		return
+	}
+	if msg.From == b.botName && msg.Recipient == "" {
+		return
+	}
+
+	b.queue.push(msg)
+}
+
+func (b *botChats) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for _, sub := range b.subs {
+		sub.Unsubscribe()
+	}
+}
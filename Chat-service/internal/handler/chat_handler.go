@@ -4,20 +4,35 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"strconv"
+	"time"
 
+	"chat-grpc/Auth-service/interceptor"
+	"chat-grpc/Chat-service/internal/entity"
+	"chat-grpc/Chat-service/internal/repository"
 	"chat-grpc/Chat-service/internal/usecase"
 	"chat-grpc/proto_gen"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type ChatService struct {
 	useCase usecase.ChatUseCaseInterface
 	proto_gen.UnimplementedChatServiceServer
-	log *zap.Logger
+	log            *zap.Logger
+	streamQueue    int
+	overflowPolicy OverflowPolicy
 }
 
-func NewChatService(useCase usecase.ChatUseCaseInterface, log *zap.Logger) *ChatService {
-	return &ChatService{useCase: useCase, log: log}
+// NewChatService creates the service. Every Connect stream buffers up to
+// streamQueue messages for its client, overflowPolicy decides what happens next.
+func NewChatService(useCase usecase.ChatUseCaseInterface, log *zap.Logger, streamQueue int, overflowPolicy OverflowPolicy) *ChatService {
+	return &ChatService{useCase: useCase, log: log, streamQueue: streamQueue, overflowPolicy: overflowPolicy}
 }
 
 func (cs *ChatService) Create(ctx context.Context, req *proto_gen.CreateRequest) (*proto_gen.CreateResponse, error) {
@@ -40,16 +55,49 @@ func (cs *ChatService) Delete(ctx context.Context, req *proto_gen.DeleteRequest)
 	return &proto_gen.ChatEmpty{}, nil
 }
 
-func (cs *ChatService) SendMessage(ctx context.Context, req *proto_gen.SendMessageRequest) (*proto_gen.ChatEmpty, error) {
-	err := cs.useCase.SendMessage(req.ChatId, req.From, req.Text, req.Timestamp.AsTime())
+func (cs *ChatService) SendMessage(ctx context.Context, req *proto_gen.SendMessageRequest) (*proto_gen.SendMessageResponse, error) {
+	from, err := cs.caller(ctx, req.From)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &proto_gen.Message{
+		ChatId:          req.ChatId,
+		From:            from,
+		Text:            req.Text,
+		Timestamp:       timestamppb.New(req.Timestamp.AsTime()),
+		Metadata:        req.Metadata,
+		ClientMessageId: req.ClientMessageId,
+	}
+
+	switch payload := req.Payload.(type) {
+	case *proto_gen.SendMessageRequest_Attachment:
+		msg.Payload = &proto_gen.Message_Attachment{Attachment: payload.Attachment}
+	case *proto_gen.SendMessageRequest_CodeSnippet:
+		msg.Payload = &proto_gen.Message_CodeSnippet{CodeSnippet: payload.CodeSnippet}
+	}
+	delete(msg.Metadata, usecase.IntegrationMetadataKey)
+
+	err = cs.useCase.SendMessage(msg)
+	var rateErr *usecase.RateLimitError
+	if errors.As(err, &rateErr) {
+		grpc.SetHeader(ctx, retryAfterMD(rateErr.RetryAfter))
+		return nil, status.Error(codes.ResourceExhausted, rateErr.Error())
+	}
+	if errors.Is(err, usecase.ErrMessageBlocked) || errors.Is(err, repository.ErrUserBanned) {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	if errors.Is(err, usecase.ErrModerationUnavailable) {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
 	if err != nil {
 		cs.log.Error("failed to send message", zap.Error(err))
 		return nil, errors.New("failed to send message")
 	}
 
-	cs.log.Info("Message successfully sent", zap.Int64("chat_id", req.ChatId), zap.String("from", req.From))
+	cs.log.Info("Message successfully sent", zap.Int64("chat_id", req.ChatId), zap.String("from", from))
 
-	return &proto_gen.ChatEmpty{}, nil
+	return &proto_gen.SendMessageResponse{MessageId: msg.Id}, nil
 }
 
 func (cs *ChatService) GetMessages(ctx context.Context, req *proto_gen.GetMessagesRequest) (*proto_gen.GetMessagesResponse, error) {
@@ -66,6 +114,11 @@ func (cs *ChatService) Connect(req *proto_gen.ConnectRequest, stream proto_gen.C
 	ctx := stream.Context()
 	chatID := req.ChatId
 
+	from, err := cs.caller(ctx, req.From)
+	if err != nil {
+		return err
+	}
+
 	messages, err := cs.useCase.GetChatHistory(ctx, chatID)
 	if err != nil {
 		return fmt.Errorf("error loading chat history: %w", err)
@@ -77,17 +130,119 @@ func (cs *ChatService) Connect(req *proto_gen.ConnectRequest, stream proto_gen.C
 		}
 	}
 
+	queue := newStreamQueue(cs.streamQueue, cs.overflowPolicy)
+	defer queue.close()
+
 	subject := fmt.Sprintf("chat.%d", chatID)
 	sub, err := cs.useCase.Subscribe(subject, func(msg *proto_gen.Message) {
-		if err := stream.Send(msg); err != nil {
-			cs.log.Warn("failed to send message stream", zap.Error(err))
+		if msg.Recipient != "" && msg.Recipient != from {
+			return
 		}
+
+		queue.push(msg)
 	})
 	if err != nil {
 		return fmt.Errorf("failed to subscribe to NATS: %w", err)
 	}
 	defer sub.Unsubscribe()
 
-	<-ctx.Done()
-	return nil
+	err = queue.run(ctx, stream.Send)
+	if status.Code(err) == codes.Aborted {
+		cs.log.Warn("Stream overflowed, disconnecting", zap.Int64("chat_id", chatID), zap.String("from", from))
+	} else if err != nil {
+		cs.log.Warn("failed to send message stream", zap.Error(err))
+	}
+
+	return err
+}
+
+func (cs *ChatService) CreatePoll(ctx context.Context, req *proto_gen.CreatePollRequest) (*proto_gen.CreatePollResponse, error) {
+	from, err := cs.caller(ctx, req.From)
+	if err != nil {
+		return nil, err
+	}
+
+	poll := &entity.Poll{
+		ChatID:      req.ChatId,
+		Creator:     from,
+		Question:    req.Question,
+		Options:     req.Options,
+		MultiChoice: req.MultiChoice,
+		Anonymous:   req.Anonymous,
+	}
+	if req.ClosesAt != nil {
+		closesAt := req.ClosesAt.AsTime()
+		poll.ClosesAt = &closesAt
+	}
+
+	pollID, err := cs.useCase.CreatePoll(poll)
+	if err != nil {
+		cs.log.Error("failed to create poll", zap.Error(err))
+		return nil, errors.New("failed to create poll")
+	}
+
+	return &proto_gen.CreatePollResponse{Id: pollID}, nil
+}
+
+func (cs *ChatService) Vote(ctx context.Context, req *proto_gen.VoteRequest) (*proto_gen.ChatEmpty, error) {
+	from, err := cs.caller(ctx, req.From)
+	if err != nil {
+		return nil, err
+	}
+
+	options := make([]int, len(req.Options))
+	for i, option := range req.Options {
+		options[i] = int(option)
+	}
+
+	err = cs.useCase.Vote(req.PollId, from, options)
+	if err != nil {
+		cs.log.Error("failed to vote", zap.Error(err))
+		return nil, errors.New("failed to vote")
+	}
+
+	return &proto_gen.ChatEmpty{}, nil
+}
+
+func (cs *ChatService) ClosePoll(ctx context.Context, req *proto_gen.ClosePollRequest) (*proto_gen.ChatEmpty, error) {
+	from, err := cs.caller(ctx, req.From)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cs.useCase.ClosePoll(req.PollId, from)
+	if err != nil {
+		cs.log.Error("failed to close poll", zap.Error(err))
+		return nil, errors.New("failed to close poll")
+	}
+
+	return &proto_gen.ChatEmpty{}, nil
+}
+
+// retryAfterMD tells the client how long to wait before retrying, in whole seconds.
+func retryAfterMD(d time.Duration) metadata.MD {
+	return metadata.Pairs("retry-after", strconv.Itoa(int(math.Ceil(d.Seconds()))))
+}
+
+// caller returns the name of the user the token of the request was issued to.
+// The from field of a request is kept for older clients: if set, it must name
+// the same user.
+func (cs *ChatService) caller(ctx context.Context, from string) (string, error) {
+	claims, ok := interceptor.ClaimsFromContext(ctx)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "request is not authenticated")
+	}
+
+	username, err := cs.useCase.GetUsername(ctx, claims.UserID)
+	if err != nil {
+		cs.log.Error("failed to get caller name", zap.Int64("user_id", claims.UserID), zap.Error(err))
+		return "", status.Error(codes.Unauthenticated, "unknown user")
+	}
+
+	if from != "" && from != username {
+		cs.log.Warn("request made on behalf of another user", zap.String("caller", username), zap.String("from", from))
+		return "", status.Error(codes.PermissionDenied, "from does not match the authenticated user")
+	}
+
+	return username, nil
 }
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"chat-grpc/Chat-service/internal/usecase"
+	"chat-grpc/proto_gen"
+	"go.uber.org/zap"
+)
+
+const maxIncomingBodySize = 64 << 10
+
+func (cs *ChatService) CreateIncomingWebhook(ctx context.Context, req *proto_gen.CreateIncomingWebhookRequest) (*proto_gen.IncomingWebhookTokenResponse, error) {
+	from, err := cs.caller(ctx, req.From)
+	if err != nil {
+		return nil, err
+	}
+
+	id, token, err := cs.useCase.CreateIncomingWebhook(req.ChatId, from, req.Name)
+	if err != nil {
+		cs.log.Error("failed to create incoming webhook", zap.Error(err))
+		return nil, errors.New("failed to create incoming webhook")
+	}
+
+	return &proto_gen.IncomingWebhookTokenResponse{Id: id, Token: token}, nil
+}
+
+func (cs *ChatService) RotateIncomingWebhook(ctx context.Context, req *proto_gen.IncomingWebhookRequest) (*proto_gen.IncomingWebhookTokenResponse, error) {
+	from, err := cs.caller(ctx, req.From)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := cs.useCase.RotateIncomingWebhook(req.Id, from)
+	if err != nil {
+		cs.log.Error("failed to rotate incoming webhook", zap.Error(err))
+		return nil, errors.New("failed to rotate incoming webhook")
+	}
+
+	return &proto_gen.IncomingWebhookTokenResponse{Id: req.Id, Token: token}, nil
+}
+
+func (cs *ChatService) RevokeIncomingWebhook(ctx context.Context, req *proto_gen.IncomingWebhookRequest) (*proto_gen.ChatEmpty, error) {
+	from, err := cs.caller(ctx, req.From)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cs.useCase.RevokeIncomingWebhook(req.Id, from)
+	if err != nil {
+		cs.log.Error("failed to revoke incoming webhook", zap.Error(err))
+		return nil, errors.New("failed to revoke incoming webhook")
+	}
+
+	return &proto_gen.ChatEmpty{}, nil
+}
+
+// IncomingWebhookHandler serves POST /hooks/{token}. The body is
+// {"text": "...", "metadata": {...}} and is posted into the token's chat.
+type IncomingWebhookHandler struct {
+	useCase   usecase.ChatUseCaseInterface
+	log       *zap.Logger
+	perMinute int
+	burst     int
+}
+
+func NewIncomingWebhookHandler(useCase usecase.ChatUseCaseInterface, log *zap.Logger, perMinute, burst int) http.Handler {
+	h := &IncomingWebhookHandler{
+		useCase:   useCase,
+		log:       log,
+		perMinute: perMinute,
+		burst:     burst,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /hooks/{token}", h.post)
+
+	return mux
+}
+
+type incomingWebhookBody struct {
+	Text     string            `json:"text"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+func (h *IncomingWebhookHandler) post(w http.ResponseWriter, r *http.Request) {
+	hook, err := h.useCase.AuthenticateIncomingWebhook(r.Context(), r.PathValue("token"))
+	if err != nil {
+		http.Error(w, "invalid webhook token", http.StatusUnauthorized)
+		return
+	}
+
+	err = h.useCase.ThrottleIncomingWebhook(r.Context(), hook.ID, h.perMinute, h.burst)
+	var rateErr *usecase.RateLimitError
+	if errors.As(err, &rateErr) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(rateErr.RetryAfter.Seconds()))))
+		http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+		return
+	}
+	if err != nil {
+		h.log.Error("failed to take incoming webhook token", zap.Int64("webhook_id", hook.ID), zap.Error(err))
+		http.Error(w, "failed to post message", http.StatusInternalServerError)
+		return
+	}
+
+	var body incomingWebhookBody
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxIncomingBodySize)).Decode(&body); err != nil {
+		http.Error(w, "invalid json body", http.StatusBadRequest)
+		return
+	}
+	if body.Text == "" {
+		http.Error(w, "text field cannot be empty", http.StatusBadRequest)
+		return
+	}
+
+	err = h.useCase.PostIncomingWebhook(hook, body.Text, body.Metadata)
+	if errors.Is(err, usecase.ErrIncomingWebhookForbidden) {
+		http.Error(w, "webhook creator is no longer a chat admin", http.StatusForbidden)
+		return
+	}
+	if errors.As(err, &rateErr) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(rateErr.RetryAfter.Seconds()))))
+		http.Error(w, "chat rate limit exceeded", http.StatusTooManyRequests)
+		return
+	}
+	if err != nil {
+		h.log.Error("failed to post incoming webhook message", zap.Int64("webhook_id", hook.ID), zap.Error(err))
+		http.Error(w, "failed to post message", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"chat-grpc/Chat-service/internal/entity"
+	"chat-grpc/proto_gen"
+	"go.uber.org/zap"
+)
+
+var moderationActions = map[proto_gen.ModerationAction]entity.ModerationAction{
+	proto_gen.ModerationAction_FlagModeration:  entity.FlagModeration,
+	proto_gen.ModerationAction_MaskModeration:  entity.MaskModeration,
+	proto_gen.ModerationAction_BlockModeration: entity.BlockModeration,
+	proto_gen.ModerationAction_OffModeration:   entity.OffModeration,
+}
+
+func (cs *ChatService) ReportMessage(ctx context.Context, req *proto_gen.ReportMessageRequest) (*proto_gen.ChatEmpty, error) {
+	from, err := cs.caller(ctx, req.From)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cs.useCase.ReportMessage(req.MessageId, from, req.Reason)
+	if err != nil {
+		cs.log.Error("failed to report message", zap.Error(err))
+		return nil, errors.New("failed to report message")
+	}
+
+	return &proto_gen.ChatEmpty{}, nil
+}
+
+func (cs *ChatService) SetModerationSettings(ctx context.Context, req *proto_gen.ModerationSettingsRequest) (*proto_gen.ChatEmpty, error) {
+	from, err := cs.caller(ctx, req.From)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := &entity.ModerationSettings{
+		ChatID:         req.ChatId,
+		Action:         moderationActions[req.Action],
+		Words:          req.Words,
+		Patterns:       req.Patterns,
+		BlockedDomains: req.BlockedDomains,
+	}
+
+	err = cs.useCase.SetModerationSettings(settings, from)
+	if err != nil {
+		cs.log.Error("failed to set moderation settings", zap.Error(err))
+		return nil, errors.New("failed to set moderation settings")
+	}
+
+	return &proto_gen.ChatEmpty{}, nil
+}
+
+func (cs *ChatService) GetFlaggedMessages(ctx context.Context, req *proto_gen.GetFlaggedMessagesRequest) (*proto_gen.GetFlaggedMessagesResponse, error) {
+	from, err := cs.caller(ctx, req.From)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := cs.useCase.GetFlaggedMessages(req.ChatId, from)
+	if err != nil {
+		cs.log.Error("failed to get flagged messages", zap.Error(err))
+		return nil, errors.New("failed to get flagged messages")
+	}
+
+	return &proto_gen.GetFlaggedMessagesResponse{Messages: messages}, nil
+}
+
+func (cs *ChatService) ReviewMessage(ctx context.Context, req *proto_gen.ReviewMessageRequest) (*proto_gen.ChatEmpty, error) {
+	from, err := cs.caller(ctx, req.From)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cs.useCase.ReviewMessage(req.MessageId, from, req.Remove)
+	if err != nil {
+		cs.log.Error("failed to review message", zap.Error(err))
+		return nil, errors.New("failed to review message")
+	}
+
+	return &proto_gen.ChatEmpty{}, nil
+}
+
+func (cs *ChatService) BanUser(ctx context.Context, req *proto_gen.BanUserRequest) (*proto_gen.ChatEmpty, error) {
+	from, err := cs.caller(ctx, req.From)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cs.useCase.BanUser(req.ChatId, from, req.Username, req.Reason)
+	if err != nil {
+		cs.log.Error("failed to ban user", zap.Error(err))
+		return nil, errors.New("failed to ban user")
+	}
+
+	return &proto_gen.ChatEmpty{}, nil
+}
+
+func (cs *ChatService) UnbanUser(ctx context.Context, req *proto_gen.BanUserRequest) (*proto_gen.ChatEmpty, error) {
+	from, err := cs.caller(ctx, req.From)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cs.useCase.UnbanUser(req.ChatId, from, req.Username)
+	if err != nil {
+		cs.log.Error("failed to unban user", zap.Error(err))
+		return nil, errors.New("failed to unban user")
+	}
+
+	return &proto_gen.ChatEmpty{}, nil
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"chat-grpc/Chat-service/internal/entity"
+	"chat-grpc/proto_gen"
+	"go.uber.org/zap"
+)
+
+func (cs *ChatService) SetRateLimits(ctx context.Context, req *proto_gen.SetRateLimitsRequest) (*proto_gen.ChatEmpty, error) {
+	from, err := cs.caller(ctx, req.From)
+	if err != nil {
+		return nil, err
+	}
+
+	limits := &entity.RateLimits{
+		ChatID:        req.ChatId,
+		UserPerMinute: int(req.GetLimits().GetUserPerMinute()),
+		UserBurst:     int(req.GetLimits().GetUserBurst()),
+		ChatPerMinute: int(req.GetLimits().GetChatPerMinute()),
+		ChatBurst:     int(req.GetLimits().GetChatBurst()),
+	}
+
+	err = cs.useCase.SetRateLimits(limits, from)
+	if err != nil {
+		cs.log.Error("failed to set rate limits", zap.Error(err))
+		return nil, errors.New("failed to set rate limits")
+	}
+
+	return &proto_gen.ChatEmpty{}, nil
+}
+
+func (cs *ChatService) GetRateLimits(ctx context.Context, req *proto_gen.GetRateLimitsRequest) (*proto_gen.RateLimits, error) {
+	from, err := cs.caller(ctx, req.From)
+	if err != nil {
+		return nil, err
+	}
+
+	limits, err := cs.useCase.GetRateLimits(req.ChatId, from)
+	if err != nil {
+		cs.log.Error("failed to get rate limits", zap.Error(err))
+		return nil, errors.New("failed to get rate limits")
+	}
+
+	return &proto_gen.RateLimits{
+		UserPerMinute: int32(limits.UserPerMinute),
+		UserBurst:     int32(limits.UserBurst),
+		ChatPerMinute: int32(limits.ChatPerMinute),
+		ChatBurst:     int32(limits.ChatBurst),
+	}, nil
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"context"
+	"expvar"
+	"sync"
+
+	"chat-grpc/proto_gen"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// OverflowPolicy decides what happens when a client reads its stream slower
+// than the chat writes to it and the queue of the stream is full.
+type OverflowPolicy string
+
+const (
+	// DropOldestPolicy discards the oldest queued message to make room.
+	DropOldestPolicy OverflowPolicy = "drop_oldest"
+	// DisconnectPolicy closes the stream, the client has to reload the history.
+	DisconnectPolicy OverflowPolicy = "disconnect"
+)
+
+func ParseOverflowPolicy(policyStr string) OverflowPolicy {
+	if OverflowPolicy(policyStr) == DisconnectPolicy {
+		return DisconnectPolicy
+	}
+
+	return DropOldestPolicy
+}
+
+var (
+	streamQueueDepth  = expvar.NewInt("stream_queue_depth")
+	streamsActive     = expvar.NewInt("streams_active")
+	streamDropped     = expvar.NewInt("stream_dropped_messages")
+	streamDisconnects = expvar.NewInt("stream_overflow_disconnects")
+)
+
+// streamQueue sits between the broker subscription and a client stream. The
+// subscription only pushes, a sender goroutine of the stream pops, so a slow
+// client never holds up the delivery to anyone else.
+type streamQueue struct {
+	messages chan *proto_gen.Message
+	policy   OverflowPolicy
+	overflow chan struct{}
+	once     sync.Once
+}
+
+func newStreamQueue(size int, policy OverflowPolicy) *streamQueue {
+	streamsActive.Add(1)
+
+	return &streamQueue{
+		messages: make(chan *proto_gen.Message, max(size, 1)),
+		policy:   policy,
+		overflow: make(chan struct{}),
+	}
+}
+
+// push never blocks, subscriptions of several chats may push concurrently.
+func (q *streamQueue) push(msg *proto_gen.Message) {
+	select {
+	case <-q.overflow:
+		return
+	default:
+	}
+
+	for {
+		select {
+		case q.messages <- msg:
+			streamQueueDepth.Add(1)
+			return
+		default:
+		}
+
+		if q.policy == DisconnectPolicy {
+			q.once.Do(func() {
+				streamDisconnects.Add(1)
+				close(q.overflow)
+			})
+			return
+		}
+
+		select {
+		case <-q.messages:
+			streamQueueDepth.Add(-1)
+			streamDropped.Add(1)
+		default:
+		}
+	}
+}
+
+// run sends the queued messages until the client leaves, a send fails or the
+// queue overflows under DisconnectPolicy.
+func (q *streamQueue) run(ctx context.Context, send func(*proto_gen.Message) error) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-q.overflow:
+			return status.Error(codes.Aborted, "resync required: the stream fell behind the chat")
+		case msg := <-q.messages:
+			streamQueueDepth.Add(-1)
+			if err := send(msg); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// close forgets the messages nobody is going to send. It must be called after
+// the subscription is stopped.
+func (q *streamQueue) close() {
+	streamsActive.Add(-1)
+	for {
+		select {
+		case <-q.messages:
+			streamQueueDepth.Add(-1)
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"chat-grpc/proto_gen"
+	"go.uber.org/zap"
+)
+
+func (cs *ChatService) RegisterWebhook(ctx context.Context, req *proto_gen.RegisterWebhookRequest) (*proto_gen.RegisterWebhookResponse, error) {
+	from, err := cs.caller(ctx, req.From)
+	if err != nil {
+		return nil, err
+	}
+
+	id, secret, err := cs.useCase.RegisterWebhook(req.ChatId, from, req.Url, req.EventTypes)
+	if err != nil {
+		cs.log.Error("failed to register webhook", zap.Error(err))
+		return nil, errors.New("failed to register webhook")
+	}
+
+	return &proto_gen.RegisterWebhookResponse{Id: id, Secret: secret}, nil
+}
+
+func (cs *ChatService) DeleteWebhook(ctx context.Context, req *proto_gen.DeleteWebhookRequest) (*proto_gen.ChatEmpty, error) {
+	from, err := cs.caller(ctx, req.From)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cs.useCase.DeleteWebhook(req.Id, from)
+	if err != nil {
+		cs.log.Error("failed to delete webhook", zap.Error(err))
+		return nil, errors.New("failed to delete webhook")
+	}
+
+	return &proto_gen.ChatEmpty{}, nil
+}
+
+func (cs *ChatService) ListWebhooks(ctx context.Context, req *proto_gen.ListWebhooksRequest) (*proto_gen.ListWebhooksResponse, error) {
+	from, err := cs.caller(ctx, req.From)
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks, err := cs.useCase.ListWebhooks(req.ChatId, from)
+	if err != nil {
+		cs.log.Error("failed to list webhooks", zap.Error(err))
+		return nil, errors.New("failed to list webhooks")
+	}
+
+	resp := &proto_gen.ListWebhooksResponse{}
+	for _, w := range webhooks {
+		resp.Webhooks = append(resp.Webhooks, &proto_gen.Webhook{
+			Id:           w.ID,
+			ChatId:       w.ChatID,
+			Url:          w.URL,
+			EventTypes:   w.EventTypes,
+			Active:       w.Active,
+			FailureCount: int32(w.FailureCount),
+		})
+	}
+
+	return resp, nil
+}
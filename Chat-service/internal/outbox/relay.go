@@ -0,0 +1,119 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"chat-grpc/Chat-service/internal/entity"
+	"chat-grpc/Chat-service/internal/repository"
+	"chat-grpc/pkg/broker"
+	"chat-grpc/proto_gen"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	batchSize     = 100
+	pollInterval  = time.Second
+	pruneInterval = time.Hour
+	keepSent      = 24 * time.Hour
+)
+
+// Relay publishes outbox entries to the broker in the order they were committed
+// within each chat; entries of different chats may overtake each other.
+// Delivery is at least once: an entry published right before a crash is sent
+// again after the restart.
+type Relay struct {
+	repo     repository.OutboxRepo
+	broker   broker.Broker
+	listener *pq.Listener
+	log      *zap.Logger
+}
+
+// NewRelay listens for outbox notifications on connStr; without them the relay
+// still polls the outbox every second.
+func NewRelay(repo repository.OutboxRepo, broker broker.Broker, connStr string, log *zap.Logger) *Relay {
+	listener := pq.NewListener(connStr, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Warn("Outbox listener event", zap.Int("event", int(ev)), zap.Error(err))
+		}
+	})
+	if err := listener.Listen(repository.OutboxChannel); err != nil {
+		log.Warn("Failed to listen for outbox notifications", zap.Error(err))
+	}
+
+	return &Relay{repo: repo, broker: broker, listener: listener, log: log}
+}
+
+func (r *Relay) Run(ctx context.Context) {
+	defer r.listener.Close()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	lastPrune := time.Now()
+
+	for {
+		r.drain(ctx)
+
+		if time.Since(lastPrune) > pruneInterval {
+			if _, err := r.repo.PruneOutbox(ctx, keepSent); err != nil {
+				r.log.Warn("Failed to prune outbox", zap.Error(err))
+			}
+			lastPrune = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.listener.Notify:
+		case <-ticker.C:
+		}
+	}
+}
+
+// drain relays full batches until the outbox is empty or publishing fails.
+func (r *Relay) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		n, err := r.repo.RelayOutbox(ctx, batchSize, r.publish)
+		if err != nil {
+			r.log.Error("Failed to relay outbox", zap.Int("sent", n), zap.Error(err))
+			return
+		}
+		if n < batchSize {
+			return
+		}
+	}
+}
+
+// publish stops at the first failure so that later entries never overtake it,
+// and reports nothing as published unless the broker confirmed the batch.
+func (r *Relay) publish(entries []*entity.OutboxEntry) (int, error) {
+	n := 0
+	var publishErr error
+	for _, e := range entries {
+		var msg proto_gen.Message
+		if err := proto.Unmarshal(e.Payload, &msg); err != nil {
+			// a corrupt entry would block the outbox forever, skip it
+			r.log.Error("Dropping undecodable outbox entry", zap.Int64("outbox_id", e.ID), zap.Error(err))
+			n++
+			continue
+		}
+
+		if err := r.broker.Publish(&msg); err != nil {
+			publishErr = err
+			break
+		}
+		n++
+	}
+
+	if n == 0 {
+		return 0, publishErr
+	}
+
+	if err := r.broker.Flush(); err != nil {
+		return 0, err
+	}
+
+	return n, publishErr
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"go.uber.org/zap"
+)
+
+type BotRepo interface {
+	IsBot(ctx context.Context, username string) (bool, error)
+	GetUserChats(ctx context.Context, username string) ([]int64, error)
+}
+
+func (r *chatRepository) IsBot(ctx context.Context, username string) (bool, error) {
+	var role string
+	err := r.dbUsers.QueryRowContext(ctx, "SELECT role FROM users WHERE name = $1", username).Scan(&role)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		r.log.Error("Failed to get user role", zap.String("username", username), zap.Error(err))
+		return false, err
+	}
+
+	return role == "bot", nil
+}
+
+func (r *chatRepository) GetUserChats(ctx context.Context, username string) ([]int64, error) {
+	userID, err := r.getUserID(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT chat_id FROM chat_users WHERE user_id = $1`, userID)
+	if err != nil {
+		r.log.Error("Failed to get user chats", zap.String("username", username), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var chatIDs []int64
+	for rows.Next() {
+		var chatID int64
+		if err := rows.Scan(&chatID); err != nil {
+			r.log.Error("Failed to scan chat id", zap.Error(err))
+			return nil, err
+		}
+		chatIDs = append(chatIDs, chatID)
+	}
+
+	return chatIDs, rows.Err()
+}
@@ -4,11 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"strings"
 	"time"
 
+	"chat-grpc/Chat-service/internal/entity"
 	"chat-grpc/proto_gen"
-	"github.com/otiai10/opengraph/v2"
 	"go.uber.org/zap"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
@@ -16,8 +15,19 @@ import (
 type ChatRepo interface {
 	CreateChat(usernames []string) (int64, error)
 	DeleteChat(id int64) error
-	SendMessage(chatID int64, from, text string, timestamp time.Time) (string, error)
+	SendMessage(msg *proto_gen.Message) (int64, bool, error)
+	GetMessageIDByClientID(ctx context.Context, chatID int64, username, clientID string) (int64, error)
+	SetLinkPreview(ctx context.Context, msg *proto_gen.Message) error
 	GetMessagesByChatID(ctx context.Context, chatID int64) ([]*proto_gen.Message, error)
+	PollRepo
+	MemberRepo
+	BotRepo
+	WebhookRepo
+	IncomingWebhookRepo
+	ModerationRepo
+	RateLimitRepo
+	OutboxRepo
+	CommandRepo
 }
 
 type chatRepository struct {
@@ -41,7 +51,13 @@ func (r *chatRepository) CreateChat(usernames []string) (int64, error) {
 		return 0, err
 	}
 
-	for _, username := range usernames {
+	// the first user in the list creates the chat and becomes its admin
+	for i, username := range usernames {
+		role := entity.MemberChatRole
+		if i == 0 {
+			role = entity.AdminChatRole
+		}
+
 		var userID int64
 		err := r.dbUsers.QueryRow("SELECT id FROM users WHERE name = $1", username).Scan(&userID)
 		if err != nil {
@@ -49,7 +65,7 @@ func (r *chatRepository) CreateChat(usernames []string) (int64, error) {
 			return 0, fmt.Errorf("user %s not found: %w", username, err)
 		}
 
-		_, err = r.db.Exec("INSERT INTO chat_users (chat_id, user_id) VALUES ($1, $2)", chatID, userID)
+		_, err = r.db.Exec("INSERT INTO chat_users (chat_id, user_id, role) VALUES ($1, $2, $3)", chatID, userID, role.StringRole())
 		if err != nil {
 			r.log.Error("Failed to add user to chat", zap.Int64("chat_id", chatID), zap.Int64("user_id", userID), zap.Error(err))
 			return 0, err
@@ -74,41 +90,144 @@ func (r *chatRepository) DeleteChat(id int64) error {
 	return nil
 }
 
-func (r *chatRepository) SendMessage(chatID int64, username, text string, timestamp time.Time) (string, error) {
-	r.log.Info("Sending message", zap.Int64("chat_id", chatID), zap.String("username", username))
-	// text = https://github.com/ split : [0]
-	checkUrl := strings.Split(text, "://")
-	if checkUrl[0] == "https" {
-		ogp, err := opengraph.Fetch(text)
-		if err != nil {
-			r.log.Error("Failed to fetch URL", zap.String("url", text), zap.Error(err))
-			return "", err
-		}
+// SendMessage stores the message and returns its id. A message with the same
+// client id from the same sender is stored once: a repeated send returns the
+// original id and false.
+func (r *chatRepository) SendMessage(msg *proto_gen.Message) (int64, bool, error) {
+	r.log.Info("Sending message", zap.Int64("chat_id", msg.ChatId), zap.String("username", msg.From))
 
-		text += fmt.Sprintf("\n\nTitle: %s\n\nDescription: %s\n\nImage: %v\n\nURL: [%s]",
-			ogp.Title, ogp.Description, ogp.Image, ogp.URL)
+	payload, err := encodePayload(msg)
+	if err != nil {
+		r.log.Error("Failed to encode message payload", zap.Error(err))
+		return 0, false, err
+	}
+
+	metadata, err := encodeMetadata(msg.Metadata)
+	if err != nil {
+		r.log.Error("Failed to encode message metadata", zap.Error(err))
+		return 0, false, err
 	}
 
 	var userID int64
-	err := r.dbUsers.QueryRow("SELECT id FROM users WHERE name = $1", username).Scan(&userID)
+	err = r.dbUsers.QueryRow("SELECT id FROM users WHERE name = $1", msg.From).Scan(&userID)
 	if err != nil {
-		r.log.Error("User not found", zap.String("username", username), zap.Error(err))
-		return "", err
+		r.log.Error("User not found", zap.String("username", msg.From), zap.Error(err))
+		return 0, false, err
 	}
 
-	query := `INSERT INTO messages (chat_id, user_id, text, timestamp) VALUES ($1, $2, $3, $4)`
-	_, err = r.db.Exec(query, chatID, userID, text, timestamp)
+	var clientID sql.NullString
+	if msg.ClientMessageId != "" {
+		clientID = sql.NullString{String: msg.ClientMessageId, Valid: true}
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.log.Error("Failed to begin transaction", zap.Error(err))
+		return 0, false, err
+	}
+	defer tx.Rollback()
+
+	if err := r.lockChatOutbox(tx, msg.ChatId); err != nil {
+		return 0, false, err
+	}
+
+	var messageID int64
+	query := `INSERT INTO messages (chat_id, user_id, text, timestamp, kind, payload, metadata, client_id)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			  ON CONFLICT (chat_id, user_id, client_id) DO NOTHING
+			  RETURNING id`
+	err = tx.QueryRow(query, msg.ChatId, userID, msg.Text, msg.Timestamp.AsTime(),
+		kindToString(msg.Kind), payload, metadata, clientID).Scan(&messageID)
+	if err == sql.ErrNoRows {
+		query = `SELECT id FROM messages WHERE chat_id = $1 AND user_id = $2 AND client_id = $3`
+		if err := tx.QueryRow(query, msg.ChatId, userID, clientID).Scan(&messageID); err != nil {
+			r.log.Error("Failed to get duplicate message", zap.Error(err))
+			return 0, false, err
+		}
+
+		r.log.Info("Duplicate message ignored", zap.Int64("message_id", messageID), zap.String("client_id", msg.ClientMessageId))
+		return messageID, false, nil
+	}
 	if err != nil {
 		r.log.Error("Failed to send message", zap.Error(err))
-		return "", err
+		return 0, false, err
+	}
+
+	if err := r.enqueueOutbox(tx, msg, messageID); err != nil {
+		return 0, false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log.Error("Failed to commit message", zap.Error(err))
+		return 0, false, err
 	}
 
-	r.log.Info("Message sent successfully", zap.Int64("chat_id", chatID), zap.String("username", username))
-	return text, nil
+	r.log.Info("Message sent successfully", zap.Int64("chat_id", msg.ChatId), zap.String("username", msg.From))
+	return messageID, true, nil
+}
+
+// SetLinkPreview stores the link preview of a message that has no payload yet
+// and publishes the updated message through the outbox.
+func (r *chatRepository) SetLinkPreview(ctx context.Context, msg *proto_gen.Message) error {
+	payload, err := encodePayload(msg)
+	if err != nil {
+		r.log.Error("Failed to encode message payload", zap.Error(err))
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.log.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := r.lockChatOutbox(tx, msg.ChatId); err != nil {
+		return err
+	}
+
+	query := `UPDATE messages SET payload = $1, kind = $2 WHERE id = $3 AND payload IS NULL`
+	res, err := tx.ExecContext(ctx, query, payload, kindToString(msg.Kind), msg.Id)
+	if err != nil {
+		r.log.Error("Failed to save link preview", zap.Int64("message_id", msg.Id), zap.Error(err))
+		return err
+	}
+	// the message was removed in the meantime
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil
+	}
+
+	if err := r.enqueueOutbox(tx, msg, msg.Id); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log.Error("Failed to commit link preview", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// GetMessageIDByClientID returns sql.ErrNoRows if the client id was not used yet.
+func (r *chatRepository) GetMessageIDByClientID(ctx context.Context, chatID int64, username, clientID string) (int64, error) {
+	userID, err := r.getUserID(ctx, username)
+	if err != nil {
+		return 0, err
+	}
+
+	var messageID int64
+	query := `SELECT id FROM messages WHERE chat_id = $1 AND user_id = $2 AND client_id = $3`
+	err = r.db.QueryRowContext(ctx, query, chatID, userID, clientID).Scan(&messageID)
+	if err != nil && err != sql.ErrNoRows {
+		r.log.Error("Failed to get message by client id", zap.Error(err))
+	}
+
+	return messageID, err
 }
 
 func (r *chatRepository) GetMessagesByChatID(ctx context.Context, chatID int64) ([]*proto_gen.Message, error) {
-	query := `SELECT u.name, m.text, m.timestamp 
+	query := `SELECT m.id, u.name, m.text, m.timestamp, m.kind, m.payload, m.metadata, m.poll_id, m.client_id
 			  FROM messages m
 			  JOIN users u ON m.user_id = u.id
 			  WHERE m.chat_id = $1
@@ -121,22 +240,51 @@ func (r *chatRepository) GetMessagesByChatID(ctx context.Context, chatID int64)
 	}
 	defer rows.Close()
 
+	return r.scanMessages(rows, chatID)
+}
+
+// scanMessages reads rows of (id, name, text, timestamp, kind, payload, metadata, poll_id, client_id).
+func (r *chatRepository) scanMessages(rows *sql.Rows, chatID int64) ([]*proto_gen.Message, error) {
 	var messages []*proto_gen.Message
 	for rows.Next() {
-		var username, text string
+		var id int64
+		var username, text, kind string
 		var timestamp time.Time
+		var payload, metadata []byte
+		var pollID sql.NullInt64
+		var clientID sql.NullString
 
-		if err := rows.Scan(&username, &text, &timestamp); err != nil {
+		if err := rows.Scan(&id, &username, &text, &timestamp, &kind, &payload, &metadata, &pollID, &clientID); err != nil {
 			r.log.Error("Failed to scan message row", zap.Error(err))
 			return nil, err
 		}
 
-		messages = append(messages, &proto_gen.Message{
-			ChatId:    chatID,
-			From:      username,
-			Text:      text,
-			Timestamp: timestamppb.New(timestamp),
-		})
+		msg := &proto_gen.Message{
+			Id:              id,
+			ChatId:          chatID,
+			From:            username,
+			Text:            text,
+			Timestamp:       timestamppb.New(timestamp),
+			Kind:            parseKind(kind),
+			ClientMessageId: clientID.String,
+		}
+
+		if err := decodePayload(payload, msg); err != nil {
+			r.log.Error("Failed to decode message payload", zap.Int64("message_id", id), zap.Error(err))
+			return nil, err
+		}
+
+		var err error
+		if msg.Metadata, err = decodeMetadata(metadata); err != nil {
+			r.log.Error("Failed to decode message metadata", zap.Int64("message_id", id), zap.Error(err))
+			return nil, err
+		}
+
+		if pollID.Valid {
+			msg.Payload = &proto_gen.Message_Poll{Poll: &proto_gen.Poll{Id: pollID.Int64}}
+		}
+
+		messages = append(messages, msg)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type CommandRepo interface {
+	ClaimCommand(ctx context.Context, chatID int64, username, clientID string) (bool, error)
+	ReleaseCommand(ctx context.Context, chatID int64, username, clientID string) error
+	PruneCommandRequests(ctx context.Context, olderThan time.Duration) (int64, error)
+}
+
+// ClaimCommand records that the command sent with the client id is being run.
+// It returns false if the command was already run, e.g. the send is a retry.
+func (r *chatRepository) ClaimCommand(ctx context.Context, chatID int64, username, clientID string) (bool, error) {
+	userID, err := r.getUserID(ctx, username)
+	if err != nil {
+		return false, err
+	}
+
+	query := `INSERT INTO command_requests (chat_id, user_id, client_id) VALUES ($1, $2, $3)
+			  ON CONFLICT (chat_id, user_id, client_id) DO NOTHING`
+	res, err := r.db.ExecContext(ctx, query, chatID, userID, clientID)
+	if err != nil {
+		r.log.Error("Failed to claim command", zap.Int64("chat_id", chatID), zap.String("username", username), zap.Error(err))
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if n == 0 {
+		r.log.Info("Duplicate command ignored", zap.Int64("chat_id", chatID), zap.String("client_id", clientID))
+	}
+
+	return n == 1, nil
+}
+
+// ReleaseCommand forgets the claim of a command that failed, so a retry with the
+// same client id runs it again.
+func (r *chatRepository) ReleaseCommand(ctx context.Context, chatID int64, username, clientID string) error {
+	userID, err := r.getUserID(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	query := `DELETE FROM command_requests WHERE chat_id = $1 AND user_id = $2 AND client_id = $3`
+	if _, err := r.db.ExecContext(ctx, query, chatID, userID, clientID); err != nil {
+		r.log.Error("Failed to release command", zap.Int64("chat_id", chatID), zap.String("username", username), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *chatRepository) PruneCommandRequests(ctx context.Context, olderThan time.Duration) (int64, error) {
+	query := `DELETE FROM command_requests WHERE created_at < NOW() - make_interval(secs => $1)`
+	res, err := r.db.ExecContext(ctx, query, olderThan.Seconds())
+	if err != nil {
+		r.log.Error("Failed to prune command requests", zap.Error(err))
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
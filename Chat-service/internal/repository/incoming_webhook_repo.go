@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+
+	"chat-grpc/Chat-service/internal/entity"
+	"go.uber.org/zap"
+)
+
+type IncomingWebhookRepo interface {
+	CreateIncomingWebhook(ctx context.Context, hook *entity.IncomingWebhook, token string) (int64, error)
+	GetIncomingWebhook(ctx context.Context, id int64) (*entity.IncomingWebhook, error)
+	GetIncomingWebhookByToken(ctx context.Context, token string) (*entity.IncomingWebhook, error)
+	RotateIncomingWebhookToken(ctx context.Context, id int64, token string) error
+	RevokeIncomingWebhook(ctx context.Context, id int64) error
+}
+
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func (r *chatRepository) CreateIncomingWebhook(ctx context.Context, hook *entity.IncomingWebhook, token string) (int64, error) {
+	userID, err := r.getUserID(ctx, hook.Creator)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	query := `INSERT INTO incoming_webhooks (chat_id, creator_id, name, token_hash) VALUES ($1, $2, $3, $4) RETURNING id`
+	err = r.db.QueryRowContext(ctx, query, hook.ChatID, userID, hook.Name, hashToken(token)).Scan(&id)
+	if err != nil {
+		r.log.Error("Failed to create incoming webhook", zap.Int64("chat_id", hook.ChatID), zap.Error(err))
+		return 0, err
+	}
+
+	r.log.Info("Incoming webhook created", zap.Int64("chat_id", hook.ChatID), zap.Int64("webhook_id", id))
+	return id, nil
+}
+
+func (r *chatRepository) GetIncomingWebhook(ctx context.Context, id int64) (*entity.IncomingWebhook, error) {
+	query := `SELECT id, chat_id, creator_id, name, created_at FROM incoming_webhooks WHERE id = $1 AND revoked_at IS NULL`
+	return r.getIncomingWebhook(ctx, query, id)
+}
+
+func (r *chatRepository) GetIncomingWebhookByToken(ctx context.Context, token string) (*entity.IncomingWebhook, error) {
+	query := `SELECT id, chat_id, creator_id, name, created_at FROM incoming_webhooks WHERE token_hash = $1 AND revoked_at IS NULL`
+	return r.getIncomingWebhook(ctx, query, hashToken(token))
+}
+
+func (r *chatRepository) getIncomingWebhook(ctx context.Context, query string, arg any) (*entity.IncomingWebhook, error) {
+	var hook entity.IncomingWebhook
+	var creatorID int64
+
+	err := r.db.QueryRowContext(ctx, query, arg).Scan(&hook.ID, &hook.ChatID, &creatorID, &hook.Name, &hook.CreatedAt)
+	if err != nil {
+		r.log.Warn("Incoming webhook not found", zap.Error(err))
+		return nil, err
+	}
+
+	err = r.dbUsers.QueryRowContext(ctx, "SELECT name FROM users WHERE id = $1", creatorID).Scan(&hook.Creator)
+	if err != nil {
+		r.log.Error("Incoming webhook creator not found", zap.Int64("user_id", creatorID), zap.Error(err))
+		return nil, err
+	}
+
+	return &hook, nil
+}
+
+func (r *chatRepository) RotateIncomingWebhookToken(ctx context.Context, id int64, token string) error {
+	query := `UPDATE incoming_webhooks SET token_hash = $1 WHERE id = $2 AND revoked_at IS NULL`
+	res, err := r.db.ExecContext(ctx, query, hashToken(token), id)
+	if err != nil {
+		r.log.Error("Failed to rotate incoming webhook token", zap.Int64("webhook_id", id), zap.Error(err))
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+
+	r.log.Info("Incoming webhook token rotated", zap.Int64("webhook_id", id))
+	return nil
+}
+
+func (r *chatRepository) RevokeIncomingWebhook(ctx context.Context, id int64) error {
+	query := `UPDATE incoming_webhooks SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`
+	_, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		r.log.Error("Failed to revoke incoming webhook", zap.Int64("webhook_id", id), zap.Error(err))
+		return err
+	}
+
+	r.log.Info("Incoming webhook revoked", zap.Int64("webhook_id", id))
+	return nil
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"chat-grpc/Chat-service/internal/entity"
+	"go.uber.org/zap"
+)
+
+type MemberRepo interface {
+	GetUsername(ctx context.Context, userID int64) (string, error)
+	GetMemberRole(ctx context.Context, chatID int64, username string) (entity.ChatRole, error)
+	AddMember(ctx context.Context, chatID int64, username string, role entity.ChatRole) error
+	MuteMember(ctx context.Context, chatID int64, username string, until time.Time) error
+	IsMuted(ctx context.Context, chatID int64, username string) (bool, error)
+	SetTopic(ctx context.Context, chatID int64, topic string) error
+}
+
+func (r *chatRepository) getUserID(ctx context.Context, username string) (int64, error) {
+	var userID int64
+	err := r.dbUsers.QueryRowContext(ctx, "SELECT id FROM users WHERE name = $1", username).Scan(&userID)
+	if err != nil {
+		r.log.Error("User not found", zap.String("username", username), zap.Error(err))
+		return 0, err
+	}
+
+	return userID, nil
+}
+
+func (r *chatRepository) GetUsername(ctx context.Context, userID int64) (string, error) {
+	var username string
+	err := r.dbUsers.QueryRowContext(ctx, "SELECT name FROM users WHERE id = $1", userID).Scan(&username)
+	if err != nil {
+		r.log.Error("User not found", zap.Int64("user_id", userID), zap.Error(err))
+		return "", err
+	}
+
+	return username, nil
+}
+
+func (r *chatRepository) GetMemberRole(ctx context.Context, chatID int64, username string) (entity.ChatRole, error) {
+	userID, err := r.getUserID(ctx, username)
+	if err != nil {
+		return 0, err
+	}
+
+	var roleStr string
+	query := `SELECT role FROM chat_users WHERE chat_id = $1 AND user_id = $2`
+	err = r.db.QueryRowContext(ctx, query, chatID, userID).Scan(&roleStr)
+	if err != nil {
+		r.log.Warn("User is not a chat member", zap.Int64("chat_id", chatID), zap.String("username", username), zap.Error(err))
+		return 0, err
+	}
+
+	return entity.ParseChatRole(roleStr), nil
+}
+
+func (r *chatRepository) AddMember(ctx context.Context, chatID int64, username string, role entity.ChatRole) error {
+	userID, err := r.getUserID(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	banned, err := r.isBanned(ctx, chatID, userID)
+	if err != nil {
+		return err
+	}
+	if banned {
+		return ErrUserBanned
+	}
+
+	query := `INSERT INTO chat_users (chat_id, user_id, role) VALUES ($1, $2, $3) ON CONFLICT (chat_id, user_id) DO NOTHING`
+	_, err = r.db.ExecContext(ctx, query, chatID, userID, role.StringRole())
+	if err != nil {
+		r.log.Error("Failed to add user to chat", zap.Int64("chat_id", chatID), zap.Int64("user_id", userID), zap.Error(err))
+		return err
+	}
+
+	r.log.Info("User added to chat", zap.Int64("chat_id", chatID), zap.String("username", username))
+	return nil
+}
+
+func (r *chatRepository) MuteMember(ctx context.Context, chatID int64, username string, until time.Time) error {
+	userID, err := r.getUserID(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE chat_users SET muted_until = $1 WHERE chat_id = $2 AND user_id = $3`
+	res, err := r.db.ExecContext(ctx, query, until, chatID, userID)
+	if err != nil {
+		r.log.Error("Failed to mute user", zap.Int64("chat_id", chatID), zap.String("username", username), zap.Error(err))
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+
+	r.log.Info("User muted", zap.Int64("chat_id", chatID), zap.String("username", username), zap.Time("until", until))
+	return nil
+}
+
+func (r *chatRepository) IsMuted(ctx context.Context, chatID int64, username string) (bool, error) {
+	userID, err := r.getUserID(ctx, username)
+	if err != nil {
+		return false, err
+	}
+
+	var muted bool
+	query := `SELECT COALESCE(muted_until > NOW(), FALSE) FROM chat_users WHERE chat_id = $1 AND user_id = $2`
+	err = r.db.QueryRowContext(ctx, query, chatID, userID).Scan(&muted)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		r.log.Error("Failed to check mute", zap.Error(err))
+		return false, err
+	}
+
+	return muted, nil
+}
+
+func (r *chatRepository) SetTopic(ctx context.Context, chatID int64, topic string) error {
+	query := `UPDATE chats SET topic = $1, updated_at = NOW() WHERE id = $2`
+	_, err := r.db.ExecContext(ctx, query, topic, chatID)
+	if err != nil {
+		r.log.Error("Failed to set chat topic", zap.Int64("chat_id", chatID), zap.Error(err))
+		return err
+	}
+
+	r.log.Info("Chat topic changed", zap.Int64("chat_id", chatID))
+	return nil
+}
@@ -0,0 +1,326 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"chat-grpc/Chat-service/internal/entity"
+	"chat-grpc/proto_gen"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var ErrUserBanned = errors.New("user is banned from this chat")
+
+type ModerationRepo interface {
+	GetModerationSettings(ctx context.Context, chatID int64) (*entity.ModerationSettings, error)
+	SetModerationSettings(ctx context.Context, settings *entity.ModerationSettings) error
+	FlagMessage(ctx context.Context, messageID int64, reason string) error
+	GetMessageChatID(ctx context.Context, messageID int64) (int64, error)
+	CreateReport(ctx context.Context, report *entity.Report) error
+	GetFlaggedMessages(ctx context.Context, chatID int64) ([]*proto_gen.FlaggedMessage, error)
+	ResolveMessage(ctx context.Context, messageID int64, remove bool) error
+	BanUser(ctx context.Context, chatID int64, username, bannedBy, reason string) error
+	UnbanUser(ctx context.Context, chatID int64, username string) error
+	IsBanned(ctx context.Context, chatID int64, username string) (bool, error)
+}
+
+// GetModerationSettings returns the default settings if the chat has none.
+func (r *chatRepository) GetModerationSettings(ctx context.Context, chatID int64) (*entity.ModerationSettings, error) {
+	settings := &entity.ModerationSettings{ChatID: chatID}
+	var action string
+
+	query := `SELECT action, words, patterns, blocked_domains FROM chat_moderation WHERE chat_id = $1`
+	err := r.db.QueryRowContext(ctx, query, chatID).Scan(&action, pq.Array(&settings.Words),
+		pq.Array(&settings.Patterns), pq.Array(&settings.BlockedDomains))
+	if err == sql.ErrNoRows {
+		return settings, nil
+	}
+	if err != nil {
+		r.log.Error("Failed to get moderation settings", zap.Int64("chat_id", chatID), zap.Error(err))
+		return nil, err
+	}
+
+	settings.Action = entity.ParseModerationAction(action)
+	return settings, nil
+}
+
+func (r *chatRepository) SetModerationSettings(ctx context.Context, settings *entity.ModerationSettings) error {
+	query := `INSERT INTO chat_moderation (chat_id, action, words, patterns, blocked_domains)
+			  VALUES ($1, $2, $3, $4, $5)
+			  ON CONFLICT (chat_id) DO UPDATE
+			  SET action = EXCLUDED.action, words = EXCLUDED.words, patterns = EXCLUDED.patterns,
+			      blocked_domains = EXCLUDED.blocked_domains, updated_at = NOW()`
+	_, err := r.db.ExecContext(ctx, query, settings.ChatID, settings.Action.StringAction(),
+		pq.Array(settings.Words), pq.Array(settings.Patterns), pq.Array(settings.BlockedDomains))
+	if err != nil {
+		r.log.Error("Failed to save moderation settings", zap.Int64("chat_id", settings.ChatID), zap.Error(err))
+		return err
+	}
+
+	r.log.Info("Moderation settings updated", zap.Int64("chat_id", settings.ChatID))
+	return nil
+}
+
+func (r *chatRepository) FlagMessage(ctx context.Context, messageID int64, reason string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE messages SET flagged = TRUE, flag_reason = $1 WHERE id = $2`, reason, messageID)
+	if err != nil {
+		r.log.Error("Failed to flag message", zap.Int64("message_id", messageID), zap.Error(err))
+		return err
+	}
+
+	r.log.Info("Message flagged", zap.Int64("message_id", messageID), zap.String("reason", reason))
+	return nil
+}
+
+func (r *chatRepository) GetMessageChatID(ctx context.Context, messageID int64) (int64, error) {
+	var chatID int64
+	err := r.db.QueryRowContext(ctx, `SELECT chat_id FROM messages WHERE id = $1`, messageID).Scan(&chatID)
+	if err != nil {
+		r.log.Warn("Message not found", zap.Int64("message_id", messageID), zap.Error(err))
+		return 0, err
+	}
+
+	return chatID, nil
+}
+
+// CreateReport stores a report; reporting the same message twice keeps the first report.
+func (r *chatRepository) CreateReport(ctx context.Context, report *entity.Report) error {
+	userID, err := r.getUserID(ctx, report.Reporter)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO reports (message_id, chat_id, reporter_id, reason) VALUES ($1, $2, $3, $4)
+			  ON CONFLICT (message_id, reporter_id) DO NOTHING`
+	_, err = r.db.ExecContext(ctx, query, report.MessageID, report.ChatID, userID, report.Reason)
+	if err != nil {
+		r.log.Error("Failed to create report", zap.Int64("message_id", report.MessageID), zap.Error(err))
+		return err
+	}
+
+	r.log.Info("Message reported", zap.Int64("message_id", report.MessageID), zap.String("reporter", report.Reporter))
+	return nil
+}
+
+// GetFlaggedMessages returns the messages of the chat that were flagged by the
+// moderator or have open reports, together with those reports.
+func (r *chatRepository) GetFlaggedMessages(ctx context.Context, chatID int64) ([]*proto_gen.FlaggedMessage, error) {
+	query := `SELECT m.id, u.name, m.text, m.timestamp, m.kind, m.payload, m.metadata, m.poll_id, m.client_id
+			  FROM messages m
+			  JOIN users u ON m.user_id = u.id
+			  WHERE m.chat_id = $1
+			    AND (m.flagged OR EXISTS (SELECT 1 FROM reports rp WHERE rp.message_id = m.id AND NOT rp.resolved))
+			  ORDER BY m.timestamp ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, chatID)
+	if err != nil {
+		r.log.Error("Failed to fetch flagged messages", zap.Int64("chat_id", chatID), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages, err := r.scanMessages(rows, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	flagged := make([]*proto_gen.FlaggedMessage, 0, len(messages))
+	byID := make(map[int64]*proto_gen.FlaggedMessage, len(messages))
+	for _, msg := range messages {
+		fm := &proto_gen.FlaggedMessage{Message: msg}
+		flagged = append(flagged, fm)
+		byID[msg.Id] = fm
+	}
+
+	reasonRows, err := r.db.QueryContext(ctx, `SELECT id, COALESCE(flag_reason, '') FROM messages WHERE chat_id = $1 AND flagged`, chatID)
+	if err != nil {
+		r.log.Error("Failed to fetch flag reasons", zap.Error(err))
+		return nil, err
+	}
+	defer reasonRows.Close()
+
+	for reasonRows.Next() {
+		var id int64
+		var reason string
+		if err := reasonRows.Scan(&id, &reason); err != nil {
+			return nil, err
+		}
+		if fm, ok := byID[id]; ok {
+			fm.FlagReason = reason
+		}
+	}
+	if err := reasonRows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.attachReports(ctx, chatID, byID); err != nil {
+		return nil, err
+	}
+
+	return flagged, nil
+}
+
+func (r *chatRepository) attachReports(ctx context.Context, chatID int64, byID map[int64]*proto_gen.FlaggedMessage) error {
+	query := `SELECT id, message_id, reporter_id, reason, created_at FROM reports
+			  WHERE chat_id = $1 AND NOT resolved ORDER BY created_at ASC`
+	rows, err := r.db.QueryContext(ctx, query, chatID)
+	if err != nil {
+		r.log.Error("Failed to fetch reports", zap.Int64("chat_id", chatID), zap.Error(err))
+		return err
+	}
+	defer rows.Close()
+
+	type reportRow struct {
+		report     *proto_gen.Report
+		messageID  int64
+		reporterID int64
+	}
+
+	var reports []reportRow
+	var reporterIDs []int64
+	for rows.Next() {
+		var rr reportRow
+		var createdAt time.Time
+		rr.report = &proto_gen.Report{}
+		if err := rows.Scan(&rr.report.Id, &rr.messageID, &rr.reporterID, &rr.report.Reason, &createdAt); err != nil {
+			r.log.Error("Failed to scan report", zap.Error(err))
+			return err
+		}
+		rr.report.CreatedAt = timestamppb.New(createdAt)
+		reports = append(reports, rr)
+		reporterIDs = append(reporterIDs, rr.reporterID)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(reports) == 0 {
+		return nil
+	}
+
+	names, err := r.getUsernames(ctx, reporterIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, rr := range reports {
+		rr.report.Reporter = names[rr.reporterID]
+		if fm, ok := byID[rr.messageID]; ok {
+			fm.Reports = append(fm.Reports, rr.report)
+		}
+	}
+
+	return nil
+}
+
+// ResolveMessage closes the reports of a message and either clears its flag or deletes it.
+func (r *chatRepository) ResolveMessage(ctx context.Context, messageID int64, remove bool) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.log.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `UPDATE reports SET resolved = TRUE, resolved_at = NOW() WHERE message_id = $1 AND NOT resolved`, messageID)
+	if err != nil {
+		r.log.Error("Failed to resolve reports", zap.Int64("message_id", messageID), zap.Error(err))
+		return err
+	}
+
+	if remove {
+		_, err = tx.ExecContext(ctx, `DELETE FROM messages WHERE id = $1`, messageID)
+	} else {
+		_, err = tx.ExecContext(ctx, `UPDATE messages SET flagged = FALSE, flag_reason = NULL WHERE id = $1`, messageID)
+	}
+	if err != nil {
+		r.log.Error("Failed to resolve message", zap.Int64("message_id", messageID), zap.Error(err))
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log.Error("Failed to commit message review", zap.Error(err))
+		return err
+	}
+
+	r.log.Info("Message reviewed", zap.Int64("message_id", messageID), zap.Bool("removed", remove))
+	return nil
+}
+
+// BanUser removes the user from the chat and keeps them from being added again.
+func (r *chatRepository) BanUser(ctx context.Context, chatID int64, username, bannedBy, reason string) error {
+	userID, err := r.getUserID(ctx, username)
+	if err != nil {
+		return err
+	}
+	adminID, err := r.getUserID(ctx, bannedBy)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.log.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO chat_bans (chat_id, user_id, banned_by, reason) VALUES ($1, $2, $3, $4)
+			  ON CONFLICT (chat_id, user_id) DO UPDATE SET banned_by = EXCLUDED.banned_by, reason = EXCLUDED.reason`
+	if _, err := tx.ExecContext(ctx, query, chatID, userID, adminID, reason); err != nil {
+		r.log.Error("Failed to ban user", zap.Int64("chat_id", chatID), zap.String("username", username), zap.Error(err))
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM chat_users WHERE chat_id = $1 AND user_id = $2`, chatID, userID); err != nil {
+		r.log.Error("Failed to remove banned user from chat", zap.Int64("chat_id", chatID), zap.Error(err))
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log.Error("Failed to commit ban", zap.Error(err))
+		return err
+	}
+
+	r.log.Info("User banned", zap.Int64("chat_id", chatID), zap.String("username", username))
+	return nil
+}
+
+func (r *chatRepository) UnbanUser(ctx context.Context, chatID int64, username string) error {
+	userID, err := r.getUserID(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, `DELETE FROM chat_bans WHERE chat_id = $1 AND user_id = $2`, chatID, userID)
+	if err != nil {
+		r.log.Error("Failed to unban user", zap.Int64("chat_id", chatID), zap.String("username", username), zap.Error(err))
+		return err
+	}
+
+	r.log.Info("User unbanned", zap.Int64("chat_id", chatID), zap.String("username", username))
+	return nil
+}
+
+func (r *chatRepository) IsBanned(ctx context.Context, chatID int64, username string) (bool, error) {
+	userID, err := r.getUserID(ctx, username)
+	if err != nil {
+		return false, err
+	}
+
+	return r.isBanned(ctx, chatID, userID)
+}
+
+func (r *chatRepository) isBanned(ctx context.Context, chatID, userID int64) (bool, error) {
+	var banned bool
+	query := `SELECT EXISTS (SELECT 1 FROM chat_bans WHERE chat_id = $1 AND user_id = $2)`
+	if err := r.db.QueryRowContext(ctx, query, chatID, userID).Scan(&banned); err != nil {
+		r.log.Error("Failed to check ban", zap.Int64("chat_id", chatID), zap.Error(err))
+		return false, err
+	}
+
+	return banned, nil
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"chat-grpc/Chat-service/internal/entity"
+	"chat-grpc/proto_gen"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+// OutboxChannel is notified on every new outbox entry, so relays do not have to wait for the next poll.
+const OutboxChannel = "outbox"
+
+// outboxLockKey is the advisory lock held by the relay publishing the outbox.
+// One relay at a time keeps the entries in order across replicas.
+const outboxLockKey = 735001
+
+// chatOutboxLockSpace is the first key of the per-chat advisory locks taken by
+// writers of the outbox, the second key is the chat id.
+const chatOutboxLockSpace = 735002
+
+type OutboxRepo interface {
+	RelayOutbox(ctx context.Context, limit int, publish func([]*entity.OutboxEntry) (int, error)) (int, error)
+	PruneOutbox(ctx context.Context, olderThan time.Duration) (int64, error)
+}
+
+// lockChatOutbox serializes the transactions writing messages of a chat until they
+// commit. Without it a transaction could take a lower outbox id, commit after a
+// later one and be relayed after it, so it must come before the first insert.
+func (r *chatRepository) lockChatOutbox(tx *sql.Tx, chatID int64) error {
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1::int, $2::int)`, chatOutboxLockSpace, chatID); err != nil {
+		r.log.Error("Failed to lock chat outbox", zap.Int64("chat_id", chatID), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// enqueueOutbox stores the message, as it will be published, in the caller's
+// transaction, which must hold lockChatOutbox for the chat.
+func (r *chatRepository) enqueueOutbox(tx *sql.Tx, msg *proto_gen.Message, messageID int64) error {
+	published := proto.Clone(msg).(*proto_gen.Message)
+	published.Id = messageID
+
+	data, err := proto.Marshal(published)
+	if err != nil {
+		r.log.Error("Failed to encode outbox message", zap.Error(err))
+		return err
+	}
+
+	if _, err := tx.Exec(`INSERT INTO outbox (chat_id, payload) VALUES ($1, $2)`, msg.ChatId, data); err != nil {
+		r.log.Error("Failed to save outbox entry", zap.Int64("message_id", messageID), zap.Error(err))
+		return err
+	}
+
+	// delivered to listeners only when the transaction commits
+	if _, err := tx.Exec(`SELECT pg_notify($1, '')`, OutboxChannel); err != nil {
+		r.log.Warn("Failed to notify outbox listeners", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// RelayOutbox passes up to limit pending entries, oldest first, to publish and
+// marks the first n it reports as published as sent. It returns 0 without calling
+// publish when another relay holds the outbox.
+func (r *chatRepository) RelayOutbox(ctx context.Context, limit int, publish func([]*entity.OutboxEntry) (int, error)) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.log.Error("Failed to begin transaction", zap.Error(err))
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err := tx.QueryRowContext(ctx, `SELECT pg_try_advisory_xact_lock($1)`, outboxLockKey).Scan(&locked); err != nil {
+		r.log.Error("Failed to lock outbox", zap.Error(err))
+		return 0, err
+	}
+	if !locked {
+		return 0, nil
+	}
+
+	query := `SELECT id, chat_id, payload, created_at FROM outbox WHERE sent_at IS NULL ORDER BY id LIMIT $1`
+	rows, err := tx.QueryContext(ctx, query, limit)
+	if err != nil {
+		r.log.Error("Failed to read outbox", zap.Error(err))
+		return 0, err
+	}
+
+	var entries []*entity.OutboxEntry
+	for rows.Next() {
+		var e entity.OutboxEntry
+		if err := rows.Scan(&e.ID, &e.ChatID, &e.Payload, &e.CreatedAt); err != nil {
+			rows.Close()
+			r.log.Error("Failed to scan outbox entry", zap.Error(err))
+			return 0, err
+		}
+		entries = append(entries, &e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(entries) == 0 {
+		return 0, nil
+	}
+
+	n, publishErr := publish(entries)
+	if n == 0 {
+		return 0, publishErr
+	}
+
+	ids := make([]int64, n)
+	for i := range ids {
+		ids[i] = entries[i].ID
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE outbox SET sent_at = NOW() WHERE id = ANY($1)`, pq.Array(ids)); err != nil {
+		r.log.Error("Failed to mark outbox entries as sent", zap.Error(err))
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log.Error("Failed to commit outbox relay", zap.Error(err))
+		return 0, err
+	}
+
+	return n, publishErr
+}
+
+func (r *chatRepository) PruneOutbox(ctx context.Context, olderThan time.Duration) (int64, error) {
+	query := `DELETE FROM outbox WHERE sent_at < NOW() - make_interval(secs => $1)`
+	res, err := r.db.ExecContext(ctx, query, olderThan.Seconds())
+	if err != nil {
+		r.log.Error("Failed to prune outbox", zap.Error(err))
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
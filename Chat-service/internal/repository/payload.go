@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"chat-grpc/proto_gen"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+var messageKinds = map[proto_gen.MessageKind]string{
+	proto_gen.MessageKind_TextKind:        "text",
+	proto_gen.MessageKind_SystemKind:      "system",
+	proto_gen.MessageKind_AttachmentKind:  "attachment",
+	proto_gen.MessageKind_LinkPreviewKind: "link_preview",
+	proto_gen.MessageKind_PollKind:        "poll",
+	proto_gen.MessageKind_CodeSnippetKind: "code_snippet",
+}
+
+func kindToString(kind proto_gen.MessageKind) string {
+	if s, ok := messageKinds[kind]; ok {
+		return s
+	}
+
+	return messageKinds[proto_gen.MessageKind_TextKind]
+}
+
+func parseKind(s string) proto_gen.MessageKind {
+	for kind, name := range messageKinds {
+		if name == s {
+			return kind
+		}
+	}
+
+	return proto_gen.MessageKind_TextKind
+}
+
+// encodePayload stores only the payload oneof of the message, e.g. {"linkPreview": {...}}.
+// Polls are not encoded: they live in their own table and are referenced by poll_id.
+func encodePayload(msg *proto_gen.Message) (sql.NullString, error) {
+	if msg.Payload == nil || msg.GetPoll() != nil {
+		return sql.NullString{}, nil
+	}
+
+	data, err := protojson.Marshal(&proto_gen.Message{Payload: msg.Payload})
+	if err != nil {
+		return sql.NullString{}, err
+	}
+
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+func decodePayload(data []byte, msg *proto_gen.Message) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	var holder proto_gen.Message
+	if err := protojson.Unmarshal(data, &holder); err != nil {
+		return err
+	}
+	msg.Payload = holder.Payload
+
+	return nil
+}
+
+func encodeMetadata(metadata map[string]string) (string, error) {
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+
+	data, err := json.Marshal(metadata)
+	return string(data), err
+}
+
+func decodeMetadata(data []byte) (map[string]string, error) {
+	var metadata map[string]string
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, err
+	}
+
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+
+	return metadata, nil
+}
@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"chat-grpc/Chat-service/internal/entity"
+	"chat-grpc/proto_gen"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+type PollRepo interface {
+	CreatePoll(poll *entity.Poll, msg *proto_gen.Message) (int64, error)
+	GetPoll(ctx context.Context, pollID int64) (*entity.Poll, error)
+	Vote(pollID int64, username string, options []int, tally func(*entity.Poll) *proto_gen.Message) error
+	ClosePoll(pollID int64, tally func(*entity.Poll) *proto_gen.Message) error
+}
+
+// queryer is implemented by both *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// CreatePoll stores the poll with its message and publishes msg, with the ids of
+// both filled in, through the outbox.
+func (r *chatRepository) CreatePoll(poll *entity.Poll, msg *proto_gen.Message) (int64, error) {
+	r.log.Info("Creating poll", zap.Int64("chat_id", poll.ChatID), zap.String("username", poll.Creator))
+
+	var userID int64
+	err := r.dbUsers.QueryRow("SELECT id FROM users WHERE name = $1", poll.Creator).Scan(&userID)
+	if err != nil {
+		r.log.Error("User not found", zap.String("username", poll.Creator), zap.Error(err))
+		return 0, err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.log.Error("Failed to begin transaction", zap.Error(err))
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := r.lockChatOutbox(tx, poll.ChatID); err != nil {
+		return 0, err
+	}
+
+	var pollID int64
+	query := `INSERT INTO polls (chat_id, creator_id, question, options, multi_choice, anonymous, closes_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+	err = tx.QueryRow(query, poll.ChatID, userID, poll.Question, pq.Array(poll.Options),
+		poll.MultiChoice, poll.Anonymous, poll.ClosesAt).Scan(&pollID)
+	if err != nil {
+		r.log.Error("Failed to create poll", zap.Error(err))
+		return 0, err
+	}
+
+	var messageID int64
+	query = `INSERT INTO messages (chat_id, user_id, text, timestamp, kind, poll_id) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	err = tx.QueryRow(query, poll.ChatID, userID, poll.Question, msg.Timestamp.AsTime(),
+		kindToString(proto_gen.MessageKind_PollKind), pollID).Scan(&messageID)
+	if err != nil {
+		r.log.Error("Failed to save poll message", zap.Error(err))
+		return 0, err
+	}
+
+	msg.GetPoll().Id = pollID
+	if err := r.enqueueOutbox(tx, msg, messageID); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log.Error("Failed to commit poll", zap.Error(err))
+		return 0, err
+	}
+
+	r.log.Info("Poll created successfully", zap.Int64("poll_id", pollID))
+	return pollID, nil
+}
+
+func (r *chatRepository) GetPoll(ctx context.Context, pollID int64) (*entity.Poll, error) {
+	return r.getPoll(ctx, r.db, pollID)
+}
+
+func (r *chatRepository) getPoll(ctx context.Context, q queryer, pollID int64) (*entity.Poll, error) {
+	poll := &entity.Poll{ID: pollID}
+	var creatorID int64
+	var closesAt sql.NullTime
+
+	query := `SELECT chat_id, creator_id, question, options, multi_choice, anonymous, closes_at, closed, created_at
+			  FROM polls WHERE id = $1`
+	err := q.QueryRowContext(ctx, query, pollID).Scan(&poll.ChatID, &creatorID, &poll.Question,
+		pq.Array(&poll.Options), &poll.MultiChoice, &poll.Anonymous, &closesAt, &poll.Closed, &poll.CreatedAt)
+	if err != nil {
+		r.log.Error("Failed to get poll", zap.Int64("poll_id", pollID), zap.Error(err))
+		return nil, err
+	}
+	if closesAt.Valid {
+		poll.ClosesAt = &closesAt.Time
+	}
+
+	rows, err := q.QueryContext(ctx, `SELECT user_id, option_index FROM poll_votes WHERE poll_id = $1 ORDER BY voted_at`, pollID)
+	if err != nil {
+		r.log.Error("Failed to get poll votes", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	userIDs := []int64{creatorID}
+	var voterIDs []int64
+	for rows.Next() {
+		var userID int64
+		var vote entity.PollVote
+		if err := rows.Scan(&userID, &vote.OptionIndex); err != nil {
+			r.log.Error("Failed to scan poll vote", zap.Error(err))
+			return nil, err
+		}
+		voterIDs = append(voterIDs, userID)
+		userIDs = append(userIDs, userID)
+		poll.Votes = append(poll.Votes, vote)
+	}
+	if err := rows.Err(); err != nil {
+		r.log.Error("Row iteration error", zap.Error(err))
+		return nil, err
+	}
+
+	names, err := r.getUsernames(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	poll.Creator = names[creatorID]
+	for i, id := range voterIDs {
+		poll.Votes[i].Username = names[id]
+	}
+
+	return poll, nil
+}
+
+// Vote replaces the previous vote of the user and publishes the message built by
+// tally from the new poll state through the outbox.
+func (r *chatRepository) Vote(pollID int64, username string, options []int, tally func(*entity.Poll) *proto_gen.Message) error {
+	r.log.Info("Voting in poll", zap.Int64("poll_id", pollID), zap.String("username", username))
+
+	var userID int64
+	err := r.dbUsers.QueryRow("SELECT id FROM users WHERE name = $1", username).Scan(&userID)
+	if err != nil {
+		r.log.Error("User not found", zap.String("username", username), zap.Error(err))
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.log.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	closed, err := r.lockPoll(tx, pollID)
+	if err != nil {
+		return err
+	}
+	if closed {
+		return errors.New("poll is closed")
+	}
+
+	_, err = tx.Exec(`DELETE FROM poll_votes WHERE poll_id = $1 AND user_id = $2`, pollID, userID)
+	if err != nil {
+		r.log.Error("Failed to reset previous vote", zap.Error(err))
+		return err
+	}
+
+	for _, option := range options {
+		_, err = tx.Exec(`INSERT INTO poll_votes (poll_id, user_id, option_index) VALUES ($1, $2, $3)`, pollID, userID, option)
+		if err != nil {
+			r.log.Error("Failed to save vote", zap.Error(err))
+			return err
+		}
+	}
+
+	if err := r.enqueueTally(tx, pollID, tally); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log.Error("Failed to commit vote", zap.Error(err))
+		return err
+	}
+
+	r.log.Info("Vote saved", zap.Int64("poll_id", pollID), zap.String("username", username))
+	return nil
+}
+
+func (r *chatRepository) ClosePoll(pollID int64, tally func(*entity.Poll) *proto_gen.Message) error {
+	r.log.Info("Closing poll", zap.Int64("poll_id", pollID))
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.log.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := r.lockPoll(tx, pollID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`UPDATE polls SET closed = TRUE WHERE id = $1`, pollID); err != nil {
+		r.log.Error("Failed to close poll", zap.Error(err))
+		return err
+	}
+
+	if err := r.enqueueTally(tx, pollID, tally); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log.Error("Failed to commit poll closing", zap.Error(err))
+		return err
+	}
+
+	r.log.Info("Poll closed", zap.Int64("poll_id", pollID))
+	return nil
+}
+
+// lockPoll takes the outbox lock of the poll's chat and then the poll row, and
+// reports whether the poll no longer accepts votes.
+func (r *chatRepository) lockPoll(tx *sql.Tx, pollID int64) (bool, error) {
+	var chatID int64
+	if err := tx.QueryRow(`SELECT chat_id FROM polls WHERE id = $1`, pollID).Scan(&chatID); err != nil {
+		r.log.Error("Failed to get poll", zap.Int64("poll_id", pollID), zap.Error(err))
+		return false, err
+	}
+
+	if err := r.lockChatOutbox(tx, chatID); err != nil {
+		return false, err
+	}
+
+	var closed bool
+	err := tx.QueryRow(`SELECT closed OR COALESCE(closes_at <= NOW(), FALSE) FROM polls WHERE id = $1 FOR UPDATE`, pollID).Scan(&closed)
+	if err != nil {
+		r.log.Error("Failed to lock poll", zap.Int64("poll_id", pollID), zap.Error(err))
+		return false, err
+	}
+
+	return closed, nil
+}
+
+// enqueueTally reads the poll as the transaction sees it, so the published tally
+// includes the change being committed.
+func (r *chatRepository) enqueueTally(tx *sql.Tx, pollID int64, tally func(*entity.Poll) *proto_gen.Message) error {
+	poll, err := r.getPoll(context.Background(), tx, pollID)
+	if err != nil {
+		return err
+	}
+
+	return r.enqueueOutbox(tx, tally(poll), 0)
+}
+
+func (r *chatRepository) getUsernames(ctx context.Context, userIDs []int64) (map[int64]string, error) {
+	rows, err := r.dbUsers.QueryContext(ctx, `SELECT id, name FROM users WHERE id = ANY($1)`, pq.Array(userIDs))
+	if err != nil {
+		r.log.Error("Failed to get usernames", zap.Error(err))
+		return nil, fmt.Errorf("failed to get usernames: %w", err)
+	}
+	defer rows.Close()
+
+	names := make(map[int64]string, len(userIDs))
+	for rows.Next() {
+		var id int64
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, fmt.Errorf("failed to scan username: %w", err)
+		}
+		names[id] = name
+	}
+
+	return names, rows.Err()
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"chat-grpc/Chat-service/internal/entity"
+	"go.uber.org/zap"
+)
+
+type RateLimitRepo interface {
+	GetRateLimits(ctx context.Context, chatID int64) (*entity.RateLimits, error)
+	SetRateLimits(ctx context.Context, limits *entity.RateLimits) error
+	TakeTokens(ctx context.Context, buckets []entity.TokenBucket) (time.Duration, error)
+	PruneTokenBuckets(ctx context.Context) (int64, error)
+}
+
+// GetRateLimits returns sql.ErrNoRows if the chat uses the default limits.
+func (r *chatRepository) GetRateLimits(ctx context.Context, chatID int64) (*entity.RateLimits, error) {
+	limits := &entity.RateLimits{ChatID: chatID}
+
+	query := `SELECT user_per_minute, user_burst, chat_per_minute, chat_burst FROM chat_rate_limits WHERE chat_id = $1`
+	err := r.db.QueryRowContext(ctx, query, chatID).Scan(&limits.UserPerMinute, &limits.UserBurst,
+		&limits.ChatPerMinute, &limits.ChatBurst)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.log.Error("Failed to get rate limits", zap.Int64("chat_id", chatID), zap.Error(err))
+		}
+		return nil, err
+	}
+
+	return limits, nil
+}
+
+func (r *chatRepository) SetRateLimits(ctx context.Context, limits *entity.RateLimits) error {
+	query := `INSERT INTO chat_rate_limits (chat_id, user_per_minute, user_burst, chat_per_minute, chat_burst)
+			  VALUES ($1, $2, $3, $4, $5)
+			  ON CONFLICT (chat_id) DO UPDATE
+			  SET user_per_minute = EXCLUDED.user_per_minute, user_burst = EXCLUDED.user_burst,
+			      chat_per_minute = EXCLUDED.chat_per_minute, chat_burst = EXCLUDED.chat_burst, updated_at = NOW()`
+	_, err := r.db.ExecContext(ctx, query, limits.ChatID, limits.UserPerMinute, limits.UserBurst,
+		limits.ChatPerMinute, limits.ChatBurst)
+	if err != nil {
+		r.log.Error("Failed to save rate limits", zap.Int64("chat_id", limits.ChatID), zap.Error(err))
+		return err
+	}
+
+	r.log.Info("Rate limits updated", zap.Int64("chat_id", limits.ChatID))
+	return nil
+}
+
+// takeTokenQuery refills the bucket for the time passed since its last update and
+// takes one token. No row is returned when the bucket has less than one token.
+const takeTokenQuery = `
+	INSERT INTO rate_limit_buckets AS b (key, tokens, rate, burst, updated_at)
+	VALUES ($1, $3::float8 - 1, $2::float8, $3::float8, clock_timestamp())
+	ON CONFLICT (key) DO UPDATE
+	SET tokens = LEAST($3::float8, b.tokens + EXTRACT(EPOCH FROM clock_timestamp() - b.updated_at)::float8 * $2::float8) - 1,
+	    rate = $2::float8, burst = $3::float8, updated_at = clock_timestamp()
+	WHERE LEAST($3::float8, b.tokens + EXTRACT(EPOCH FROM clock_timestamp() - b.updated_at)::float8 * $2::float8) >= 1
+	RETURNING tokens`
+
+// TakeTokens takes a token from every bucket or from none of them. Buckets are
+// rows in Postgres, so the limits are shared by all Chat-service replicas.
+// It returns how long to wait when a bucket is empty.
+func (r *chatRepository) TakeTokens(ctx context.Context, buckets []entity.TokenBucket) (time.Duration, error) {
+	if len(buckets) == 0 {
+		return 0, nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.log.Error("Failed to begin transaction", zap.Error(err))
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	for _, b := range buckets {
+		var tokens float64
+		err := tx.QueryRowContext(ctx, takeTokenQuery, b.Key, b.Rate, b.Burst).Scan(&tokens)
+		if err == sql.ErrNoRows {
+			return r.retryAfter(ctx, tx, b)
+		}
+		if err != nil {
+			r.log.Error("Failed to take rate limit token", zap.String("key", b.Key), zap.Error(err))
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log.Error("Failed to commit rate limit tokens", zap.Error(err))
+		return 0, err
+	}
+
+	return 0, nil
+}
+
+func (r *chatRepository) retryAfter(ctx context.Context, tx *sql.Tx, b entity.TokenBucket) (time.Duration, error) {
+	var tokens float64
+	query := `SELECT LEAST($2::float8, tokens + EXTRACT(EPOCH FROM clock_timestamp() - updated_at)::float8 * $3::float8)
+			  FROM rate_limit_buckets WHERE key = $1`
+	if err := tx.QueryRowContext(ctx, query, b.Key, b.Burst, b.Rate).Scan(&tokens); err != nil {
+		r.log.Error("Failed to read rate limit bucket", zap.String("key", b.Key), zap.Error(err))
+		return 0, err
+	}
+
+	wait := time.Duration((1 - tokens) / b.Rate * float64(time.Second))
+	return max(wait, time.Millisecond), nil
+}
+
+// PruneTokenBuckets deletes buckets that have refilled completely: a missing
+// bucket is the same as a full one.
+func (r *chatRepository) PruneTokenBuckets(ctx context.Context) (int64, error) {
+	query := `DELETE FROM rate_limit_buckets
+			  WHERE tokens + EXTRACT(EPOCH FROM clock_timestamp() - updated_at)::float8 * rate >= burst`
+	res, err := r.db.ExecContext(ctx, query)
+	if err != nil {
+		r.log.Error("Failed to prune rate limit buckets", zap.Error(err))
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"chat-grpc/Chat-service/internal/entity"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+type WebhookRepo interface {
+	CreateWebhook(ctx context.Context, webhook *entity.Webhook) (int64, error)
+	GetWebhook(ctx context.Context, id int64) (*entity.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int64) error
+	ListWebhooks(ctx context.Context, chatID int64) ([]*entity.Webhook, error)
+	GetActiveWebhooks(ctx context.Context, chatID int64) ([]*entity.Webhook, error)
+	RecordWebhookDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error
+	ResetWebhookFailures(ctx context.Context, id int64) error
+	IncWebhookFailures(ctx context.Context, id int64, maxFailures int) (bool, error)
+	EnqueueWebhookJobs(ctx context.Context, jobs []*entity.WebhookJob) error
+	ClaimWebhookJobs(ctx context.Context, limit int, lease time.Duration) ([]*entity.WebhookJob, error)
+	RetryWebhookJob(ctx context.Context, id int64, after time.Duration) error
+	DeleteWebhookJob(ctx context.Context, id int64) error
+}
+
+const webhookColumns = `id, chat_id, url, secret, event_types, active, failure_count, created_at`
+
+func scanWebhook(row interface{ Scan(...any) error }) (*entity.Webhook, error) {
+	var w entity.Webhook
+	err := row.Scan(&w.ID, &w.ChatID, &w.URL, &w.Secret, pq.Array(&w.EventTypes), &w.Active, &w.FailureCount, &w.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+func (r *chatRepository) CreateWebhook(ctx context.Context, webhook *entity.Webhook) (int64, error) {
+	userID, err := r.getUserID(ctx, webhook.Creator)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	query := `INSERT INTO webhooks (chat_id, creator_id, url, secret, event_types) VALUES ($1, $2, $3, $4, $5) RETURNING id`
+	err = r.db.QueryRowContext(ctx, query, webhook.ChatID, userID, webhook.URL, webhook.Secret,
+		pq.Array(webhook.EventTypes)).Scan(&id)
+	if err != nil {
+		r.log.Error("Failed to create webhook", zap.Int64("chat_id", webhook.ChatID), zap.Error(err))
+		return 0, err
+	}
+
+	r.log.Info("Webhook created", zap.Int64("chat_id", webhook.ChatID), zap.Int64("webhook_id", id))
+	return id, nil
+}
+
+func (r *chatRepository) GetWebhook(ctx context.Context, id int64) (*entity.Webhook, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE id = $1`, id)
+	webhook, err := scanWebhook(row)
+	if err != nil {
+		r.log.Warn("Failed to get webhook", zap.Int64("webhook_id", id), zap.Error(err))
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+func (r *chatRepository) DeleteWebhook(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM webhooks WHERE id = $1`, id)
+	if err != nil {
+		r.log.Error("Failed to delete webhook", zap.Int64("webhook_id", id), zap.Error(err))
+		return err
+	}
+
+	r.log.Info("Webhook deleted", zap.Int64("webhook_id", id))
+	return nil
+}
+
+func (r *chatRepository) ListWebhooks(ctx context.Context, chatID int64) ([]*entity.Webhook, error) {
+	return r.queryWebhooks(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE chat_id = $1 ORDER BY id`, chatID)
+}
+
+func (r *chatRepository) GetActiveWebhooks(ctx context.Context, chatID int64) ([]*entity.Webhook, error) {
+	return r.queryWebhooks(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE chat_id = $1 AND active ORDER BY id`, chatID)
+}
+
+func (r *chatRepository) queryWebhooks(ctx context.Context, query string, args ...any) ([]*entity.Webhook, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.log.Error("Failed to get webhooks", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []*entity.Webhook
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			r.log.Error("Failed to scan webhook", zap.Error(err))
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+func (r *chatRepository) RecordWebhookDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	var statusCode sql.NullInt32
+	if delivery.StatusCode != 0 {
+		statusCode = sql.NullInt32{Int32: int32(delivery.StatusCode), Valid: true}
+	}
+	var deliveryErr sql.NullString
+	if delivery.Error != "" {
+		deliveryErr = sql.NullString{String: delivery.Error, Valid: true}
+	}
+
+	query := `INSERT INTO webhook_deliveries (webhook_id, message_id, event_type, attempt, status_code, error, success)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err := r.db.ExecContext(ctx, query, delivery.WebhookID, delivery.MessageID, delivery.EventType,
+		delivery.Attempt, statusCode, deliveryErr, delivery.Success)
+	if err != nil {
+		r.log.Error("Failed to record webhook delivery", zap.Int64("webhook_id", delivery.WebhookID), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *chatRepository) ResetWebhookFailures(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE webhooks SET failure_count = 0 WHERE id = $1 AND failure_count <> 0`, id)
+	if err != nil {
+		r.log.Error("Failed to reset webhook failures", zap.Int64("webhook_id", id), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// IncWebhookFailures counts a failed delivery and disables the webhook once
+// maxFailures deliveries in a row have failed. It reports whether the webhook was disabled.
+func (r *chatRepository) IncWebhookFailures(ctx context.Context, id int64, maxFailures int) (bool, error) {
+	var active bool
+	query := `UPDATE webhooks
+			  SET failure_count = failure_count + 1,
+			      active = failure_count + 1 < $2,
+			      disabled_at = CASE WHEN failure_count + 1 >= $2 THEN NOW() ELSE disabled_at END
+			  WHERE id = $1 AND active
+			  RETURNING active`
+	err := r.db.QueryRowContext(ctx, query, id, maxFailures).Scan(&active)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		r.log.Error("Failed to count webhook failure", zap.Int64("webhook_id", id), zap.Error(err))
+		return false, err
+	}
+
+	if !active {
+		r.log.Warn("Webhook disabled after repeated failures", zap.Int64("webhook_id", id))
+	}
+
+	return !active, nil
+}
+
+// EnqueueWebhookJobs stores the jobs of one event. A job the event already has,
+// e.g. when the event is redelivered, is kept as it is.
+func (r *chatRepository) EnqueueWebhookJobs(ctx context.Context, jobs []*entity.WebhookJob) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.log.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO webhook_jobs (webhook_id, event_id, event_type, message_id, body) VALUES ($1, $2, $3, $4, $5)
+			  ON CONFLICT (webhook_id, event_id) DO NOTHING`
+	for _, job := range jobs {
+		var messageID sql.NullInt64
+		if job.MessageID != 0 {
+			messageID = sql.NullInt64{Int64: job.MessageID, Valid: true}
+		}
+
+		if _, err := tx.ExecContext(ctx, query, job.WebhookID, job.EventID, job.EventType, messageID, job.Body); err != nil {
+			r.log.Error("Failed to enqueue webhook job", zap.Int64("webhook_id", job.WebhookID), zap.Error(err))
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log.Error("Failed to commit webhook jobs", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// ClaimWebhookJobs returns up to limit jobs that are due and counts an attempt
+// for each. A claimed job is not handed out again for lease, so the job of a
+// dispatcher that died in the middle of a delivery is picked up later.
+func (r *chatRepository) ClaimWebhookJobs(ctx context.Context, limit int, lease time.Duration) ([]*entity.WebhookJob, error) {
+	query := `UPDATE webhook_jobs
+			  SET attempts = attempts + 1, next_attempt_at = NOW() + make_interval(secs => $2)
+			  WHERE id IN (
+			      SELECT id FROM webhook_jobs WHERE next_attempt_at <= NOW()
+			      ORDER BY next_attempt_at LIMIT $1 FOR UPDATE SKIP LOCKED
+			  )
+			  RETURNING id, webhook_id, event_id, event_type, COALESCE(message_id, 0), body, attempts`
+	rows, err := r.db.QueryContext(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		r.log.Error("Failed to claim webhook jobs", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []*entity.WebhookJob
+	for rows.Next() {
+		var job entity.WebhookJob
+		err := rows.Scan(&job.ID, &job.WebhookID, &job.EventID, &job.EventType, &job.MessageID, &job.Body, &job.Attempts)
+		if err != nil {
+			r.log.Error("Failed to scan webhook job", zap.Error(err))
+			return nil, err
+		}
+		jobs = append(jobs, &job)
+	}
+
+	return jobs, rows.Err()
+}
+
+func (r *chatRepository) RetryWebhookJob(ctx context.Context, id int64, after time.Duration) error {
+	query := `UPDATE webhook_jobs SET next_attempt_at = NOW() + make_interval(secs => $2) WHERE id = $1`
+	if _, err := r.db.ExecContext(ctx, query, id, after.Seconds()); err != nil {
+		r.log.Error("Failed to schedule webhook job", zap.Int64("job_id", id), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *chatRepository) DeleteWebhookJob(ctx context.Context, id int64) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM webhook_jobs WHERE id = $1`, id); err != nil {
+		r.log.Error("Failed to delete webhook job", zap.Int64("job_id", id), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"chat-grpc/Chat-service/internal/entity"
+	"chat-grpc/proto_gen"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (uc *ChatUseCase) AddBot(chatID int64, from, botName string) error {
+	if chatID == 0 || from == "" || botName == "" {
+		return errors.New("invalid bot parameters")
+	}
+
+	ctx := context.Background()
+	role, err := uc.repo.GetMemberRole(ctx, chatID, from)
+	if err != nil {
+		return err
+	}
+	if role != entity.AdminChatRole {
+		return errors.New("only chat admins can add bots")
+	}
+
+	isBot, err := uc.repo.IsBot(ctx, botName)
+	if err != nil {
+		return err
+	}
+	if !isBot {
+		return fmt.Errorf("%s is not a bot account", botName)
+	}
+
+	if err := uc.repo.AddMember(ctx, chatID, botName, entity.MemberChatRole); err != nil {
+		return err
+	}
+
+	return uc.deliver(&proto_gen.Message{
+		ChatId:    chatID,
+		From:      from,
+		Text:      fmt.Sprintf("%s added bot %s to the chat", from, botName),
+		Timestamp: timestamppb.Now(),
+		Payload: &proto_gen.Message_SystemEvent{SystemEvent: &proto_gen.SystemEvent{
+			Type:  "bot_added",
+			Actor: from,
+			Data:  map[string]string{"bot": botName},
+		}},
+	})
+}
+
+// GetBotChats returns the chats a bot account belongs to.
+func (uc *ChatUseCase) GetBotChats(ctx context.Context, botName string) ([]int64, error) {
+	isBot, err := uc.repo.IsBot(ctx, botName)
+	if err != nil {
+		return nil, err
+	}
+	if !isBot {
+		return nil, fmt.Errorf("%s is not a bot account", botName)
+	}
+
+	return uc.repo.GetUserChats(ctx, botName)
+}
+
+// IsChatMember tells whether the user or bot belongs to the chat.
+func (uc *ChatUseCase) IsChatMember(ctx context.Context, chatID int64, username string) (bool, error) {
+	_, err := uc.repo.GetMemberRole(ctx, chatID, username)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
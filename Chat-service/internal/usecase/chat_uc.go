@@ -2,33 +2,78 @@ package usecase
 
 import (
 	"context"
+	"database/sql"
 	"errors"
-	"time"
+	"strings"
 
-	"chat-grpc/Chat-service/internal/broker"
+	"chat-grpc/Chat-service/command"
+	"chat-grpc/Chat-service/internal/entity"
 	"chat-grpc/Chat-service/internal/repository"
+	"chat-grpc/Chat-service/moderation"
+	"chat-grpc/pkg/broker"
 	"chat-grpc/proto_gen"
-	"github.com/nats-io/nats.go"
 	"go.uber.org/zap"
-	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/proto"
 )
 
 type ChatUseCaseInterface interface {
 	Create(usernames []string) (int64, error)
 	Delete(chatID int64) error
-	SendMessage(chatID int64, from, text string, timestamp time.Time) error
+	SendMessage(msg *proto_gen.Message) error
 	GetChatHistory(ctx context.Context, chatID int64) ([]*proto_gen.Message, error)
-	Subscribe(subject string, handler func(*proto_gen.Message)) (*nats.Subscription, error)
+	Subscribe(subject string, handler func(*proto_gen.Message)) (broker.Subscription, error)
+	CreatePoll(poll *entity.Poll) (int64, error)
+	Vote(pollID int64, from string, options []int) error
+	ClosePoll(pollID int64, from string) error
+	AddBot(chatID int64, from, botName string) error
+	GetBotChats(ctx context.Context, botName string) ([]int64, error)
+	IsChatMember(ctx context.Context, chatID int64, username string) (bool, error)
+	RegisterWebhook(chatID int64, from, url string, eventTypes []string) (int64, string, error)
+	DeleteWebhook(id int64, from string) error
+	ListWebhooks(chatID int64, from string) ([]*entity.Webhook, error)
+	CreateIncomingWebhook(chatID int64, from, name string) (int64, string, error)
+	RotateIncomingWebhook(id int64, from string) (string, error)
+	RevokeIncomingWebhook(id int64, from string) error
+	AuthenticateIncomingWebhook(ctx context.Context, token string) (*entity.IncomingWebhook, error)
+	ThrottleIncomingWebhook(ctx context.Context, id int64, perMinute, burst int) error
+	PostIncomingWebhook(hook *entity.IncomingWebhook, text string, metadata map[string]string) error
+	SetModerationSettings(settings *entity.ModerationSettings, from string) error
+	ReportMessage(messageID int64, from, reason string) error
+	GetFlaggedMessages(chatID int64, from string) ([]*proto_gen.FlaggedMessage, error)
+	ReviewMessage(messageID int64, from string, remove bool) error
+	BanUser(chatID int64, from, username, reason string) error
+	UnbanUser(chatID int64, from, username string) error
+	SetRateLimits(limits *entity.RateLimits, from string) error
+	GetRateLimits(chatID int64, from string) (*entity.RateLimits, error)
+	GetUsername(ctx context.Context, userID int64) (string, error)
 }
 
 type ChatUseCase struct {
-	repo   repository.ChatRepo
-	log    *zap.Logger
-	broker broker.Broker
+	repo      repository.ChatRepo
+	log       *zap.Logger
+	broker    broker.Broker
+	hub       *hub
+	commands  *command.Registry
+	reminders *reminders
+	moderator moderation.Moderator
+
+	defaultRateLimits entity.RateLimits
+	userRateLimit     entity.UserRateLimit
 }
 
-func NewChatUseCase(repo repository.ChatRepo, log *zap.Logger, broker broker.Broker) *ChatUseCase {
-	return &ChatUseCase{repo: repo, log: log, broker: broker}
+func NewChatUseCase(repo repository.ChatRepo, log *zap.Logger, broker broker.Broker, commands *command.Registry,
+	moderator moderation.Moderator, defaultRateLimits entity.RateLimits, userRateLimit entity.UserRateLimit) *ChatUseCase {
+	return &ChatUseCase{
+		repo:              repo,
+		log:               log,
+		broker:            broker,
+		hub:               newHub(broker),
+		commands:          commands,
+		reminders:         newReminders(),
+		moderator:         moderator,
+		defaultRateLimits: defaultRateLimits,
+		userRateLimit:     userRateLimit,
+	}
 }
 
 func (uc *ChatUseCase) Create(usernames []string) (int64, error) {
@@ -47,25 +92,96 @@ func (uc *ChatUseCase) Delete(chatID int64) error {
 	return uc.repo.DeleteChat(chatID)
 }
 
-func (uc *ChatUseCase) SendMessage(chatID int64, from, text string, timestamp time.Time) error {
-	if chatID == 0 || from == "" || text == "" {
+func (uc *ChatUseCase) SendMessage(msg *proto_gen.Message) error {
+	if msg.ChatId == 0 || msg.From == "" || (msg.Text == "" && msg.Payload == nil) {
 		return errors.New("invalid message parameters")
 	}
 
-	// timestamp = time.Now().Local()
-	modText, err := uc.repo.SendMessage(chatID, from, text, timestamp)
+	ctx := context.Background()
+
+	// a retried send returns the stored message and is not published again
+	if msg.ClientMessageId != "" {
+		messageID, err := uc.repo.GetMessageIDByClientID(ctx, msg.ChatId, msg.From, msg.ClientMessageId)
+		if err == nil {
+			msg.Id = messageID
+			return nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+	}
+
+	if err := uc.checkRateLimit(ctx, msg.ChatId, msg.From); err != nil {
+		return err
+	}
+
+	// integrations post text as is, they must not run commands as their creator
+	_, integration := msg.Metadata[IntegrationMetadataKey]
+	if uc.commands != nil && msg.Payload == nil && !integration {
+		if call, ok := command.Parse(msg.ChatId, msg.From, msg.Text); ok {
+			return uc.runCommand(msg, call)
+		}
+	}
+
+	// "//text" is an escaped slash, not a command
+	if strings.HasPrefix(msg.Text, command.Prefix+command.Prefix) {
+		msg.Text = strings.TrimPrefix(msg.Text, command.Prefix)
+	}
+
+	return uc.deliver(msg)
+}
+
+// deliver stores the message. It is published to the chat subscribers by the
+// outbox relay once the transaction commits, the preview of a link in it
+// follows as an update.
+func (uc *ChatUseCase) deliver(msg *proto_gen.Message) error {
+	ctx := context.Background()
+
+	muted, err := uc.repo.IsMuted(ctx, msg.ChatId, msg.From)
+	if err != nil {
+		return err
+	}
+	if muted {
+		return errors.New("user is muted in this chat")
+	}
+
+	banned, err := uc.repo.IsBanned(ctx, msg.ChatId, msg.From)
+	if err != nil {
+		return err
+	}
+	if banned {
+		return repository.ErrUserBanned
+	}
+
+	flagReason, err := uc.moderate(ctx, msg)
 	if err != nil {
 		return err
 	}
 
-	msg := &proto_gen.Message{
-		ChatId:    chatID,
-		From:      from,
-		Text:      modText,
-		Timestamp: timestamppb.New(timestamp),
+	msg.Kind = messageKind(msg)
+
+	messageID, created, err := uc.repo.SendMessage(msg)
+	if err != nil {
+		return err
+	}
+	msg.Id = messageID
+
+	// a concurrent retry with the same client id has already stored and moderated it
+	if !created {
+		return nil
+	}
+
+	if flagReason != "" {
+		if err := uc.repo.FlagMessage(ctx, messageID, flagReason); err != nil {
+			uc.log.Error("Failed to flag message", zap.Int64("message_id", messageID), zap.Error(err))
+		}
+	}
+
+	if msg.Payload == nil && findURL(msg.Text) != "" {
+		go uc.addLinkPreview(proto.Clone(msg).(*proto_gen.Message))
 	}
 
-	return uc.broker.Publish(msg)
+	return nil
 }
 
 func (uc *ChatUseCase) GetChatHistory(ctx context.Context, chatID int64) ([]*proto_gen.Message, error) {
@@ -73,9 +189,42 @@ func (uc *ChatUseCase) GetChatHistory(ctx context.Context, chatID int64) ([]*pro
 		return nil, errors.New("invalid chat ID")
 	}
 
-	return uc.repo.GetMessagesByChatID(ctx, chatID)
+	messages, err := uc.repo.GetMessagesByChatID(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.hydratePolls(ctx, messages); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+func messageKind(msg *proto_gen.Message) proto_gen.MessageKind {
+	switch msg.Payload.(type) {
+	case *proto_gen.Message_SystemEvent:
+		return proto_gen.MessageKind_SystemKind
+	case *proto_gen.Message_Attachment:
+		return proto_gen.MessageKind_AttachmentKind
+	case *proto_gen.Message_LinkPreview:
+		return proto_gen.MessageKind_LinkPreviewKind
+	case *proto_gen.Message_Poll:
+		return proto_gen.MessageKind_PollKind
+	case *proto_gen.Message_CodeSnippet:
+		return proto_gen.MessageKind_CodeSnippetKind
+	default:
+		return proto_gen.MessageKind_TextKind
+	}
+}
+
+// GetUsername returns the name of the user a token was issued to.
+func (uc *ChatUseCase) GetUsername(ctx context.Context, userID int64) (string, error) {
+	return uc.repo.GetUsername(ctx, userID)
 }
 
-func (uc *ChatUseCase) Subscribe(subject string, handler func(*proto_gen.Message)) (*nats.Subscription, error) {
-	return uc.broker.Subscribe(subject, handler)
+// Subscribe adds a local listener of the subject. All listeners of a subject
+// share one broker subscription and must not modify the messages they get.
+func (uc *ChatUseCase) Subscribe(subject string, handler func(*proto_gen.Message)) (broker.Subscription, error) {
+	return uc.hub.subscribe(subject, handler)
 }
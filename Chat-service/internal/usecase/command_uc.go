@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"chat-grpc/Chat-service/command"
+	"chat-grpc/Chat-service/internal/entity"
+	"chat-grpc/Chat-service/internal/repository"
+	"chat-grpc/proto_gen"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// maxPendingReminders is how many reminders a user may wait for at once.
+const maxPendingReminders = 10
+
+// reminders counts the pending reminders of every user.
+type reminders struct {
+	mu      sync.Mutex
+	pending map[string]int
+}
+
+func newReminders() *reminders {
+	return &reminders{pending: make(map[string]int)}
+}
+
+func (r *reminders) add(username string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.pending[username] >= maxPendingReminders {
+		return false
+	}
+	r.pending[username]++
+
+	return true
+}
+
+func (r *reminders) done(username string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.pending[username]--; r.pending[username] <= 0 {
+		delete(r.pending, username)
+	}
+}
+
+// runCommand runs the command at most once per client message id: a retried
+// send of a command that was already run does nothing. A command that fails, or
+// whose reply is not delivered, is released, so its retry runs it again.
+func (uc *ChatUseCase) runCommand(msg *proto_gen.Message, call *command.Call) error {
+	ctx := context.Background()
+
+	if msg.ClientMessageId != "" {
+		first, err := uc.repo.ClaimCommand(ctx, msg.ChatId, msg.From, msg.ClientMessageId)
+		if err != nil {
+			return err
+		}
+		if !first {
+			return nil
+		}
+	}
+
+	reply, err := uc.commands.Dispatch(ctx, &commandBackend{uc: uc}, call)
+	var cmdErr *command.Error
+	if errors.As(err, &cmdErr) {
+		reply = &command.Reply{Text: cmdErr.Message, Ephemeral: true}
+	} else if err != nil {
+		uc.log.Error("Command failed", zap.String("command", call.Name), zap.Int64("chat_id", call.ChatID), zap.Error(err))
+		uc.releaseCommand(ctx, msg)
+		return err
+	}
+
+	if reply == nil {
+		return nil
+	}
+
+	out := &proto_gen.Message{
+		ChatId:    msg.ChatId,
+		From:      msg.From,
+		Text:      reply.Text,
+		Timestamp: msg.Timestamp,
+		Metadata:  map[string]string{"command": call.Name},
+	}
+	if reply.Event != nil {
+		out.Payload = &proto_gen.Message_SystemEvent{SystemEvent: reply.Event}
+	}
+
+	if reply.Ephemeral {
+		err = uc.publishEphemeral(out, msg.From)
+	} else {
+		err = uc.deliver(out)
+	}
+	if err != nil {
+		uc.releaseCommand(ctx, msg)
+	}
+
+	return err
+}
+
+func (uc *ChatUseCase) releaseCommand(ctx context.Context, msg *proto_gen.Message) {
+	if msg.ClientMessageId == "" {
+		return
+	}
+
+	if err := uc.repo.ReleaseCommand(ctx, msg.ChatId, msg.From, msg.ClientMessageId); err != nil {
+		uc.log.Error("Failed to release command", zap.Int64("chat_id", msg.ChatId), zap.String("client_id", msg.ClientMessageId), zap.Error(err))
+	}
+}
+
+// publishEphemeral delivers a message to the recipient's streams only, without
+// storing it. Streams pick their messages by the user of their token, so the
+// recipient must be the authenticated caller.
+func (uc *ChatUseCase) publishEphemeral(msg *proto_gen.Message, recipient string) error {
+	msg.Recipient = recipient
+	msg.Kind = messageKind(msg)
+
+	return uc.broker.Publish(msg)
+}
+
+type commandBackend struct {
+	uc *ChatUseCase
+}
+
+func (b *commandBackend) Permission(ctx context.Context, chatID int64, username string) (command.Permission, error) {
+	role, err := b.uc.repo.GetMemberRole(ctx, chatID, username)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, command.Errorf("you are not a member of this chat")
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if role == entity.AdminChatRole {
+		return command.AdminPermission, nil
+	}
+
+	return command.MemberPermission, nil
+}
+
+func (b *commandBackend) SetTopic(ctx context.Context, chatID int64, topic string) error {
+	return b.uc.repo.SetTopic(ctx, chatID, topic)
+}
+
+func (b *commandBackend) Invite(ctx context.Context, chatID int64, username string) error {
+	err := b.uc.repo.AddMember(ctx, chatID, username, entity.MemberChatRole)
+	if errors.Is(err, sql.ErrNoRows) {
+		return command.Errorf("user %s not found", username)
+	}
+	if errors.Is(err, repository.ErrUserBanned) {
+		return command.Errorf("%s is banned from this chat", username)
+	}
+
+	return err
+}
+
+func (b *commandBackend) Mute(ctx context.Context, chatID int64, username string, until time.Time) error {
+	err := b.uc.repo.MuteMember(ctx, chatID, username, until)
+	if errors.Is(err, sql.ErrNoRows) {
+		return command.Errorf("%s is not a member of this chat", username)
+	}
+
+	return err
+}
+
+// Remind is kept in memory only: pending reminders are lost on restart. That is
+// why the delay is bounded and a user may only have a few reminders pending.
+func (b *commandBackend) Remind(chatID int64, username string, after time.Duration, text string) error {
+	if !b.uc.reminders.add(username) {
+		return command.Errorf("you already have %d pending reminders", maxPendingReminders)
+	}
+
+	time.AfterFunc(after, func() {
+		defer b.uc.reminders.done(username)
+
+		msg := &proto_gen.Message{
+			ChatId:    chatID,
+			From:      username,
+			Text:      "Reminder: " + text,
+			Timestamp: timestamppb.Now(),
+			Metadata:  map[string]string{"command": "remind"},
+		}
+
+		if err := b.uc.publishEphemeral(msg, username); err != nil {
+			b.uc.log.Error("Failed to deliver reminder", zap.Int64("chat_id", chatID), zap.String("username", username), zap.Error(err))
+		}
+	})
+
+	return nil
+}
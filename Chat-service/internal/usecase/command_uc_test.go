@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"chat-grpc/Chat-service/command"
+	"chat-grpc/Chat-service/internal/entity"
+	"chat-grpc/proto_gen"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetriedCommandRunsAgainAfterFailure(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addMember(7, "alice", entity.MemberChatRole)
+
+	runs := 0
+	commands := command.NewRegistry()
+	require.NoError(t, commands.Register(&command.Command{
+		Name: "flaky",
+		Handler: func(ctx context.Context, b command.Backend, call *command.Call) (*command.Reply, error) {
+			runs++
+			if runs == 1 {
+				return nil, errors.New("database is down")
+			}
+			return &command.Reply{Text: "done", Ephemeral: true}, nil
+		},
+	}))
+	uc, _ := newTestUseCase(repo, commands)
+
+	send := func() error {
+		return uc.SendMessage(&proto_gen.Message{ChatId: 7, From: "alice", Text: "/flaky", ClientMessageId: "c1"})
+	}
+
+	require.Error(t, send())
+	require.NoError(t, send())
+	require.Equal(t, 2, runs)
+
+	// the command has run, a further retry does nothing
+	require.NoError(t, send())
+	require.Equal(t, 2, runs)
+}
+
+func TestUserFacingCommandErrorIsNotRetried(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addMember(7, "alice", entity.MemberChatRole)
+	uc, _ := newTestUseCase(repo, command.NewDefaultRegistry())
+
+	// an unknown command is answered, so the claim is kept
+	msg := &proto_gen.Message{ChatId: 7, From: "alice", Text: "/nope", ClientMessageId: "c1"}
+	require.NoError(t, uc.SendMessage(msg))
+
+	first, err := repo.ClaimCommand(context.Background(), 7, "alice", "c1")
+	require.NoError(t, err)
+	require.False(t, first)
+}
@@ -0,0 +1,222 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"chat-grpc/Chat-service/command"
+	"chat-grpc/Chat-service/internal/entity"
+	"chat-grpc/Chat-service/internal/repository"
+	"chat-grpc/pkg/broker"
+	"chat-grpc/proto_gen"
+	"go.uber.org/zap"
+)
+
+// fakeRepo keeps chat members, polls, moderation settings and command claims
+// in memory. Every chat uses the default rate limits, whose buckets never
+// refill, and nobody is muted or banned. Messages that go through the outbox are
+// collected in published instead.
+type fakeRepo struct {
+	repository.ChatRepo
+
+	mu         sync.Mutex
+	members    map[int64]map[string]entity.ChatRole
+	commands   map[string]bool
+	polls      map[int64]*entity.Poll
+	moderation map[int64]*entity.ModerationSettings
+	published  []*proto_gen.Message
+	tokens     map[string]float64
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		members:    make(map[int64]map[string]entity.ChatRole),
+		commands:   make(map[string]bool),
+		polls:      make(map[int64]*entity.Poll),
+		moderation: make(map[int64]*entity.ModerationSettings),
+		tokens:     make(map[string]float64),
+	}
+}
+
+func (r *fakeRepo) addMember(chatID int64, username string, role entity.ChatRole) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.members[chatID] == nil {
+		r.members[chatID] = make(map[string]entity.ChatRole)
+	}
+	r.members[chatID][username] = role
+}
+
+func (r *fakeRepo) GetMemberRole(ctx context.Context, chatID int64, username string) (entity.ChatRole, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	role, ok := r.members[chatID][username]
+	if !ok {
+		return 0, sql.ErrNoRows
+	}
+
+	return role, nil
+}
+
+func (r *fakeRepo) GetMessageIDByClientID(ctx context.Context, chatID int64, username, clientID string) (int64, error) {
+	return 0, sql.ErrNoRows
+}
+
+func (r *fakeRepo) GetRateLimits(ctx context.Context, chatID int64) (*entity.RateLimits, error) {
+	return nil, sql.ErrNoRows
+}
+
+// TakeTokens takes a token from every bucket or from none of them.
+func (r *fakeRepo) TakeTokens(ctx context.Context, buckets []entity.TokenBucket) (time.Duration, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, b := range buckets {
+		if tokens, ok := r.tokens[b.Key]; ok && tokens < 1 {
+			return time.Minute, nil
+		}
+	}
+	for _, b := range buckets {
+		if _, ok := r.tokens[b.Key]; !ok {
+			r.tokens[b.Key] = b.Burst
+		}
+		r.tokens[b.Key]--
+	}
+
+	return 0, nil
+}
+
+func (r *fakeRepo) IsMuted(ctx context.Context, chatID int64, username string) (bool, error) {
+	return false, nil
+}
+
+func (r *fakeRepo) IsBanned(ctx context.Context, chatID int64, username string) (bool, error) {
+	return false, nil
+}
+
+func (r *fakeRepo) GetModerationSettings(ctx context.Context, chatID int64) (*entity.ModerationSettings, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if settings, ok := r.moderation[chatID]; ok {
+		return settings, nil
+	}
+
+	return &entity.ModerationSettings{ChatID: chatID, Action: entity.FlagModeration}, nil
+}
+
+func (r *fakeRepo) SendMessage(msg *proto_gen.Message) (int64, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.published = append(r.published, msg)
+	return int64(len(r.published)), true, nil
+}
+
+func commandKey(chatID int64, username, clientID string) string {
+	return fmt.Sprintf("%d:%s:%s", chatID, username, clientID)
+}
+
+func (r *fakeRepo) ClaimCommand(ctx context.Context, chatID int64, username, clientID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := commandKey(chatID, username, clientID)
+	if r.commands[key] {
+		return false, nil
+	}
+	r.commands[key] = true
+
+	return true, nil
+}
+
+func (r *fakeRepo) ReleaseCommand(ctx context.Context, chatID int64, username, clientID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.commands, commandKey(chatID, username, clientID))
+	return nil
+}
+
+func (r *fakeRepo) CreatePoll(poll *entity.Poll, msg *proto_gen.Message) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *poll
+	stored.ID = int64(len(r.polls) + 1)
+	r.polls[stored.ID] = &stored
+	r.published = append(r.published, msg)
+
+	return stored.ID, nil
+}
+
+func (r *fakeRepo) GetPoll(ctx context.Context, pollID int64) (*entity.Poll, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	poll, ok := r.polls[pollID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	copied := *poll
+	copied.Votes = append([]entity.PollVote(nil), poll.Votes...)
+
+	return &copied, nil
+}
+
+func (r *fakeRepo) Vote(pollID int64, username string, options []int, tally func(*entity.Poll) *proto_gen.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	poll := r.polls[pollID]
+	if poll.Closed {
+		return errors.New("poll is closed")
+	}
+
+	votes := poll.Votes[:0]
+	for _, vote := range poll.Votes {
+		if vote.Username != username {
+			votes = append(votes, vote)
+		}
+	}
+	for _, option := range options {
+		votes = append(votes, entity.PollVote{Username: username, OptionIndex: option})
+	}
+	poll.Votes = votes
+	r.published = append(r.published, tally(poll))
+
+	return nil
+}
+
+func (r *fakeRepo) ClosePoll(pollID int64, tally func(*entity.Poll) *proto_gen.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	poll := r.polls[pollID]
+	poll.Closed = true
+	r.published = append(r.published, tally(poll))
+
+	return nil
+}
+
+// lastPublished returns the last message that went through the outbox.
+func (r *fakeRepo) lastPublished() *proto_gen.Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.published[len(r.published)-1]
+}
+
+// newTestUseCase builds a use case on the memory broker without moderation.
+func newTestUseCase(repo *fakeRepo, commands *command.Registry) (*ChatUseCase, broker.Broker) {
+	b := broker.NewMemoryBroker("test", zap.NewNop())
+	uc := NewChatUseCase(repo, zap.NewNop(), b, commands, nil, entity.RateLimits{}, entity.UserRateLimit{})
+
+	return uc, b
+}
@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"chat-grpc/pkg/broker"
+	"chat-grpc/proto_gen"
+)
+
+// hub shares one broker subscription per subject between all local listeners,
+// so an event is received and decoded once no matter how many members of the
+// chat are connected to this instance. Listeners get the same message and must
+// not modify it.
+type hub struct {
+	broker broker.Broker
+
+	// mu guards topics and their listeners and is never held across a broker
+	// call, so a slow subscribe to one chat does not hold up the others
+	mu     sync.Mutex
+	topics map[string]*hubTopic
+}
+
+type hubTopic struct {
+	// mu serialises the broker calls of the subject
+	mu  sync.Mutex
+	sub broker.Subscription
+	// listeners is replaced on every change, so dispatch can use it without copying
+	listeners []*hubListener
+}
+
+type hubListener struct {
+	hub     *hub
+	subject string
+	topic   *hubTopic
+	handler func(*proto_gen.Message)
+	active  atomic.Bool
+}
+
+func newHub(b broker.Broker) *hub {
+	return &hub{broker: b, topics: make(map[string]*hubTopic)}
+}
+
+func (h *hub) subscribe(subject string, handler func(*proto_gen.Message)) (broker.Subscription, error) {
+	h.mu.Lock()
+	topic, ok := h.topics[subject]
+	if !ok {
+		topic = &hubTopic{}
+		h.topics[subject] = topic
+	}
+	l := &hubListener{hub: h, subject: subject, topic: topic, handler: handler}
+	l.active.Store(true)
+	topic.listeners = append(append([]*hubListener{}, topic.listeners...), l)
+	h.mu.Unlock()
+
+	topic.mu.Lock()
+	defer topic.mu.Unlock()
+
+	// the topic stays in use while l is a listener, the first listener to get
+	// here subscribes for all of them
+	if topic.sub != nil {
+		return l, nil
+	}
+	sub, err := h.broker.Subscribe(subject, func(event *proto_gen.ChatEvent) {
+		h.dispatch(topic, event.GetMessage())
+	})
+	if err != nil {
+		l.active.Store(false)
+		h.remove(l)
+		return nil, err
+	}
+	topic.sub = sub
+
+	return l, nil
+}
+
+// dispatch hands the message to the listeners of the topic the event came for,
+// a topic replaced after its last listener left is never given its events.
+func (h *hub) dispatch(topic *hubTopic, msg *proto_gen.Message) {
+	h.mu.Lock()
+	listeners := topic.listeners
+	h.mu.Unlock()
+
+	for _, l := range listeners {
+		if l.active.Load() {
+			l.handler(msg)
+		}
+	}
+}
+
+// remove takes the listener off its topic and reports whether it was the last
+// one, the topic is forgotten then and the next listener starts a new one.
+func (h *hub) remove(l *hubListener) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	topic := l.topic
+	listeners := make([]*hubListener, 0, len(topic.listeners))
+	for _, other := range topic.listeners {
+		if other != l {
+			listeners = append(listeners, other)
+		}
+	}
+	topic.listeners = listeners
+
+	if len(listeners) > 0 {
+		return false
+	}
+	if h.topics[l.subject] == topic {
+		delete(h.topics, l.subject)
+	}
+
+	return true
+}
+
+// Unsubscribe removes the listener. The broker subscription is dropped together
+// with the last listener of the subject.
+func (l *hubListener) Unsubscribe() error {
+	if !l.active.CompareAndSwap(true, false) {
+		return nil
+	}
+	if !l.hub.remove(l) {
+		return nil
+	}
+
+	topic := l.topic
+	topic.mu.Lock()
+	defer topic.mu.Unlock()
+
+	if topic.sub == nil {
+		return nil
+	}
+	err := topic.sub.Unsubscribe()
+	topic.sub = nil
+
+	return err
+}
@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"fmt"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"chat-grpc/pkg/broker"
+	"chat-grpc/proto_gen"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// countingBroker counts the broker subscriptions that are open.
+type countingBroker struct {
+	broker.Broker
+	open atomic.Int64
+}
+
+type countedSubscription struct {
+	broker.Subscription
+	broker *countingBroker
+}
+
+func (b *countingBroker) Subscribe(subject string, handler func(*proto_gen.ChatEvent)) (broker.Subscription, error) {
+	sub, err := b.Broker.Subscribe(subject, handler)
+	if err != nil {
+		return nil, err
+	}
+	b.open.Add(1)
+
+	return &countedSubscription{Subscription: sub, broker: b}, nil
+}
+
+func (s *countedSubscription) Unsubscribe() error {
+	s.broker.open.Add(-1)
+	return s.Subscription.Unsubscribe()
+}
+
+func TestHubSharesSubscriptionUntilLastListenerLeaves(t *testing.T) {
+	br := &countingBroker{Broker: broker.NewMemoryBroker("test", zap.NewNop())}
+	defer br.Close()
+	h := newHub(br)
+
+	received := make(chan *proto_gen.Message, 10)
+	first, err := h.subscribe(broker.Subject(1), func(msg *proto_gen.Message) { received <- msg })
+	require.NoError(t, err)
+	second, err := h.subscribe(broker.Subject(1), func(*proto_gen.Message) {})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, br.open.Load())
+
+	require.NoError(t, first.Unsubscribe())
+	require.NoError(t, first.Unsubscribe(), "a second Unsubscribe does nothing")
+	require.EqualValues(t, 1, br.open.Load())
+
+	require.NoError(t, second.Unsubscribe())
+	require.EqualValues(t, 0, br.open.Load())
+	require.Empty(t, h.topics)
+
+	require.NoError(t, br.Publish(&proto_gen.Message{ChatId: 1, Text: "nobody listens"}))
+	require.Never(t, func() bool { return len(received) > 0 }, 50*time.Millisecond, 5*time.Millisecond)
+
+	// a new listener opens a new subscription
+	third, err := h.subscribe(broker.Subject(1), func(msg *proto_gen.Message) { received <- msg })
+	require.NoError(t, err)
+	require.EqualValues(t, 1, br.open.Load())
+	require.NoError(t, br.Publish(&proto_gen.Message{ChatId: 1, Text: "hello"}))
+	require.Equal(t, "hello", (<-received).Text)
+	require.NoError(t, third.Unsubscribe())
+}
+
+func TestHubListenerUnsubscribedDuringDispatchStopsReceiving(t *testing.T) {
+	br := broker.NewMemoryBroker("test", zap.NewNop())
+	defer br.Close()
+	h := newHub(br)
+
+	var late broker.Subscription
+	var lateReceived atomic.Int64
+	received := make(chan *proto_gen.Message, 10)
+	_, err := h.subscribe(broker.Subject(1), func(msg *proto_gen.Message) {
+		// the first listener drops the second while the message is dispatched
+		require.NoError(t, late.Unsubscribe())
+		received <- msg
+	})
+	require.NoError(t, err)
+	late, err = h.subscribe(broker.Subject(1), func(*proto_gen.Message) { lateReceived.Add(1) })
+	require.NoError(t, err)
+
+	require.NoError(t, br.Publish(&proto_gen.Message{ChatId: 1, Text: "first"}))
+	require.NoError(t, br.Publish(&proto_gen.Message{ChatId: 1, Text: "second"}))
+	require.Equal(t, "first", (<-received).Text)
+	require.Equal(t, "second", (<-received).Text)
+	require.Zero(t, lateReceived.Load())
+}
+
+func TestHubListenerUnsubscribesItselfDuringDispatch(t *testing.T) {
+	br := broker.NewMemoryBroker("test", zap.NewNop())
+	defer br.Close()
+	h := newHub(br)
+
+	var sub broker.Subscription
+	done := make(chan struct{})
+	sub, err := h.subscribe(broker.Subject(1), func(*proto_gen.Message) {
+		require.NoError(t, sub.Unsubscribe())
+		close(done)
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, br.Publish(&proto_gen.Message{ChatId: 1, Text: "bye"}))
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the listener did not get the message")
+	}
+	require.Empty(t, h.topics)
+}
+
+// publishWindow bounds the events not yet handled by every stream, so the
+// queues of the memory broker never overflow and drop events.
+const publishWindow = 1000
+
+// BenchmarkHub publishes into a chat with the given number of connected streams,
+// once through the hub and once with a broker subscription per stream.
+func BenchmarkHub(b *testing.B) {
+	subscribers := map[string]func(broker.Broker) func(string, func(*proto_gen.Message)) (broker.Subscription, error){
+		"hub": func(br broker.Broker) func(string, func(*proto_gen.Message)) (broker.Subscription, error) {
+			return newHub(br).subscribe
+		},
+		"per-stream": func(br broker.Broker) func(string, func(*proto_gen.Message)) (broker.Subscription, error) {
+			return func(subject string, handler func(*proto_gen.Message)) (broker.Subscription, error) {
+				return br.Subscribe(subject, func(event *proto_gen.ChatEvent) {
+					handler(event.GetMessage())
+				})
+			}
+		},
+	}
+
+	for _, streams := range []int{1, 10, 100} {
+		for _, name := range []string{"hub", "per-stream"} {
+			b.Run(fmt.Sprintf("%s/streams=%d", name, streams), func(b *testing.B) {
+				br := broker.NewMemoryBroker("bench", zap.NewNop())
+				defer br.Close()
+				subscribe := subscribers[name](br)
+
+				var handled atomic.Int64
+				handler := func(*proto_gen.Message) {
+					handled.Add(1)
+				}
+				waitHandled := func(published int) {
+					for handled.Load() < int64(published*streams) {
+						runtime.Gosched()
+					}
+				}
+				for range streams {
+					if _, err := subscribe(broker.Subject(1), handler); err != nil {
+						b.Fatal(err)
+					}
+				}
+
+				msg := &proto_gen.Message{Id: 1, ChatId: 1, From: "alice", Text: "hello, everyone in the chat"}
+
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := range b.N {
+					if err := br.Publish(msg); err != nil {
+						b.Fatal(err)
+					}
+					if i >= publishWindow {
+						waitHandled(i - publishWindow)
+					}
+				}
+				waitHandled(b.N)
+			})
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"chat-grpc/Chat-service/internal/entity"
+	"chat-grpc/proto_gen"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// IntegrationMetadataKey marks messages posted by an incoming webhook. The value
+// is the webhook name. Clients cannot set it themselves.
+const IntegrationMetadataKey = "integration"
+
+// ErrIncomingWebhookForbidden is returned by PostIncomingWebhook when the creator
+// of the webhook has left the chat or is no longer its admin.
+var ErrIncomingWebhookForbidden = errors.New("incoming webhook creator is no longer a chat admin")
+
+const (
+	incomingTokenPrefix = "whk_"
+	incomingTokenBytes  = 32
+)
+
+func generateIncomingToken() (string, error) {
+	b := make([]byte, incomingTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return incomingTokenPrefix + hex.EncodeToString(b), nil
+}
+
+// CreateIncomingWebhook returns the webhook id and its token. The token is shown only once.
+func (uc *ChatUseCase) CreateIncomingWebhook(chatID int64, from, name string) (int64, string, error) {
+	if chatID == 0 || from == "" || name == "" {
+		return 0, "", errors.New("invalid incoming webhook parameters")
+	}
+
+	ctx := context.Background()
+	if err := uc.requireAdmin(ctx, chatID, from); err != nil {
+		return 0, "", err
+	}
+
+	token, err := generateIncomingToken()
+	if err != nil {
+		return 0, "", err
+	}
+
+	id, err := uc.repo.CreateIncomingWebhook(ctx, &entity.IncomingWebhook{ChatID: chatID, Creator: from, Name: name}, token)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return id, token, nil
+}
+
+func (uc *ChatUseCase) RotateIncomingWebhook(id int64, from string) (string, error) {
+	ctx := context.Background()
+
+	hook, err := uc.repo.GetIncomingWebhook(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if err := uc.requireAdmin(ctx, hook.ChatID, from); err != nil {
+		return "", err
+	}
+
+	token, err := generateIncomingToken()
+	if err != nil {
+		return "", err
+	}
+
+	if err := uc.repo.RotateIncomingWebhookToken(ctx, id, token); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (uc *ChatUseCase) RevokeIncomingWebhook(id int64, from string) error {
+	ctx := context.Background()
+
+	hook, err := uc.repo.GetIncomingWebhook(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := uc.requireAdmin(ctx, hook.ChatID, from); err != nil {
+		return err
+	}
+
+	return uc.repo.RevokeIncomingWebhook(ctx, id)
+}
+
+func (uc *ChatUseCase) AuthenticateIncomingWebhook(ctx context.Context, token string) (*entity.IncomingWebhook, error) {
+	if token == "" {
+		return nil, errors.New("empty webhook token")
+	}
+
+	hook, err := uc.repo.GetIncomingWebhookByToken(ctx, token)
+	if err != nil {
+		return nil, errors.New("invalid webhook token")
+	}
+
+	return hook, nil
+}
+
+// ThrottleIncomingWebhook takes a token from the webhook's bucket. The bucket is
+// stored with the chat rate limits, so the limit is shared by all replicas and
+// pruned with them once it refills. A non-positive perMinute disables the limit.
+func (uc *ChatUseCase) ThrottleIncomingWebhook(ctx context.Context, id int64, perMinute, burst int) error {
+	if perMinute <= 0 {
+		return nil
+	}
+
+	wait, err := uc.repo.TakeTokens(ctx, []entity.TokenBucket{{
+		Key:   fmt.Sprintf("incoming_webhook:%d", id),
+		Rate:  float64(perMinute) / 60,
+		Burst: float64(max(burst, 1)),
+	}})
+	if err != nil {
+		return err
+	}
+	if wait > 0 {
+		return &RateLimitError{RetryAfter: wait}
+	}
+
+	return nil
+}
+
+// PostIncomingWebhook posts the text into the webhook's chat on behalf of its creator.
+// The creator must still be an admin of the chat: the token does not outlive the
+// role it was created with.
+func (uc *ChatUseCase) PostIncomingWebhook(hook *entity.IncomingWebhook, text string, metadata map[string]string) error {
+	role, err := uc.repo.GetMemberRole(context.Background(), hook.ChatID, hook.Creator)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && role != entity.AdminChatRole) {
+		uc.log.Warn("Incoming webhook creator is no longer a chat admin", zap.Int64("webhook_id", hook.ID),
+			zap.Int64("chat_id", hook.ChatID), zap.String("creator", hook.Creator))
+		return ErrIncomingWebhookForbidden
+	}
+	if err != nil {
+		return err
+	}
+
+	if metadata == nil {
+		metadata = make(map[string]string)
+	}
+	metadata[IntegrationMetadataKey] = hook.Name
+
+	msg := &proto_gen.Message{
+		ChatId:    hook.ChatID,
+		From:      hook.Creator,
+		Text:      text,
+		Timestamp: timestamppb.Now(),
+		Metadata:  metadata,
+	}
+	if err := uc.SendMessage(msg); err != nil {
+		return err
+	}
+
+	uc.log.Info("Incoming webhook message posted", zap.Int64("webhook_id", hook.ID), zap.Int64("chat_id", hook.ChatID))
+	return nil
+}
@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"chat-grpc/pkg/safehttp"
+	"chat-grpc/proto_gen"
+	"github.com/otiai10/opengraph/v2"
+	"go.uber.org/zap"
+)
+
+// linkPreviewTimeout bounds the fetch of a page. The message is sent without
+// waiting for it.
+const linkPreviewTimeout = 5 * time.Second
+
+// linkPreviewClient does not fetch pages of the internal network, links are
+// posted by users, bots and integrations alike.
+var linkPreviewClient = safehttp.NewClient(linkPreviewTimeout)
+
+// addLinkPreview fetches the preview of the first link of a stored message and
+// sends it to the chat as an update of the message. It changes msg, the caller
+// passes a copy.
+func (uc *ChatUseCase) addLinkPreview(msg *proto_gen.Message) {
+	url := findURL(msg.Text)
+	if url == "" {
+		return
+	}
+
+	preview := uc.fetchLinkPreview(url)
+	if preview == nil {
+		return
+	}
+
+	msg.Payload = &proto_gen.Message_LinkPreview{LinkPreview: preview}
+	msg.Kind = messageKind(msg)
+
+	if err := uc.repo.SetLinkPreview(context.Background(), msg); err != nil {
+		uc.log.Error("Failed to save link preview", zap.Int64("message_id", msg.Id), zap.Error(err))
+	}
+}
+
+func (uc *ChatUseCase) fetchLinkPreview(url string) *proto_gen.LinkPreview {
+	ctx, cancel := context.WithTimeout(context.Background(), linkPreviewTimeout)
+	defer cancel()
+
+	ogp, err := opengraph.Fetch(url, opengraph.Intent{Context: ctx, HTTPClient: linkPreviewClient})
+	if err != nil {
+		uc.log.Warn("Failed to fetch link preview", zap.String("url", url), zap.Error(err))
+		return nil
+	}
+
+	preview := &proto_gen.LinkPreview{
+		Url:         ogp.URL,
+		Title:       ogp.Title,
+		Description: ogp.Description,
+		SiteName:    ogp.SiteName,
+	}
+	if preview.Url == "" {
+		preview.Url = url
+	}
+	if len(ogp.Image) > 0 {
+		preview.ImageUrl = ogp.Image[0].URL
+	}
+
+	return preview
+}
+
+func findURL(text string) string {
+	for _, word := range strings.Fields(text) {
+		if strings.HasPrefix(word, "https://") {
+			return word
+		}
+	}
+
+	return ""
+}
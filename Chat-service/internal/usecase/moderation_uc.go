@@ -0,0 +1,190 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"strconv"
+
+	"chat-grpc/Chat-service/internal/entity"
+	"chat-grpc/Chat-service/moderation"
+	"chat-grpc/proto_gen"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var (
+	ErrMessageBlocked = errors.New("message blocked by moderation")
+	// ErrModerationUnavailable is returned instead of sending a message unchecked
+	// into a chat that blocks what moderation finds.
+	ErrModerationUnavailable = errors.New("moderation is unavailable, try again later")
+)
+
+var moderationFailures = expvar.NewInt("moderation_check_failures")
+
+// moderate applies the chat's moderation settings to the text of the message.
+// Masking rewrites msg.Text in place; the returned reason is non-empty when the
+// message has to be flagged once it is stored.
+func (uc *ChatUseCase) moderate(ctx context.Context, msg *proto_gen.Message) (string, error) {
+	if uc.moderator == nil || msg.Text == "" || msg.GetSystemEvent() != nil {
+		return "", nil
+	}
+
+	settings, err := uc.repo.GetModerationSettings(ctx, msg.ChatId)
+	if err != nil {
+		return "", err
+	}
+	if settings.Action == entity.OffModeration {
+		return "", nil
+	}
+
+	rules := moderation.Rules{Words: settings.Words, Patterns: settings.Patterns, BlockedDomains: settings.BlockedDomains}
+	matches, err := uc.moderator.Check(ctx, msg.ChatId, msg.Text, rules)
+	if err != nil {
+		moderationFailures.Add(1)
+		uc.log.Error("Moderation check failed", zap.Int64("chat_id", msg.ChatId), zap.Error(err))
+		// a broken moderator must not stop the chat, unless the chat relies on it
+		// to keep messages out
+		if settings.Action == entity.BlockModeration {
+			return "", ErrModerationUnavailable
+		}
+		return "", nil
+	}
+	if len(matches) == 0 {
+		return "", nil
+	}
+
+	reason := moderation.Reasons(matches)
+	switch settings.Action {
+	case entity.BlockModeration:
+		uc.log.Info("Message blocked", zap.Int64("chat_id", msg.ChatId), zap.String("from", msg.From), zap.String("reason", reason))
+		notice := &proto_gen.Message{
+			ChatId:    msg.ChatId,
+			From:      msg.From,
+			Text:      fmt.Sprintf("Your message was blocked by moderation (%s)", reason),
+			Timestamp: timestamppb.Now(),
+		}
+		if err := uc.publishEphemeral(notice, msg.From); err != nil {
+			uc.log.Warn("Failed to notify about blocked message", zap.Error(err))
+		}
+		return "", ErrMessageBlocked
+	case entity.MaskModeration:
+		msg.Text = moderation.Mask(msg.Text, matches)
+		return "", nil
+	default:
+		return reason, nil
+	}
+}
+
+func (uc *ChatUseCase) SetModerationSettings(settings *entity.ModerationSettings, from string) error {
+	if err := moderation.ValidatePatterns(settings.Patterns); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if err := uc.requireAdmin(ctx, settings.ChatID, from); err != nil {
+		return err
+	}
+
+	return uc.repo.SetModerationSettings(ctx, settings)
+}
+
+func (uc *ChatUseCase) ReportMessage(messageID int64, from, reason string) error {
+	if messageID == 0 || from == "" {
+		return errors.New("invalid report parameters")
+	}
+
+	ctx := context.Background()
+	chatID, err := uc.repo.GetMessageChatID(ctx, messageID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := uc.repo.GetMemberRole(ctx, chatID, from); err != nil {
+		return errors.New("only chat members can report messages")
+	}
+
+	return uc.repo.CreateReport(ctx, &entity.Report{MessageID: messageID, ChatID: chatID, Reporter: from, Reason: reason})
+}
+
+func (uc *ChatUseCase) GetFlaggedMessages(chatID int64, from string) ([]*proto_gen.FlaggedMessage, error) {
+	ctx := context.Background()
+	if err := uc.requireAdmin(ctx, chatID, from); err != nil {
+		return nil, err
+	}
+
+	return uc.repo.GetFlaggedMessages(ctx, chatID)
+}
+
+// ReviewMessage resolves the flag and reports of a message. A removed message is
+// deleted and the chat is told about it with a "message_removed" event.
+func (uc *ChatUseCase) ReviewMessage(messageID int64, from string, remove bool) error {
+	ctx := context.Background()
+
+	chatID, err := uc.repo.GetMessageChatID(ctx, messageID)
+	if err != nil {
+		return err
+	}
+	if err := uc.requireAdmin(ctx, chatID, from); err != nil {
+		return err
+	}
+
+	if err := uc.repo.ResolveMessage(ctx, messageID, remove); err != nil {
+		return err
+	}
+	if !remove {
+		return nil
+	}
+
+	return uc.deliver(&proto_gen.Message{
+		ChatId:    chatID,
+		From:      from,
+		Text:      fmt.Sprintf("%s removed a message", from),
+		Timestamp: timestamppb.Now(),
+		Payload: &proto_gen.Message_SystemEvent{SystemEvent: &proto_gen.SystemEvent{
+			Type:  "message_removed",
+			Actor: from,
+			Data:  map[string]string{"message_id": strconv.FormatInt(messageID, 10)},
+		}},
+	})
+}
+
+func (uc *ChatUseCase) BanUser(chatID int64, from, username, reason string) error {
+	if chatID == 0 || username == "" {
+		return errors.New("invalid ban parameters")
+	}
+	if username == from {
+		return errors.New("cannot ban yourself")
+	}
+
+	ctx := context.Background()
+	if err := uc.requireAdmin(ctx, chatID, from); err != nil {
+		return err
+	}
+
+	if err := uc.repo.BanUser(ctx, chatID, username, from, reason); err != nil {
+		return err
+	}
+
+	return uc.deliver(&proto_gen.Message{
+		ChatId:    chatID,
+		From:      from,
+		Text:      fmt.Sprintf("%s banned %s", from, username),
+		Timestamp: timestamppb.Now(),
+		Payload: &proto_gen.Message_SystemEvent{SystemEvent: &proto_gen.SystemEvent{
+			Type:  "user_banned",
+			Actor: from,
+			Data:  map[string]string{"username": username, "reason": reason},
+		}},
+	})
+}
+
+func (uc *ChatUseCase) UnbanUser(chatID int64, from, username string) error {
+	ctx := context.Background()
+	if err := uc.requireAdmin(ctx, chatID, from); err != nil {
+		return err
+	}
+
+	return uc.repo.UnbanUser(ctx, chatID, username)
+}
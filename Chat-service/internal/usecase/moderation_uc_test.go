@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"chat-grpc/Chat-service/internal/entity"
+	"chat-grpc/Chat-service/moderation"
+	"chat-grpc/proto_gen"
+	"github.com/stretchr/testify/require"
+)
+
+type brokenModerator struct{}
+
+func (brokenModerator) Check(ctx context.Context, chatID int64, text string, rules moderation.Rules) ([]moderation.Match, error) {
+	return nil, errors.New("moderation service is down")
+}
+
+func TestBrokenModerator(t *testing.T) {
+	tests := []struct {
+		action entity.ModerationAction
+		err    error
+	}{
+		{entity.FlagModeration, nil},
+		{entity.MaskModeration, nil},
+		{entity.BlockModeration, ErrModerationUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.action.StringAction(), func(t *testing.T) {
+			repo := newFakeRepo()
+			repo.moderation[7] = &entity.ModerationSettings{ChatID: 7, Action: tt.action}
+			uc, _ := newTestUseCase(repo, nil)
+			uc.moderator = brokenModerator{}
+
+			failures := moderationFailures.Value()
+			err := uc.SendMessage(&proto_gen.Message{ChatId: 7, From: "alice", Text: "hello"})
+			require.ErrorIs(t, err, tt.err)
+			require.Equal(t, failures+1, moderationFailures.Value())
+
+			if tt.err != nil {
+				require.Empty(t, repo.published, "blocked chats get no unchecked messages")
+			} else {
+				require.Len(t, repo.published, 1)
+			}
+		})
+	}
+}
+
+func TestBlockModerationBlocksMatchingMessage(t *testing.T) {
+	repo := newFakeRepo()
+	repo.moderation[7] = &entity.ModerationSettings{ChatID: 7, Action: entity.BlockModeration, Words: []string{"spam"}}
+	uc, _ := newTestUseCase(repo, nil)
+	uc.moderator = moderation.NewFilter()
+
+	require.ErrorIs(t, uc.SendMessage(&proto_gen.Message{ChatId: 7, From: "alice", Text: "buy spam"}), ErrMessageBlocked)
+	require.NoError(t, uc.SendMessage(&proto_gen.Message{ChatId: 7, From: "alice", Text: "hello"}))
+	require.Len(t, repo.published, 1)
+}
@@ -0,0 +1,179 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"chat-grpc/Chat-service/internal/entity"
+	"chat-grpc/proto_gen"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	minPollOptions = 2
+	maxPollOptions = 10
+)
+
+func (uc *ChatUseCase) CreatePoll(poll *entity.Poll) (int64, error) {
+	if poll.ChatID == 0 || poll.Creator == "" || poll.Question == "" {
+		return 0, errors.New("invalid poll parameters")
+	}
+
+	if len(poll.Options) < minPollOptions || len(poll.Options) > maxPollOptions {
+		return 0, errors.New("poll must have from 2 to 10 options")
+	}
+
+	for _, option := range poll.Options {
+		if option == "" {
+			return 0, errors.New("poll option cannot be empty")
+		}
+	}
+
+	now := time.Now()
+	if poll.ClosesAt != nil && !poll.ClosesAt.After(now) {
+		return 0, errors.New("poll closing time must be in the future")
+	}
+
+	if _, err := uc.repo.GetMemberRole(context.Background(), poll.ChatID, poll.Creator); err != nil {
+		return 0, errors.New("only chat members can create polls")
+	}
+
+	msg := &proto_gen.Message{
+		ChatId:    poll.ChatID,
+		From:      poll.Creator,
+		Text:      poll.Question,
+		Timestamp: timestamppb.New(now),
+		Kind:      proto_gen.MessageKind_PollKind,
+		Payload:   &proto_gen.Message_Poll{Poll: pollToProto(poll, now)},
+	}
+
+	return uc.repo.CreatePoll(poll, msg)
+}
+
+func (uc *ChatUseCase) Vote(pollID int64, from string, options []int) error {
+	if pollID == 0 || from == "" || len(options) == 0 {
+		return errors.New("invalid vote parameters")
+	}
+
+	ctx := context.Background()
+	poll, err := uc.repo.GetPoll(ctx, pollID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := uc.repo.GetMemberRole(ctx, poll.ChatID, from); err != nil {
+		return errors.New("only chat members can vote")
+	}
+
+	if poll.IsClosed(time.Now()) {
+		return errors.New("poll is closed")
+	}
+
+	if !poll.MultiChoice && len(options) > 1 {
+		return errors.New("poll allows only one option")
+	}
+
+	seen := make(map[int]bool, len(options))
+	for _, option := range options {
+		if option < 0 || option >= len(poll.Options) {
+			return errors.New("invalid poll option")
+		}
+		if seen[option] {
+			return errors.New("duplicate poll option")
+		}
+		seen[option] = true
+	}
+
+	return uc.repo.Vote(pollID, from, options, pollTally)
+}
+
+func (uc *ChatUseCase) ClosePoll(pollID int64, from string) error {
+	if pollID == 0 || from == "" {
+		return errors.New("invalid poll parameters")
+	}
+
+	ctx := context.Background()
+	poll, err := uc.repo.GetPoll(ctx, pollID)
+	if err != nil {
+		return err
+	}
+
+	if poll.Creator != from {
+		return errors.New("only poll creator can close the poll")
+	}
+
+	if poll.Closed {
+		return nil
+	}
+
+	return uc.repo.ClosePoll(pollID, pollTally)
+}
+
+// pollTally builds the message with the current poll state for the chat subscribers.
+// Tally updates carry no text, so they are not stored or e-mailed as new messages.
+func pollTally(poll *entity.Poll) *proto_gen.Message {
+	now := time.Now()
+	return &proto_gen.Message{
+		ChatId:    poll.ChatID,
+		Timestamp: timestamppb.New(now),
+		Kind:      proto_gen.MessageKind_PollKind,
+		Payload:   &proto_gen.Message_Poll{Poll: pollToProto(poll, now)},
+	}
+}
+
+func (uc *ChatUseCase) hydratePolls(ctx context.Context, messages []*proto_gen.Message) error {
+	now := time.Now()
+	for _, msg := range messages {
+		stub := msg.GetPoll()
+		if stub == nil {
+			continue
+		}
+
+		poll, err := uc.repo.GetPoll(ctx, stub.Id)
+		if err != nil {
+			uc.log.Error("Failed to load poll for message", zap.Int64("poll_id", stub.Id), zap.Error(err))
+			return err
+		}
+		msg.Payload = &proto_gen.Message_Poll{Poll: pollToProto(poll, now)}
+	}
+
+	return nil
+}
+
+func pollToProto(poll *entity.Poll, now time.Time) *proto_gen.Poll {
+	options := make([]*proto_gen.PollOption, len(poll.Options))
+	for i, text := range poll.Options {
+		options[i] = &proto_gen.PollOption{Index: int32(i), Text: text}
+	}
+
+	voters := make(map[string]bool)
+	for _, vote := range poll.Votes {
+		if vote.OptionIndex < 0 || vote.OptionIndex >= len(options) {
+			continue
+		}
+
+		option := options[vote.OptionIndex]
+		option.Votes++
+		if !poll.Anonymous {
+			option.Voters = append(option.Voters, vote.Username)
+		}
+		voters[vote.Username] = true
+	}
+
+	res := &proto_gen.Poll{
+		Id:          poll.ID,
+		Question:    poll.Question,
+		Options:     options,
+		MultiChoice: poll.MultiChoice,
+		Anonymous:   poll.Anonymous,
+		Closed:      poll.IsClosed(now),
+		TotalVoters: int64(len(voters)),
+	}
+	if poll.ClosesAt != nil {
+		res.ClosesAt = timestamppb.New(*poll.ClosesAt)
+	}
+
+	return res
+}
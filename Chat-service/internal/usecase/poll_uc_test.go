@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"chat-grpc/Chat-service/internal/entity"
+	"github.com/stretchr/testify/require"
+)
+
+func newPollTest(t *testing.T, multiChoice bool) (*ChatUseCase, *fakeRepo, int64) {
+	t.Helper()
+
+	repo := newFakeRepo()
+	repo.addMember(7, "alice", entity.AdminChatRole)
+	repo.addMember(7, "bob", entity.MemberChatRole)
+	uc, _ := newTestUseCase(repo, nil)
+
+	pollID, err := uc.CreatePoll(&entity.Poll{
+		ChatID:      7,
+		Creator:     "alice",
+		Question:    "Lunch?",
+		Options:     []string{"pizza", "sushi", "salad"},
+		MultiChoice: multiChoice,
+	})
+	require.NoError(t, err)
+
+	return uc, repo, pollID
+}
+
+func optionVotes(repo *fakeRepo) []int64 {
+	var votes []int64
+	for _, option := range repo.lastPublished().GetPoll().Options {
+		votes = append(votes, option.Votes)
+	}
+
+	return votes
+}
+
+func TestCreatePollRequiresMembership(t *testing.T) {
+	repo := newFakeRepo()
+	uc, _ := newTestUseCase(repo, nil)
+
+	_, err := uc.CreatePoll(&entity.Poll{ChatID: 7, Creator: "mallory", Question: "Lunch?", Options: []string{"pizza", "sushi"}})
+	require.Error(t, err)
+	require.Empty(t, repo.polls)
+}
+
+func TestCreatePollValidatesOptions(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addMember(7, "alice", entity.MemberChatRole)
+	uc, _ := newTestUseCase(repo, nil)
+
+	past := time.Now().Add(-time.Minute)
+	tests := []*entity.Poll{
+		{ChatID: 7, Creator: "alice", Question: "Lunch?", Options: []string{"pizza"}},
+		{ChatID: 7, Creator: "alice", Question: "Lunch?", Options: []string{"pizza", ""}},
+		{ChatID: 7, Creator: "alice", Question: "Lunch?", Options: []string{"pizza", "sushi"}, ClosesAt: &past},
+	}
+	for _, poll := range tests {
+		_, err := uc.CreatePoll(poll)
+		require.Error(t, err)
+	}
+}
+
+func TestVoteRequiresMembership(t *testing.T) {
+	uc, repo, pollID := newPollTest(t, false)
+
+	require.Error(t, uc.Vote(pollID, "mallory", []int{0}))
+	require.Empty(t, repo.polls[pollID].Votes)
+}
+
+func TestRevoteReplacesPreviousVote(t *testing.T) {
+	uc, repo, pollID := newPollTest(t, false)
+
+	require.NoError(t, uc.Vote(pollID, "bob", []int{0}))
+	require.Equal(t, []int64{1, 0, 0}, optionVotes(repo))
+
+	require.NoError(t, uc.Vote(pollID, "bob", []int{2}))
+	require.Equal(t, []int64{0, 0, 1}, optionVotes(repo))
+	require.Equal(t, int64(1), repo.lastPublished().GetPoll().TotalVoters)
+}
+
+func TestSingleChoicePollRefusesSeveralOptions(t *testing.T) {
+	uc, _, pollID := newPollTest(t, false)
+
+	require.Error(t, uc.Vote(pollID, "bob", []int{0, 1}))
+}
+
+func TestMultiChoiceVote(t *testing.T) {
+	uc, repo, pollID := newPollTest(t, true)
+
+	require.NoError(t, uc.Vote(pollID, "bob", []int{0, 2}))
+	require.NoError(t, uc.Vote(pollID, "alice", []int{2}))
+	require.Equal(t, []int64{1, 0, 2}, optionVotes(repo))
+	require.Equal(t, int64(2), repo.lastPublished().GetPoll().TotalVoters)
+
+	require.Error(t, uc.Vote(pollID, "bob", []int{1, 1}), "duplicate option")
+	require.Error(t, uc.Vote(pollID, "bob", []int{3}), "option out of range")
+}
+
+func TestClosedPollRefusesVotes(t *testing.T) {
+	uc, repo, pollID := newPollTest(t, false)
+
+	require.Error(t, uc.ClosePoll(pollID, "bob"), "only the creator closes the poll")
+	require.NoError(t, uc.ClosePoll(pollID, "alice"))
+	require.True(t, repo.lastPublished().GetPoll().Closed)
+
+	require.Error(t, uc.Vote(pollID, "bob", []int{0}))
+	require.Empty(t, repo.polls[pollID].Votes)
+}
@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"chat-grpc/Chat-service/internal/entity"
+	"go.uber.org/zap"
+)
+
+// RateLimitError is returned by SendMessage when the user, in the chat or in
+// all chats together, or the chat sends too fast.
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded, retry after %s", e.RetryAfter.Round(time.Millisecond))
+}
+
+// rateLimits returns the limits of the chat, or the service defaults.
+func (uc *ChatUseCase) rateLimits(ctx context.Context, chatID int64) (*entity.RateLimits, error) {
+	limits, err := uc.repo.GetRateLimits(ctx, chatID)
+	if errors.Is(err, sql.ErrNoRows) {
+		defaults := uc.defaultRateLimits
+		defaults.ChatID = chatID
+		return &defaults, nil
+	}
+
+	return limits, err
+}
+
+func (uc *ChatUseCase) checkRateLimit(ctx context.Context, chatID int64, username string) error {
+	limits, err := uc.rateLimits(ctx, chatID)
+	if err != nil {
+		return err
+	}
+
+	// the user bucket goes first, so concurrent sends lock the buckets in the
+	// same order: user, user in the chat, chat
+	buckets := append(uc.userRateLimit.Buckets(username), limits.Buckets(username)...)
+	wait, err := uc.repo.TakeTokens(ctx, buckets)
+	if err != nil {
+		return err
+	}
+	if wait > 0 {
+		uc.log.Warn("Rate limit exceeded", zap.Int64("chat_id", chatID), zap.String("username", username), zap.Duration("retry_after", wait))
+		return &RateLimitError{RetryAfter: wait}
+	}
+
+	return nil
+}
+
+func (uc *ChatUseCase) SetRateLimits(limits *entity.RateLimits, from string) error {
+	if limits.UserPerMinute < 0 || limits.UserBurst < 0 || limits.ChatPerMinute < 0 || limits.ChatBurst < 0 {
+		return errors.New("rate limits cannot be negative")
+	}
+
+	ctx := context.Background()
+	if err := uc.requireAdmin(ctx, limits.ChatID, from); err != nil {
+		return err
+	}
+
+	return uc.repo.SetRateLimits(ctx, limits)
+}
+
+func (uc *ChatUseCase) GetRateLimits(chatID int64, from string) (*entity.RateLimits, error) {
+	ctx := context.Background()
+	if err := uc.requireAdmin(ctx, chatID, from); err != nil {
+		return nil, err
+	}
+
+	return uc.rateLimits(ctx, chatID)
+}
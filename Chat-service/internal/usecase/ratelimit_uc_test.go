@@ -0,0 +1,30 @@
+package usecase
+
+import (
+	"testing"
+
+	"chat-grpc/Chat-service/internal/entity"
+	"chat-grpc/proto_gen"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserRateLimitSpansChats(t *testing.T) {
+	repo := newFakeRepo()
+	uc, _ := newTestUseCase(repo, nil)
+	uc.defaultRateLimits = entity.RateLimits{UserPerMinute: 10, UserBurst: 2}
+	uc.userRateLimit = entity.UserRateLimit{PerMinute: 10, Burst: 3}
+
+	send := func(chatID int64, from string) error {
+		return uc.SendMessage(&proto_gen.Message{ChatId: chatID, From: from, Text: "hello"})
+	}
+	var rateErr *RateLimitError
+
+	require.NoError(t, send(1, "alice"))
+	require.NoError(t, send(1, "alice"))
+	require.ErrorAs(t, send(1, "alice"), &rateErr, "the limit in the chat is spent")
+
+	require.NoError(t, send(2, "alice"))
+	require.ErrorAs(t, send(3, "alice"), &rateErr, "the limit in all chats is spent")
+	require.NoError(t, send(3, "bob"))
+	require.Len(t, repo.published, 4)
+}
@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"chat-grpc/Chat-service/internal/entity"
+	"chat-grpc/pkg/safehttp"
+	"go.uber.org/zap"
+)
+
+const (
+	webhookSecretBytes     = 32
+	webhookURLCheckTimeout = 5 * time.Second
+)
+
+func (uc *ChatUseCase) requireAdmin(ctx context.Context, chatID int64, username string) error {
+	role, err := uc.repo.GetMemberRole(ctx, chatID, username)
+	if err != nil {
+		return err
+	}
+	if role != entity.AdminChatRole {
+		return errors.New("this action requires the chat admin role")
+	}
+
+	return nil
+}
+
+// RegisterWebhook adds a webhook to the chat and returns its id and signing secret.
+// Without event types the webhook receives every event of the chat.
+func (uc *ChatUseCase) RegisterWebhook(chatID int64, from, rawURL string, eventTypes []string) (int64, string, error) {
+	if chatID == 0 || from == "" {
+		return 0, "", errors.New("invalid webhook parameters")
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return 0, "", errors.New("webhook url must be an absolute http(s) url")
+	}
+
+	ctx := context.Background()
+	if err := uc.requireAdmin(ctx, chatID, from); err != nil {
+		return 0, "", err
+	}
+
+	// the dispatcher refuses internal addresses anyway, this only tells the admin early
+	checkCtx, cancel := context.WithTimeout(ctx, webhookURLCheckTimeout)
+	defer cancel()
+	if err := safehttp.CheckURL(checkCtx, u.String()); err != nil {
+		return 0, "", fmt.Errorf("webhook url is not allowed: %w", err)
+	}
+
+	b := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return 0, "", err
+	}
+	secret := hex.EncodeToString(b)
+
+	id, err := uc.repo.CreateWebhook(ctx, &entity.Webhook{
+		ChatID:     chatID,
+		Creator:    from,
+		URL:        u.String(),
+		Secret:     secret,
+		EventTypes: eventTypes,
+	})
+	if err != nil {
+		return 0, "", err
+	}
+
+	uc.log.Info("Webhook registered", zap.Int64("chat_id", chatID), zap.Int64("webhook_id", id))
+	return id, secret, nil
+}
+
+func (uc *ChatUseCase) DeleteWebhook(id int64, from string) error {
+	ctx := context.Background()
+
+	webhook, err := uc.repo.GetWebhook(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := uc.requireAdmin(ctx, webhook.ChatID, from); err != nil {
+		return err
+	}
+
+	return uc.repo.DeleteWebhook(ctx, id)
+}
+
+func (uc *ChatUseCase) ListWebhooks(chatID int64, from string) ([]*entity.Webhook, error) {
+	ctx := context.Background()
+	if err := uc.requireAdmin(ctx, chatID, from); err != nil {
+		return nil, err
+	}
+
+	return uc.repo.ListWebhooks(ctx, chatID)
+}
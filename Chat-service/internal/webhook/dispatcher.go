@@ -0,0 +1,325 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"chat-grpc/Chat-service/internal/entity"
+	"chat-grpc/Chat-service/internal/repository"
+	"chat-grpc/pkg/broker"
+	"chat-grpc/pkg/safehttp"
+	"chat-grpc/proto_gen"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	consumerName = "webhook-dispatcher"
+
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+	IDHeader        = "X-Webhook-Id"
+
+	initialBackoff = time.Second
+	maxBackoff     = time.Minute
+	pollInterval   = time.Second
+)
+
+// Dispatcher delivers chat events to webhooks. Events are first stored as jobs,
+// one per webhook, and then posted by a fixed number of workers, so deliveries
+// that are still being retried survive a restart.
+type Dispatcher struct {
+	repo        repository.WebhookRepo
+	log         *zap.Logger
+	client      *http.Client
+	workers     int
+	maxAttempts int
+	maxFailures int
+}
+
+// NewDispatcher creates a dispatcher that posts up to workers events at a time.
+// Webhooks on loopback, private or link-local addresses are not called.
+func NewDispatcher(repo repository.WebhookRepo, log *zap.Logger, timeout time.Duration, workers, maxAttempts, maxFailures int) *Dispatcher {
+	return &Dispatcher{
+		repo:        repo,
+		log:         log,
+		client:      safehttp.NewClient(timeout),
+		workers:     max(workers, 1),
+		maxAttempts: maxAttempts,
+		maxFailures: maxFailures,
+	}
+}
+
+// Start subscribes the dispatcher to the events of every chat. All replicas share
+// one durable consumer, so each event is stored once. Run delivers the jobs.
+func (d *Dispatcher) Start(b broker.Broker) (broker.Subscription, error) {
+	return b.Consume("chat.*", consumerName, d.handle)
+}
+
+type payload struct {
+	Event     string          `json:"event"`
+	ChatID    int64           `json:"chat_id"`
+	MessageID int64           `json:"message_id,omitempty"`
+	Timestamp time.Time       `json:"timestamp"`
+	Message   json.RawMessage `json:"message"`
+}
+
+// EventType names the event a message represents: "system.<type>" for system
+// events and "message.<kind>" for everything else, e.g. "message.text".
+func EventType(msg *proto_gen.Message) string {
+	if event := msg.GetSystemEvent(); event != nil {
+		return "system." + event.Type
+	}
+
+	return "message." + eventKinds[msg.Kind]
+}
+
+var eventKinds = map[proto_gen.MessageKind]string{
+	proto_gen.MessageKind_TextKind:        "text",
+	proto_gen.MessageKind_SystemKind:      "system",
+	proto_gen.MessageKind_AttachmentKind:  "attachment",
+	proto_gen.MessageKind_LinkPreviewKind: "link_preview",
+	proto_gen.MessageKind_PollKind:        "poll",
+	proto_gen.MessageKind_CodeSnippetKind: "code_snippet",
+}
+
+// handle stores a job for every webhook of the chat that accepts the event. The
+// event is acknowledged only once the jobs are stored.
+func (d *Dispatcher) handle(e *proto_gen.ChatEvent) error {
+	// ephemeral messages are private to one user
+	if e.Type == proto_gen.ChatEventType_EphemeralMessageEvent {
+		return nil
+	}
+
+	ctx := context.Background()
+	msg := e.GetMessage()
+
+	webhooks, err := d.repo.GetActiveWebhooks(ctx, msg.ChatId)
+	if err != nil {
+		d.log.Error("Failed to get chat webhooks", zap.Int64("chat_id", msg.ChatId), zap.Error(err))
+		return err
+	}
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	event := EventType(msg)
+	body, err := encodePayload(event, msg)
+	if err != nil {
+		d.log.Error("Failed to encode webhook payload", zap.Int64("chat_id", msg.ChatId), zap.Error(err))
+		return err
+	}
+
+	var jobs []*entity.WebhookJob
+	for _, webhook := range webhooks {
+		if webhook.Accepts(event) {
+			jobs = append(jobs, &entity.WebhookJob{
+				WebhookID: webhook.ID,
+				EventID:   e.Id,
+				EventType: event,
+				MessageID: msg.Id,
+				Body:      body,
+			})
+		}
+	}
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	if err := d.repo.EnqueueWebhookJobs(ctx, jobs); err != nil {
+		d.log.Error("Failed to store webhook jobs", zap.Int64("chat_id", msg.ChatId), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func encodePayload(event string, msg *proto_gen.Message) ([]byte, error) {
+	message, err := protojson.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := time.Now().UTC()
+	if msg.Timestamp != nil {
+		timestamp = msg.Timestamp.AsTime()
+	}
+
+	return json.Marshal(payload{
+		Event:     event,
+		ChatID:    msg.ChatId,
+		MessageID: msg.Id,
+		Timestamp: timestamp,
+		Message:   message,
+	})
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the body, sent as "sha256=<signature>".
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Run delivers the due jobs until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			n, err := d.deliverDue(ctx)
+			if err != nil {
+				d.log.Error("Failed to claim webhook jobs", zap.Error(err))
+				break
+			}
+			if n < d.workers {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliverDue posts a batch of due jobs, one worker per job, and returns how many
+// jobs it took.
+func (d *Dispatcher) deliverDue(ctx context.Context) (int, error) {
+	// a job is handed out again if its worker has not finished it by then
+	lease := d.client.Timeout + time.Minute
+
+	jobs, err := d.repo.ClaimWebhookJobs(ctx, d.workers, lease)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.deliver(ctx, job)
+		}()
+	}
+	wg.Wait()
+
+	return len(jobs), nil
+}
+
+// deliver makes one attempt to post the job. A failed job is retried with
+// exponential backoff. A webhook that fails every attempt counts one failure;
+// after maxFailures in a row it is disabled.
+func (d *Dispatcher) deliver(ctx context.Context, job *entity.WebhookJob) {
+	webhook, err := d.repo.GetWebhook(ctx, job.WebhookID)
+	if err != nil {
+		// the job is handed out again once its lease expires
+		return
+	}
+	if !webhook.Active {
+		d.finish(ctx, job)
+		return
+	}
+
+	statusCode, err := d.post(ctx, webhook, job.EventType, job.Body)
+
+	delivery := &entity.WebhookDelivery{
+		WebhookID:  webhook.ID,
+		MessageID:  job.MessageID,
+		EventType:  job.EventType,
+		Attempt:    job.Attempts,
+		StatusCode: statusCode,
+		Success:    err == nil,
+	}
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+	if recErr := d.repo.RecordWebhookDelivery(ctx, delivery); recErr != nil {
+		d.log.Warn("Failed to record webhook delivery", zap.Int64("webhook_id", webhook.ID), zap.Error(recErr))
+	}
+
+	if err == nil {
+		if webhook.FailureCount > 0 {
+			if err := d.repo.ResetWebhookFailures(ctx, webhook.ID); err != nil {
+				d.log.Warn("Failed to reset webhook failures", zap.Int64("webhook_id", webhook.ID), zap.Error(err))
+			}
+		}
+		d.finish(ctx, job)
+		return
+	}
+
+	d.log.Warn("Webhook delivery failed", zap.Int64("webhook_id", webhook.ID),
+		zap.Int("attempt", job.Attempts), zap.Error(err))
+
+	if job.Attempts < d.maxAttempts {
+		if err := d.repo.RetryWebhookJob(ctx, job.ID, backoff(job.Attempts)); err != nil {
+			d.log.Warn("Failed to schedule webhook retry", zap.Int64("webhook_id", webhook.ID), zap.Error(err))
+		}
+		return
+	}
+
+	d.finish(ctx, job)
+
+	disabled, err := d.repo.IncWebhookFailures(ctx, webhook.ID, d.maxFailures)
+	if err != nil {
+		d.log.Error("Failed to count webhook failure", zap.Int64("webhook_id", webhook.ID), zap.Error(err))
+		return
+	}
+	if disabled {
+		d.log.Warn("Webhook disabled", zap.Int64("webhook_id", webhook.ID), zap.String("url", webhook.URL))
+	}
+}
+
+func (d *Dispatcher) finish(ctx context.Context, job *entity.WebhookJob) {
+	if err := d.repo.DeleteWebhookJob(ctx, job.ID); err != nil {
+		d.log.Warn("Failed to delete webhook job", zap.Int64("job_id", job.ID), zap.Error(err))
+	}
+}
+
+// backoff is the delay after the given failed attempt: one second, doubled
+// with every attempt up to a minute.
+func backoff(attempt int) time.Duration {
+	delay := initialBackoff
+	for i := 1; i < attempt && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxBackoff)
+}
+
+func (d *Dispatcher) post(ctx context.Context, webhook *entity.Webhook, event string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(IDHeader, fmt.Sprint(webhook.ID))
+	req.Header.Set(SignatureHeader, "sha256="+Sign(webhook.Secret, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,304 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"chat-grpc/Chat-service/internal/entity"
+	"chat-grpc/Chat-service/internal/repository"
+	"chat-grpc/pkg/broker"
+	"chat-grpc/pkg/safehttp"
+	"chat-grpc/proto_gen"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// fakeRepo keeps webhooks and jobs in memory. Every stored job is due.
+type fakeRepo struct {
+	repository.WebhookRepo
+
+	mu         sync.Mutex
+	webhooks   map[int64]*entity.Webhook
+	jobs       map[int64]*entity.WebhookJob
+	nextJobID  int64
+	deliveries []*entity.WebhookDelivery
+	retries    []time.Duration
+	enqueueErr error
+}
+
+func newFakeRepo(webhooks ...*entity.Webhook) *fakeRepo {
+	r := &fakeRepo{webhooks: make(map[int64]*entity.Webhook), jobs: make(map[int64]*entity.WebhookJob)}
+	for _, w := range webhooks {
+		r.webhooks[w.ID] = w
+	}
+
+	return r
+}
+
+func (r *fakeRepo) GetWebhook(ctx context.Context, id int64) (*entity.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	w, ok := r.webhooks[id]
+	if !ok {
+		return nil, errors.New("webhook not found")
+	}
+	copied := *w
+
+	return &copied, nil
+}
+
+func (r *fakeRepo) GetActiveWebhooks(ctx context.Context, chatID int64) ([]*entity.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var webhooks []*entity.Webhook
+	for _, w := range r.webhooks {
+		if w.ChatID == chatID && w.Active {
+			webhooks = append(webhooks, w)
+		}
+	}
+
+	return webhooks, nil
+}
+
+func (r *fakeRepo) RecordWebhookDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.deliveries = append(r.deliveries, delivery)
+	return nil
+}
+
+func (r *fakeRepo) ResetWebhookFailures(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.webhooks[id].FailureCount = 0
+	return nil
+}
+
+func (r *fakeRepo) IncWebhookFailures(ctx context.Context, id int64, maxFailures int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	w := r.webhooks[id]
+	w.FailureCount++
+	if w.FailureCount >= maxFailures {
+		w.Active = false
+	}
+
+	return !w.Active, nil
+}
+
+func (r *fakeRepo) EnqueueWebhookJobs(ctx context.Context, jobs []*entity.WebhookJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.enqueueErr != nil {
+		return r.enqueueErr
+	}
+
+	for _, job := range jobs {
+		duplicate := false
+		for _, stored := range r.jobs {
+			if stored.WebhookID == job.WebhookID && stored.EventID == job.EventID {
+				duplicate = true
+			}
+		}
+		if duplicate {
+			continue
+		}
+
+		r.nextJobID++
+		stored := *job
+		stored.ID = r.nextJobID
+		r.jobs[stored.ID] = &stored
+	}
+
+	return nil
+}
+
+func (r *fakeRepo) ClaimWebhookJobs(ctx context.Context, limit int, lease time.Duration) ([]*entity.WebhookJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var jobs []*entity.WebhookJob
+	for _, job := range r.jobs {
+		if len(jobs) == limit {
+			break
+		}
+		job.Attempts++
+		copied := *job
+		jobs = append(jobs, &copied)
+	}
+
+	return jobs, nil
+}
+
+func (r *fakeRepo) RetryWebhookJob(ctx context.Context, id int64, after time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.retries = append(r.retries, after)
+	return nil
+}
+
+func (r *fakeRepo) DeleteWebhookJob(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.jobs, id)
+	return nil
+}
+
+func (r *fakeRepo) pendingJobs() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.jobs)
+}
+
+func textEvent(chatID, messageID int64, text string) *proto_gen.ChatEvent {
+	return broker.NewEvent(&proto_gen.Message{
+		Id:        messageID,
+		ChatId:    chatID,
+		From:      "alice",
+		Text:      text,
+		Timestamp: timestamppb.Now(),
+	}, "test")
+}
+
+// newTestDispatcher calls the test server, which listens on loopback.
+func newTestDispatcher(repo *fakeRepo, server *httptest.Server, maxAttempts, maxFailures int) *Dispatcher {
+	d := NewDispatcher(repo, zap.NewNop(), time.Second, 4, maxAttempts, maxFailures)
+	d.client = server.Client()
+
+	return d
+}
+
+func TestDispatcherDeliversSignedPayload(t *testing.T) {
+	type request struct {
+		event     string
+		signature string
+		body      []byte
+	}
+	requests := make(chan request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- request{event: r.Header.Get(EventHeader), signature: r.Header.Get(SignatureHeader), body: body}
+	}))
+	defer server.Close()
+
+	repo := newFakeRepo(&entity.Webhook{ID: 1, ChatID: 7, URL: server.URL, Secret: "secret", Active: true})
+	d := newTestDispatcher(repo, server, 3, 3)
+
+	require.NoError(t, d.handle(textEvent(7, 42, "hello")))
+	require.Equal(t, 1, repo.pendingJobs())
+
+	n, err := d.deliverDue(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	req := <-requests
+	require.Equal(t, "message.text", req.event)
+	require.Equal(t, "sha256="+Sign("secret", req.body), req.signature)
+	require.Contains(t, string(req.body), `"message_id":42`)
+
+	require.Equal(t, 0, repo.pendingJobs())
+	require.Len(t, repo.deliveries, 1)
+	require.True(t, repo.deliveries[0].Success)
+}
+
+func TestDispatcherSkipsWebhooksOfOtherEvents(t *testing.T) {
+	repo := newFakeRepo(&entity.Webhook{ID: 1, ChatID: 7, URL: "http://example.com", EventTypes: []string{"system.bot_added"}, Active: true})
+	d := NewDispatcher(repo, zap.NewNop(), time.Second, 4, 3, 3)
+
+	require.NoError(t, d.handle(textEvent(7, 42, "hello")))
+	require.Equal(t, 0, repo.pendingJobs())
+}
+
+func TestDispatcherStoresRedeliveredEventOnce(t *testing.T) {
+	repo := newFakeRepo(&entity.Webhook{ID: 1, ChatID: 7, URL: "http://example.com", Active: true})
+	d := NewDispatcher(repo, zap.NewNop(), time.Second, 4, 3, 3)
+
+	event := textEvent(7, 42, "hello")
+	require.NoError(t, d.handle(event))
+	require.NoError(t, d.handle(event))
+	require.Equal(t, 1, repo.pendingJobs())
+}
+
+func TestDispatcherAsksForRedeliveryWhenJobsAreNotStored(t *testing.T) {
+	repo := newFakeRepo(&entity.Webhook{ID: 1, ChatID: 7, URL: "http://example.com", Active: true})
+	repo.enqueueErr = errors.New("database is down")
+	d := NewDispatcher(repo, zap.NewNop(), time.Second, 4, 3, 3)
+
+	require.Error(t, d.handle(textEvent(7, 42, "hello")))
+}
+
+func TestDispatcherRetriesAndDisablesFailingWebhook(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	repo := newFakeRepo(&entity.Webhook{ID: 1, ChatID: 7, URL: server.URL, Secret: "secret", Active: true})
+	d := newTestDispatcher(repo, server, 3, 1)
+
+	require.NoError(t, d.handle(textEvent(7, 42, "hello")))
+	for range 3 {
+		_, err := d.deliverDue(context.Background())
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, 3, calls)
+	require.Equal(t, []time.Duration{time.Second, 2 * time.Second}, repo.retries)
+	require.Equal(t, 0, repo.pendingJobs())
+	require.Len(t, repo.deliveries, 3)
+	require.Equal(t, http.StatusInternalServerError, repo.deliveries[2].StatusCode)
+	require.Equal(t, 3, repo.deliveries[2].Attempt)
+
+	webhook, err := repo.GetWebhook(context.Background(), 1)
+	require.NoError(t, err)
+	require.False(t, webhook.Active)
+
+	// a disabled webhook gets no new jobs
+	require.NoError(t, d.handle(textEvent(7, 43, "again")))
+	require.Equal(t, 0, repo.pendingJobs())
+}
+
+func TestDispatcherRefusesInternalAddresses(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	repo := newFakeRepo(&entity.Webhook{ID: 1, ChatID: 7, URL: server.URL, Secret: "secret", Active: true})
+	d := NewDispatcher(repo, zap.NewNop(), time.Second, 4, 3, 3)
+
+	require.NoError(t, d.handle(textEvent(7, 42, "hello")))
+	_, err := d.deliverDue(context.Background())
+	require.NoError(t, err)
+
+	require.False(t, called)
+	require.Len(t, repo.deliveries, 1)
+	require.False(t, repo.deliveries[0].Success)
+	require.Contains(t, repo.deliveries[0].Error, safehttp.ErrForbiddenAddress.Error())
+}
+
+func TestBackoff(t *testing.T) {
+	require.Equal(t, time.Second, backoff(1))
+	require.Equal(t, 4*time.Second, backoff(3))
+	require.Equal(t, maxBackoff, backoff(10))
+	require.Equal(t, maxBackoff, backoff(100))
+}
@@ -0,0 +1,252 @@
+package moderation
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Rules describe what a moderator looks for. The Chat-service merges the
+// global rules from the config with the rules of the chat.
+type Rules struct {
+	Words          []string
+	Patterns       []string
+	BlockedDomains []string
+}
+
+func (r Rules) Merge(other Rules) Rules {
+	return Rules{
+		Words:          append(append([]string{}, r.Words...), other.Words...),
+		Patterns:       append(append([]string{}, r.Patterns...), other.Patterns...),
+		BlockedDomains: append(append([]string{}, r.BlockedDomains...), other.BlockedDomains...),
+	}
+}
+
+// Match is a byte range of the text that violates a rule.
+type Match struct {
+	Start  int
+	End    int
+	Reason string
+}
+
+// Moderator inspects the text of a message before it is stored.
+// It only reports matches, the chat settings decide whether to block, mask or flag.
+type Moderator interface {
+	Check(ctx context.Context, chatID int64, text string, rules Rules) ([]Match, error)
+}
+
+// Chain runs several moderators and collects all their matches.
+type Chain []Moderator
+
+func (c Chain) Check(ctx context.Context, chatID int64, text string, rules Rules) ([]Match, error) {
+	var matches []Match
+	for _, m := range c {
+		found, err := m.Check(ctx, chatID, text, rules)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, found...)
+	}
+
+	return matches, nil
+}
+
+// Filter is the built-in moderator: a case-insensitive wordlist, regular
+// expressions and a link blocklist. Blocked domains match their subdomains too.
+type Filter struct {
+	mu    sync.Mutex
+	cache map[string]*regexp.Regexp
+}
+
+func NewFilter() *Filter {
+	return &Filter{cache: make(map[string]*regexp.Regexp)}
+}
+
+var tokenRe = regexp.MustCompile(`\S+`)
+
+func (f *Filter) Check(ctx context.Context, chatID int64, text string, rules Rules) ([]Match, error) {
+	var matches []Match
+
+	for _, word := range rules.Words {
+		if word == "" {
+			continue
+		}
+		re, err := f.compile(`(?i)` + regexp.QuoteMeta(word))
+		if err != nil {
+			return nil, err
+		}
+		for _, loc := range re.FindAllStringIndex(text, -1) {
+			if isWordBoundary(text, loc[0], loc[1]) {
+				matches = append(matches, Match{Start: loc[0], End: loc[1], Reason: "word"})
+			}
+		}
+	}
+
+	for _, pattern := range rules.Patterns {
+		re, err := f.compile(`(?i)` + pattern)
+		if err != nil {
+			return nil, err
+		}
+		matches = appendMatches(matches, re, text, "pattern")
+	}
+
+	if len(rules.BlockedDomains) > 0 {
+		for _, loc := range tokenRe.FindAllStringIndex(text, -1) {
+			if host := linkHost(text[loc[0]:loc[1]]); host != "" && domainBlocked(host, rules.BlockedDomains) {
+				matches = append(matches, Match{Start: loc[0], End: loc[1], Reason: "link"})
+			}
+		}
+	}
+
+	return matches, nil
+}
+
+func (f *Filter) compile(expr string) (*regexp.Regexp, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if re, ok := f.cache[expr]; ok {
+		return re, nil
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	f.cache[expr] = re
+
+	return re, nil
+}
+
+func appendMatches(matches []Match, re *regexp.Regexp, text, reason string) []Match {
+	for _, loc := range re.FindAllStringIndex(text, -1) {
+		if loc[0] == loc[1] {
+			continue
+		}
+		matches = append(matches, Match{Start: loc[0], End: loc[1], Reason: reason})
+	}
+
+	return matches
+}
+
+// isWordBoundary reports whether text[start:end] is a whole word. Unlike \b in
+// regexp it treats any Unicode letter as a word character.
+func isWordBoundary(text string, start, end int) bool {
+	if start > 0 {
+		r, _ := utf8.DecodeLastRuneInString(text[:start])
+		if isWordRune(r) {
+			return false
+		}
+	}
+	if end < len(text) {
+		r, _ := utf8.DecodeRuneInString(text[end:])
+		if isWordRune(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// linkHost returns the host of a word that looks like a link, e.g. "https://a.io/x" or "a.io".
+func linkHost(word string) string {
+	word = strings.Trim(word, `"'()[]<>,.;:!?`)
+	if !strings.Contains(word, ".") {
+		return ""
+	}
+	if !strings.Contains(word, "://") {
+		word = "http://" + word
+	}
+
+	u, err := url.Parse(word)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+}
+
+func domainBlocked(host string, domains []string) bool {
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimPrefix(d, "."))
+		if d != "" && (host == d || strings.HasSuffix(host, "."+d)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidatePatterns reports the first pattern that is not a valid regular expression.
+func ValidatePatterns(patterns []string) error {
+	for _, p := range patterns {
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+	}
+
+	return nil
+}
+
+// Reasons lists the distinct reasons of the matches, e.g. "word, link".
+func Reasons(matches []Match) string {
+	seen := make(map[string]bool)
+	var reasons []string
+	for _, m := range matches {
+		if !seen[m.Reason] {
+			seen[m.Reason] = true
+			reasons = append(reasons, m.Reason)
+		}
+	}
+
+	return strings.Join(reasons, ", ")
+}
+
+// Mask replaces every matched character with '*'.
+func Mask(text string, matches []Match) string {
+	if len(matches) == 0 {
+		return text
+	}
+
+	sorted := append([]Match{}, matches...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+
+	var b strings.Builder
+	pos := 0
+	for _, m := range sorted {
+		if m.End <= pos {
+			continue
+		}
+		start := max(m.Start, pos)
+		b.WriteString(text[pos:start])
+		b.WriteString(strings.Repeat("*", utf8.RuneCountInString(text[start:m.End])))
+		pos = m.End
+	}
+	b.WriteString(text[pos:])
+
+	return b.String()
+}
+
+type withRules struct {
+	next Moderator
+	base Rules
+}
+
+// WithRules adds global rules to the rules of every chat checked by m.
+func WithRules(m Moderator, base Rules) Moderator {
+	return &withRules{next: m, base: base}
+}
+
+func (w *withRules) Check(ctx context.Context, chatID int64, text string, rules Rules) ([]Match, error) {
+	return w.next.Check(ctx, chatID, text, w.base.Merge(rules))
+}
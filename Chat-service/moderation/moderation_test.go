@@ -0,0 +1,111 @@
+package moderation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterWords(t *testing.T) {
+	tests := []struct {
+		name  string
+		word  string
+		text  string
+		match []string
+	}{
+		{"whole word", "spam", "no spam here", []string{"spam"}},
+		{"case insensitive", "spam", "SPAM!", []string{"SPAM"}},
+		{"inside a word", "spam", "spammer", nil},
+		{"cyrillic whole word", "кот", "это кот.", []string{"кот"}},
+		{"cyrillic inside a word", "кот", "котик и скот", nil},
+		{"next to a digit", "bad", "bad1", nil},
+		{"next to punctuation", "bad", "(bad)", []string{"bad"}},
+		{"every occurrence", "bad", "bad, bad", []string{"bad", "bad"}},
+	}
+
+	f := NewFilter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := f.Check(context.Background(), 1, tt.text, Rules{Words: []string{tt.word}})
+			require.NoError(t, err)
+
+			var found []string
+			for _, m := range matches {
+				found = append(found, tt.text[m.Start:m.End])
+			}
+			require.Equal(t, tt.match, found)
+		})
+	}
+}
+
+func TestFilterInvalidPattern(t *testing.T) {
+	_, err := NewFilter().Check(context.Background(), 1, "text", Rules{Patterns: []string{"(unclosed"}})
+	require.Error(t, err)
+
+	require.Error(t, ValidatePatterns([]string{`\d+`, "(unclosed"}))
+	require.NoError(t, ValidatePatterns([]string{`\d+`, `buy\s+now`}))
+}
+
+func TestFilterBlockedLinks(t *testing.T) {
+	tests := []struct {
+		text    string
+		blocked bool
+	}{
+		{"see https://evil.com/page", true},
+		{"see https://cdn.evil.com/x.js", true},
+		{"see EVIL.COM", true},
+		{"see (www.evil.com).", true},
+		{"see https://notevil.com", false},
+		{"see https://evil.com.example.org", false},
+		{"see https://good.org", false},
+		{"evil dot com", false},
+	}
+
+	f := NewFilter()
+	for _, tt := range tests {
+		matches, err := f.Check(context.Background(), 1, tt.text, Rules{BlockedDomains: []string{".Evil.com"}})
+		require.NoError(t, err)
+		require.Equal(t, tt.blocked, len(matches) > 0, tt.text)
+	}
+}
+
+func TestLinkHost(t *testing.T) {
+	tests := map[string]string{
+		"https://A.io/x":   "a.io",
+		"a.io":             "a.io",
+		"<http://b.io.>":   "b.io",
+		"word":             "",
+		"http://[::1]:80/": "",
+	}
+
+	for word, host := range tests {
+		require.Equal(t, host, linkHost(word), word)
+	}
+}
+
+func TestMask(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		matches []Match
+		masked  string
+	}{
+		{"no matches", "hello", nil, "hello"},
+		{"one match", "hello world", []Match{{Start: 6, End: 11}}, "hello *****"},
+		{"overlapping", "abcdef", []Match{{Start: 2, End: 5}, {Start: 0, End: 3}}, "*****f"},
+		{"contained", "abcdef", []Match{{Start: 0, End: 5}, {Start: 1, End: 3}}, "*****f"},
+		{"one star per rune", "привет мир", []Match{{Start: 0, End: len("привет")}}, "****** мир"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.masked, Mask(tt.text, tt.matches))
+		})
+	}
+}
+
+func TestReasons(t *testing.T) {
+	matches := []Match{{Reason: "word"}, {Reason: "link"}, {Reason: "word"}}
+	require.Equal(t, "word, link", Reasons(matches))
+}
@@ -4,19 +4,20 @@ import (
 	"net"
 
 	"chat-grpc/Auth-service/interceptor"
-	"chat-grpc/Notification-service/internal/broker"
 	"chat-grpc/Notification-service/internal/handler"
 	"chat-grpc/Notification-service/internal/usecase"
+	"chat-grpc/pkg/broker"
 	"chat-grpc/pkg/config"
 	"chat-grpc/pkg/logger"
 	"chat-grpc/proto_gen"
 
-	"github.com/nats-io/nats.go"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
+const serviceName = "notification-service"
+
 func main() {
 	cfg := config.LoadConfig()
 	log, err := logger.NewLogger()
@@ -25,10 +26,11 @@ func main() {
 	}
 	defer log.Sync()
 
-	nc, err := nats.Connect(cfg.NatsUrl)
+	b, err := broker.New(cfg, serviceName, log)
 	if err != nil {
-		log.Fatal("Failed to connect to NATS", zap.Error(err))
+		log.Fatal("Failed to connect to the broker", zap.Error(err))
 	}
+	defer b.Close()
 
 	authConn, err := grpc.NewClient(cfg.AuthServiceAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
@@ -36,15 +38,18 @@ func main() {
 	}
 	defer authConn.Close()
 
-	authClient := interceptor.NewAuthClient(authConn, log)
+	if cfg.ServiceToken == "" {
+		log.Warn("SERVICE_TOKEN is not set, chat members cannot be read from the auth service")
+	}
+	authClient := interceptor.NewAuthClient(authConn, cfg.ServiceToken, log)
 
 	emailSender := handler.NewStubEmailSender(log)
 	notifier := usecase.NewNotifier(authClient, emailSender, log)
 
-	consumer := broker.NewNatsConsumer(nc, "chat.*", log, notifier.Notify)
-	if err := consumer.Start(); err != nil {
-		log.Fatal("Failed to start NATS consumer", zap.Error(err))
+	if _, err := b.Consume("chat.*", serviceName, notifier.Notify); err != nil {
+		log.Fatal("Failed to start consumer", zap.Error(err))
 	}
+	log.Info("Consuming chat messages", zap.String("consumer", serviceName))
 
 	lis, err := net.Listen("tcp", ":"+cfg.NotificationPort)
 	if err != nil {
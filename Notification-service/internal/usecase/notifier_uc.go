@@ -28,23 +28,37 @@ func NewNotifier(authClient interceptor.AuthClientInterface, emailSender *handle
 	}
 }
 
-func (n *Notifier) Notify(ctx context.Context, msg *proto_gen.Message) {
+// Notify emails the members of the chat about a new message. An error asks the
+// broker to redeliver the message later.
+func (n *Notifier) Notify(event *proto_gen.ChatEvent) error {
+	msg := event.GetMessage()
+	if event.Type != proto_gen.ChatEventType_MessageCreatedEvent || msg.Text == "" {
+		// poll tally updates, system events and ephemeral command replies
+		// are not new messages from chat members
+		return nil
+	}
+
+	ctx := context.Background()
+
 	n.log.Info("Processing message", zap.String("message", msg.Text))
 
 	emails, err := n.authClient.GetChatUsersEmails(ctx, msg.ChatId)
 	if err != nil {
 		n.log.Error("Failed to get emails from auth service", zap.Error(err))
-		return
+		return err
 	}
 
 	n.log.Info("Sending notifications", zap.Int("emailCount", len(emails)))
 	for _, email := range emails {
 		err := n.emailSender.Send(email, "New message in chat", msg.Text)
 		if err != nil {
+			// a redelivery would email the members that were already notified
 			n.log.Error("Failed to send email", zap.Error(err))
-			return
+			return nil
 		} else {
 			n.log.Info("Email sent", zap.String("email", email))
 		}
 	}
+
+	return nil
 }
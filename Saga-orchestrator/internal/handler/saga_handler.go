@@ -21,10 +21,11 @@ func NewSagaHandler(service *usecase.SagaService, log *zap.Logger) *SagaHandler
 
 func (h *SagaHandler) StartSaga(ctx context.Context, req *pb.StartSagaRequest) (*emptypb.Empty, error) {
 	msg := &usecase.Message{
-		ID:     req.GetMessageId(),
-		Text:   req.GetText(),
-		Emails: req.GetEmails(),
-		ChatID: req.GetChatId(),
+		ID:       req.GetMessageId(),
+		Text:     req.GetText(),
+		Emails:   req.GetEmails(),
+		ChatID:   req.GetChatId(),
+		ClientID: req.GetClientMessageId(),
 	}
 
 	userID, err := h.sagaService.GetUserIdFromEmail(ctx, msg.Emails[0])
@@ -20,10 +20,23 @@ func (r *SagaRepo) DeleteMessage(messageID int64) error {
 	return err
 }
 
-func (r *SagaRepo) SaveMessage(messageID int64, chatID int64, text string, userID int64) error {
-	query := `INSERT INTO messages (id, chat_id, text, user_id) VALUES ($1, $2, $3, $4)`
-	_, err := r.db.Exec(query, messageID, chatID, text, userID)
-	return err
+// SaveMessage returns false if a message with the same client id was already
+// saved by a previous run of the saga.
+func (r *SagaRepo) SaveMessage(messageID int64, chatID int64, text string, userID int64, clientID string) (bool, error) {
+	var nullClientID sql.NullString
+	if clientID != "" {
+		nullClientID = sql.NullString{String: clientID, Valid: true}
+	}
+
+	query := `INSERT INTO messages (id, chat_id, text, user_id, client_id) VALUES ($1, $2, $3, $4, $5)
+			  ON CONFLICT (chat_id, user_id, client_id) DO NOTHING`
+	res, err := r.db.Exec(query, messageID, chatID, text, userID, nullClientID)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	return n > 0, err
 }
 
 func (r *SagaRepo) GetUserIdFromEmail(email string) (int64, error) {
@@ -10,10 +10,11 @@ import (
 )
 
 type Message struct {
-	ID     int64
-	ChatID int64
-	Text   string
-	Emails []string
+	ID       int64
+	ChatID   int64
+	Text     string
+	Emails   []string
+	ClientID string
 }
 
 type SagaService struct {
@@ -33,11 +34,16 @@ func NewSagaService(repo *repository.SagaRepo, notifier client.NotificationClien
 func (s *SagaService) SendMessageWithNotification(ctx context.Context, msg *Message, chatID, userID int64) error {
 	s.log.Info("Saving message", zap.Int64("message_id", msg.ID))
 
-	err := s.repo.SaveMessage(msg.ID, chatID, msg.Text, userID)
+	saved, err := s.repo.SaveMessage(msg.ID, chatID, msg.Text, userID, msg.ClientID)
 	if err != nil {
 		s.log.Error("Failed to save message", zap.Error(err))
 		return err
 	}
+	if !saved {
+		// the saga was retried by the client, notifications were sent by the first run
+		s.log.Info("Duplicate saga skipped", zap.Int64("message_id", msg.ID), zap.String("client_id", msg.ClientID))
+		return nil
+	}
 
 	s.log.Info("Sending notifications", zap.Int("email_count", len(msg.Emails)))
 
@@ -3,6 +3,8 @@ package main
 import (
 	"bufio"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"strconv"
@@ -10,14 +12,17 @@ import (
 	"sync"
 	"time"
 
+	"chat-grpc/pkg/broker"
 	"chat-grpc/pkg/config"
 	"chat-grpc/proto_gen"
 	"github.com/nats-io/nats.go"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
-	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 var (
@@ -67,7 +72,8 @@ func main() {
 	fmt.Println("  get_access - Получить access token")
 	fmt.Println("  create_chat <user1,user2,...> - Создать чат")
 	fmt.Println("  send_message <chat_id> <from> <text> - Отправить сообщение")
-	fmt.Println("  connect <chat_id> - Подключиться к чату")
+	fmt.Println("  send <chat_id> <from> <text> - Отправить сообщение напрямую в Chat-service")
+	fmt.Println("  connect <chat_id> [username] - Подключиться к чату")
 	fmt.Println("  exit - Выйти")
 
 	scanner := bufio.NewScanner(os.Stdin)
@@ -110,6 +116,76 @@ func main() {
 				log.Error("Failed to get access token", zap.Error(err))
 			}
 
+		case "sessions":
+			err := listSessions()
+			if err != nil {
+				log.Error("Failed to list sessions", zap.Error(err))
+				fmt.Println("Ошибка получения сессий:", err)
+			}
+
+		case "revoke_session":
+			if len(args) < 2 {
+				fmt.Println("Формат: revoke_session <session_id>")
+				continue
+			}
+			sessionID, err := strconv.ParseInt(args[1], 10, 64)
+			if err != nil {
+				log.Warn("Invalid session ID", zap.String("input", args[1]))
+				continue
+			}
+			err = revokeSession(sessionID)
+			if err != nil {
+				log.Error("Failed to revoke session", zap.Error(err))
+				fmt.Println("Ошибка завершения сессии:", err)
+			}
+
+		case "revoke_other_sessions":
+			err := revokeOtherSessions()
+			if err != nil {
+				log.Error("Failed to revoke sessions", zap.Error(err))
+				fmt.Println("Ошибка завершения сессий:", err)
+			}
+
+		case "change_password":
+			if len(args) < 3 {
+				fmt.Println("Формат: change_password <old_password> <new_password>")
+				continue
+			}
+			err := changePassword(args[1], args[2])
+			if err != nil {
+				log.Error("Failed to change password", zap.Error(err))
+				fmt.Println("Ошибка смены пароля:", err)
+			}
+
+		case "request_password_reset":
+			if len(args) < 2 {
+				fmt.Println("Формат: request_password_reset <email>")
+				continue
+			}
+			err := requestPasswordReset(args[1])
+			if err != nil {
+				log.Error("Failed to request password reset", zap.Error(err))
+				fmt.Println("Ошибка запроса сброса пароля:", err)
+			}
+
+		case "reset_password":
+			if len(args) < 3 {
+				fmt.Println("Формат: reset_password <token> <new_password>")
+				continue
+			}
+			err := resetPassword(args[1], args[2])
+			if err != nil {
+				log.Error("Failed to reset password", zap.Error(err))
+				fmt.Println("Ошибка сброса пароля:", err)
+			}
+
+		case "logout":
+			err := logout()
+			if err != nil {
+				log.Error("Logout failed", zap.Error(err))
+				fmt.Println("Ошибка выхода:", err)
+			}
+
 		case "create_chat":
 			if len(args) < 2 {
 				fmt.Println("Формат: create_chat <user1,user2,...>")
@@ -131,7 +207,6 @@ func main() {
 				log.Warn("Invalid chat ID", zap.String("input", args[1]))
 				continue
 			}
-			//err = sendMessage(chatID, args[2], strings.Join(args[3:], " "))
 
 			log.Info("Sending message via saga", zap.Int64("chat_id", chatID), zap.String("from", args[2]))
 
@@ -146,10 +221,15 @@ func main() {
 				continue
 			}
 
-			_, err = sagaClient.StartSaga(ctx, &proto_gen.StartSagaRequest{
-				MessageId: chatID,
-				Text:      strings.Join(args[3:], " "),
-				Emails:    emailResp.Emails,
+			req := &proto_gen.StartSagaRequest{
+				MessageId:       chatID,
+				Text:            strings.Join(args[3:], " "),
+				Emails:          emailResp.Emails,
+				ClientMessageId: newClientMessageID(),
+			}
+			err = withRetry(func(ctx context.Context) error {
+				_, err := sagaClient.StartSaga(ctx, req)
+				return err
 			})
 			if err != nil {
 				log.Error("Failed to send message saga", zap.Error(err))
@@ -158,9 +238,25 @@ func main() {
 				fmt.Println("Сообщение отправлено через сагу")
 			}
 
+		case "send":
+			if len(args) < 4 {
+				fmt.Println("Формат: send <chat_id> <from> <text>")
+				continue
+			}
+			chatID, err := strconv.ParseInt(args[1], 10, 64)
+			if err != nil {
+				log.Warn("Invalid chat ID", zap.String("input", args[1]))
+				continue
+			}
+			err = sendMessage(chatID, args[2], strings.Join(args[3:], " "))
+			if err != nil {
+				log.Error("Failed to send message", zap.Error(err))
+				fmt.Println("Ошибка отправки сообщения:", err)
+			}
+
 		case "connect":
 			if len(args) < 2 {
-				fmt.Println("Формат: connect <chat_id>")
+				fmt.Println("Формат: connect <chat_id> [username]")
 				continue
 			}
 			chatID, err := strconv.ParseInt(args[1], 10, 64)
@@ -168,7 +264,11 @@ func main() {
 				log.Warn("Invalid chat ID", zap.String("input", args[1]))
 				continue
 			}
-			connectToChat(chatID)
+			username := ""
+			if len(args) > 2 {
+				username = args[2]
+			}
+			connectToChat(chatID, username)
 
 		case "exit":
 			log.Info("Exiting CLI")
@@ -209,9 +309,11 @@ func login(email, password string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	device, _ := os.Hostname()
 	resp, err := authClient.Login(ctx, &proto_gen.LoginRequest{
-		Email:    email,
-		Password: password,
+		Email:      email,
+		Password:   password,
+		DeviceName: "chat-cli@" + device,
 	})
 	if err != nil {
 		return fmt.Errorf("ошибка авторизации: %w", err)
@@ -247,6 +349,112 @@ func getAccess() error {
 	return nil
 }
 
+func listSessions() error {
+	if getAccessToken() == "" {
+		return fmt.Errorf("необходимо получить access token")
+	}
+
+	resp, err := authClient.ListSessions(authContext(), &proto_gen.AuthEmpty{})
+	if err != nil {
+		return err
+	}
+
+	for _, s := range resp.Sessions {
+		current := ""
+		if s.Current {
+			current = " (текущая)"
+		}
+		fmt.Printf("%d  %s  %s  последняя активность %s%s\n", s.Id, s.DeviceName, s.Ip,
+			s.LastUsedAt.AsTime().Local().Format("02.01 15:04"), current)
+	}
+	return nil
+}
+
+func revokeSession(sessionID int64) error {
+	if getAccessToken() == "" {
+		return fmt.Errorf("необходимо получить access token")
+	}
+
+	if _, err := authClient.RevokeSession(authContext(), &proto_gen.RevokeSessionRequest{SessionId: sessionID}); err != nil {
+		return err
+	}
+
+	fmt.Println("Сессия завершена")
+	return nil
+}
+
+func revokeOtherSessions() error {
+	if getAccessToken() == "" {
+		return fmt.Errorf("необходимо получить access token")
+	}
+
+	if _, err := authClient.RevokeAllOtherSessions(authContext(), &proto_gen.AuthEmpty{}); err != nil {
+		return err
+	}
+
+	fmt.Println("Остальные сессии завершены")
+	return nil
+}
+
+func logout() error {
+	if getAccessToken() == "" {
+		return fmt.Errorf("необходимо получить access token")
+	}
+
+	if _, err := authClient.Logout(authContext(), &proto_gen.AuthEmpty{}); err != nil {
+		return err
+	}
+
+	setAccessToken("")
+	setRefreshToken("")
+	fmt.Println("Вы вышли из аккаунта")
+	return nil
+}
+
+func changePassword(oldPassword, newPassword string) error {
+	if getAccessToken() == "" {
+		return fmt.Errorf("необходимо получить access token")
+	}
+
+	_, err := authClient.ChangePassword(authContext(), &proto_gen.ChangePasswordRequest{
+		OldPassword: oldPassword,
+		NewPassword: newPassword,
+	})
+	if err != nil {
+		return err
+	}
+
+	setAccessToken("")
+	setRefreshToken("")
+	fmt.Println("Пароль изменён, войдите заново")
+	return nil
+}
+
+func requestPasswordReset(email string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := authClient.RequestPasswordReset(ctx, &proto_gen.RequestPasswordResetRequest{Email: email}); err != nil {
+		return err
+	}
+
+	fmt.Println("Если адрес зарегистрирован, на него отправлена ссылка для сброса пароля")
+	return nil
+}
+
+func resetPassword(token, newPassword string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := authClient.ResetPassword(ctx, &proto_gen.ResetPasswordRequest{Token: token, NewPassword: newPassword})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Пароль изменён, войдите с новым паролем")
+	return nil
+}
+
 func createChat(users []string) error {
 	ctx := authContext()
 	if ctx == nil {
@@ -265,7 +473,75 @@ func createChat(users []string) error {
 	return nil
 }
 
-func connectToChat(chatID int64) {
+func sendMessage(chatID int64, from, text string) error {
+	if authContext() == nil {
+		return fmt.Errorf("необходимо получить access_token")
+	}
+
+	req := &proto_gen.SendMessageRequest{
+		ChatId:          chatID,
+		From:            from,
+		Text:            text,
+		Timestamp:       timestamppb.Now(),
+		ClientMessageId: newClientMessageID(),
+	}
+
+	var resp *proto_gen.SendMessageResponse
+	err := withRetry(func(ctx context.Context) error {
+		var err error
+		resp, err = chatClient.SendMessage(ctx, req)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Сообщение отправлено, ID:", resp.MessageId)
+	return nil
+}
+
+// newClientMessageID returns a random idempotency key. Every retry of one send
+// reuses it, so the server stores the message only once.
+func newClientMessageID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+const (
+	sendAttempts = 3
+	sendTimeout  = 5 * time.Second
+)
+
+// withRetry repeats a send while it times out or the service is unavailable.
+func withRetry(send func(ctx context.Context) error) error {
+	var err error
+	for attempt := 1; attempt <= sendAttempts; attempt++ {
+		parent := authContext()
+		if parent == nil {
+			parent = context.Background()
+		}
+
+		ctx, cancel := context.WithTimeout(parent, sendTimeout)
+		err = send(ctx)
+		cancel()
+
+		code := status.Code(err)
+		if code != codes.DeadlineExceeded && code != codes.Unavailable {
+			return err
+		}
+
+		log.Warn("Send failed", zap.Int("attempt", attempt), zap.Error(err))
+		if attempt < sendAttempts {
+			time.Sleep(time.Duration(attempt) * 500 * time.Millisecond)
+		}
+	}
+
+	return err
+}
+
+func connectToChat(chatID int64, username string) {
 	ctx := authContext()
 	if ctx == nil {
 		log.Warn("Access token is missing")
@@ -285,7 +561,7 @@ func connectToChat(chatID int64) {
 
 	fmt.Println("История чата:")
 	for _, msg := range historyResp.Messages {
-		fmt.Printf("[%s] %s: %s\n", msg.Timestamp.AsTime().Format("15:04"), msg.From, msg.Text)
+		printMessage(msg)
 	}
 
 	nc, err := nats.Connect(nats.DefaultURL)
@@ -297,13 +573,15 @@ func connectToChat(chatID int64) {
 
 	subject := fmt.Sprintf("chat.%d", chatID)
 	_, err = nc.Subscribe(subject, func(msg *nats.Msg) {
-		var m proto_gen.Message
-		if err := protojson.Unmarshal(msg.Data, &m); err != nil {
-			log.Error("Failed to parse protojson message", zap.Error(err))
+		event, err := broker.DecodeEvent(msg)
+		if err != nil {
+			log.Error("Failed to parse chat event", zap.Error(err))
 			return
 		}
 
+		m := event.GetMessage()
 		log.Info("incoming message (NATS)",
+			zap.String("event", event.Type.String()),
 			zap.String("from", m.From),
 			zap.String("text", m.Text))
 	})
@@ -312,7 +590,7 @@ func connectToChat(chatID int64) {
 		return
 	}
 
-	stream, err := chatClient.Connect(ctx, &proto_gen.ConnectRequest{ChatId: chatID})
+	stream, err := chatClient.Connect(ctx, &proto_gen.ConnectRequest{ChatId: chatID, From: username})
 	if err != nil {
 		log.Error("Failed to connect to chat stream", zap.Error(err))
 		fmt.Println("Ошибка подключения к чату:", err)
@@ -331,7 +609,7 @@ func connectToChat(chatID int64) {
 				return
 			}
 
-			fmt.Printf("[%s] %s: %s\n", msg.Timestamp.AsTime().Format("15:04"), msg.From, msg.Text)
+			printMessage(msg)
 		}
 	}()
 
@@ -346,6 +624,33 @@ func connectToChat(chatID int64) {
 	}()
 }
 
+func printMessage(msg *proto_gen.Message) {
+	if msg.Text != "" {
+		fmt.Printf("[%s] %s: %s\n", msg.Timestamp.AsTime().Format("15:04"), msg.From, msg.Text)
+	}
+
+	switch payload := msg.Payload.(type) {
+	case *proto_gen.Message_Poll:
+		poll := payload.Poll
+		state := "открыт"
+		if poll.Closed {
+			state = "закрыт"
+		}
+		fmt.Printf("  Опрос #%d (%s), проголосовало: %d\n", poll.Id, state, poll.TotalVoters)
+		for _, option := range poll.Options {
+			fmt.Printf("    %d) %s — %d\n", option.Index, option.Text, option.Votes)
+		}
+	case *proto_gen.Message_LinkPreview:
+		fmt.Printf("  %s — %s\n  %s\n", payload.LinkPreview.Title, payload.LinkPreview.Description, payload.LinkPreview.Url)
+	case *proto_gen.Message_Attachment:
+		fmt.Printf("  Вложение: %s (%s)\n", payload.Attachment.FileName, payload.Attachment.Url)
+	case *proto_gen.Message_CodeSnippet:
+		fmt.Printf("  ```%s\n%s\n  ```\n", payload.CodeSnippet.Language, payload.CodeSnippet.Code)
+	case *proto_gen.Message_SystemEvent:
+		fmt.Printf("  * %s: %s\n", payload.SystemEvent.Actor, payload.SystemEvent.Type)
+	}
+}
+
 func setRefreshToken(token string) {
 	mu.Lock()
 	defer mu.Unlock()
@@ -384,6 +689,8 @@ func parseRole(roleStr string) (proto_gen.Role, error) {
 		return proto_gen.Role_AdminRole, nil
 	case "user":
 		return proto_gen.Role_UserRole, nil
+	case "moderator":
+		return proto_gen.Role_ModeratorRole, nil
 	default:
 		return proto_gen.Role_UserRole, fmt.Errorf("unknown role: %s", roleStr)
 	}
@@ -0,0 +1 @@
+ALTER TABLE chat_users DROP COLUMN role, DROP COLUMN muted_until;
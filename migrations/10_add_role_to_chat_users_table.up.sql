@@ -0,0 +1,3 @@
+ALTER TABLE chat_users
+    ADD COLUMN IF NOT EXISTS role VARCHAR(16) NOT NULL DEFAULT 'member' CHECK (role IN ('admin', 'member')),
+    ADD COLUMN IF NOT EXISTS muted_until TIMESTAMP;
@@ -0,0 +1,3 @@
+DELETE FROM users WHERE role = 'bot';
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('admin', 'user'));
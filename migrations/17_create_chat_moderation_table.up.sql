@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS chat_moderation (
+    chat_id INT PRIMARY KEY REFERENCES chats(id) ON DELETE CASCADE,
+    action VARCHAR(16) NOT NULL DEFAULT 'flag' CHECK (action IN ('flag', 'mask', 'block', 'off')),
+    words TEXT[] NOT NULL DEFAULT '{}',
+    patterns TEXT[] NOT NULL DEFAULT '{}',
+    blocked_domains TEXT[] NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1 @@
+ALTER TABLE messages DROP COLUMN flagged, DROP COLUMN flag_reason;
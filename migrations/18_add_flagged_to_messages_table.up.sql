@@ -0,0 +1,3 @@
+ALTER TABLE messages
+    ADD COLUMN IF NOT EXISTS flagged BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS flag_reason TEXT;
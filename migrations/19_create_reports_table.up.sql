@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS reports (
+    id SERIAL PRIMARY KEY,
+    message_id INT NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    chat_id INT NOT NULL,
+    reporter_id BIGINT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    resolved BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP,
+    UNIQUE (message_id, reporter_id)
+);
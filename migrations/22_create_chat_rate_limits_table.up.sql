@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS chat_rate_limits (
+    chat_id INT PRIMARY KEY REFERENCES chats(id) ON DELETE CASCADE,
+    user_per_minute INT NOT NULL CHECK (user_per_minute >= 0),
+    user_burst INT NOT NULL CHECK (user_burst >= 0),
+    chat_per_minute INT NOT NULL CHECK (chat_per_minute >= 0),
+    chat_burst INT NOT NULL CHECK (chat_burst >= 0),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
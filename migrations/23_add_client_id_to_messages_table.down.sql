@@ -0,0 +1 @@
+ALTER TABLE messages DROP CONSTRAINT messages_client_id_key, DROP COLUMN client_id;
@@ -0,0 +1,3 @@
+ALTER TABLE messages
+    ADD COLUMN IF NOT EXISTS client_id TEXT,
+    ADD CONSTRAINT messages_client_id_key UNIQUE (chat_id, user_id, client_id);
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS revoked_users (
+    user_id BIGINT PRIMARY KEY,
+    revoked_before TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,4 @@
+UPDATE users SET role = 'user' WHERE role = 'moderator';
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('admin', 'user', 'bot'));
+ALTER TABLE users ALTER COLUMN role TYPE VARCHAR(5);
@@ -0,0 +1,3 @@
+ALTER TABLE users ALTER COLUMN role TYPE VARCHAR(16);
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('admin', 'user', 'bot', 'moderator'));
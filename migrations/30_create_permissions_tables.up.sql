@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS method_permissions (
+    method TEXT PRIMARY KEY,
+    permission VARCHAR(64) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role VARCHAR(16) NOT NULL,
+    permission VARCHAR(64) NOT NULL,
+    PRIMARY KEY (role, permission)
+);
+
+INSERT INTO method_permissions (method, permission) VALUES
+    ('/auth.AuthService/GetList', 'users.read'),
+    ('/auth.AuthService/Delete', 'users.manage')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'users.read'),
+    ('admin', 'users.manage'),
+    ('moderator', 'users.read')
+ON CONFLICT DO NOTHING;
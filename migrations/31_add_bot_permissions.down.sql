@@ -0,0 +1,2 @@
+DELETE FROM role_permissions WHERE permission = 'bots.manage';
+DELETE FROM method_permissions WHERE permission = 'bots.manage';
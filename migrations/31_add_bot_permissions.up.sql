@@ -0,0 +1,8 @@
+INSERT INTO method_permissions (method, permission) VALUES
+    ('/auth.AuthService/CreateBot', 'bots.manage'),
+    ('/auth.AuthService/RotateBotKey', 'bots.manage')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'bots.manage')
+ON CONFLICT DO NOTHING;
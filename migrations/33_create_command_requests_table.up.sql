@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS command_requests (
+    chat_id INT NOT NULL,
+    user_id INT NOT NULL,
+    client_id TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (chat_id, user_id, client_id)
+);
+
+CREATE INDEX IF NOT EXISTS command_requests_created_at_idx ON command_requests (created_at);
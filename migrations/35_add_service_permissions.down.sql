@@ -0,0 +1,6 @@
+DELETE FROM role_permissions WHERE role = 'service';
+DELETE FROM method_permissions WHERE method IN (
+    '/auth.AuthService/GetChatUsersEmails',
+    '/auth.AuthService/GetChatUsers',
+    '/auth.AuthService/GetUsersEmailsByID'
+);
@@ -0,0 +1,9 @@
+INSERT INTO method_permissions (method, permission) VALUES
+    ('/auth.AuthService/GetChatUsersEmails', 'users.read'),
+    ('/auth.AuthService/GetChatUsers', 'users.read'),
+    ('/auth.AuthService/GetUsersEmailsByID', 'users.read')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('service', 'users.read')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS poll_votes (
+    poll_id INT NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL,
+    option_index INT NOT NULL,
+    voted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (poll_id, user_id, option_index)
+);
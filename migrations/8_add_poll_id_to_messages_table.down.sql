@@ -0,0 +1 @@
+ALTER TABLE messages DROP COLUMN poll_id;
@@ -0,0 +1 @@
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS poll_id INT REFERENCES polls(id) ON DELETE SET NULL;
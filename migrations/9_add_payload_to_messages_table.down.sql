@@ -0,0 +1 @@
+ALTER TABLE messages DROP COLUMN kind, DROP COLUMN payload, DROP COLUMN metadata;
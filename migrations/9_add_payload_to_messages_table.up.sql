@@ -0,0 +1,6 @@
+ALTER TABLE messages
+    ADD COLUMN IF NOT EXISTS kind VARCHAR(32) NOT NULL DEFAULT 'text',
+    ADD COLUMN IF NOT EXISTS payload JSONB,
+    ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}';
+
+UPDATE messages SET kind = 'poll' WHERE poll_id IS NOT NULL;
@@ -0,0 +1,58 @@
+package broker
+
+import (
+	"errors"
+	"fmt"
+
+	"chat-grpc/pkg/config"
+	"chat-grpc/proto_gen"
+	"go.uber.org/zap"
+)
+
+const (
+	NatsDriver      = "nats"
+	JetStreamDriver = "jetstream"
+	MemoryDriver    = "memory"
+)
+
+// ErrMemoryDriver is returned by New for BROKER_DRIVER=memory: every service runs
+// in a process of its own, and an in-process broker would silently keep their
+// events from each other.
+var ErrMemoryDriver = errors.New("the memory broker only connects brokers of one process, use NewMemoryHub there")
+
+// Subscription stops the delivery of messages to its handler.
+type Subscription interface {
+	Unsubscribe() error
+}
+
+// Broker carries chat events. Publish wraps the message into a ChatEvent
+// envelope, subscribers receive the decoded envelope.
+type Broker interface {
+	Publish(msg *proto_gen.Message) error
+	Subscribe(subject string, handler func(*proto_gen.ChatEvent)) (Subscription, error)
+	QueueSubscribe(subject, queue string, handler func(*proto_gen.ChatEvent)) (Subscription, error)
+	// Consume delivers messages to the named durable consumer. Replicas using the
+	// same name share the messages, and a handler error asks for a redelivery.
+	Consume(subject, durable string, handler func(*proto_gen.ChatEvent) error) (Subscription, error)
+	Flush() error
+	Close() error
+}
+
+// New connects to the broker selected by BROKER_DRIVER. The producer names the
+// service in the envelope of the events it publishes.
+func New(cfg *config.Config, producer string, log *zap.Logger) (Broker, error) {
+	switch cfg.BrokerDriver {
+	case NatsDriver:
+		return NewNatsBroker(cfg.NatsUrl, producer, log)
+	case JetStreamDriver:
+		return NewJetStreamBroker(cfg.NatsUrl, producer, JetStreamConfig{
+			MaxDeliver: cfg.JetStreamMaxDeliver,
+			AckWait:    cfg.JetStreamAckWait,
+			MaxAge:     cfg.JetStreamMaxAge,
+		}, log)
+	case MemoryDriver:
+		return nil, ErrMemoryDriver
+	default:
+		return nil, fmt.Errorf("unknown broker driver %q", cfg.BrokerDriver)
+	}
+}
@@ -0,0 +1,148 @@
+package broker
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"chat-grpc/proto_gen"
+	"github.com/nats-io/nats.go"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// EventVersion is the version of the ChatEvent envelope written by this code.
+	EventVersion = 1
+
+	ContentTypeHeader = "Content-Type"
+	// EventContentType marks a binary ChatEvent. Messages without it are the
+	// protojson encoded proto_gen.Message of older producers.
+	EventContentType = "application/protobuf; type=chat.ChatEvent; v=1"
+)
+
+var membershipEvents = map[string]bool{
+	"member_added": true,
+	"member_muted": true,
+	"bot_added":    true,
+	"user_banned":  true,
+}
+
+// EventType tells what happened to the chat by looking at the message.
+func EventType(msg *proto_gen.Message) proto_gen.ChatEventType {
+	if msg.Recipient != "" {
+		return proto_gen.ChatEventType_EphemeralMessageEvent
+	}
+
+	if event := msg.GetSystemEvent(); event != nil {
+		switch {
+		case event.Type == "message_removed":
+			return proto_gen.ChatEventType_MessageRemovedEvent
+		case membershipEvents[event.Type]:
+			return proto_gen.ChatEventType_MembershipChangedEvent
+		default:
+			return proto_gen.ChatEventType_ChatUpdatedEvent
+		}
+	}
+
+	// tally updates of a poll carry no text
+	if msg.GetPoll() != nil && msg.Text == "" {
+		return proto_gen.ChatEventType_PollUpdatedEvent
+	}
+
+	// link previews are fetched after the message is stored and sent as an update
+	if msg.GetLinkPreview() != nil {
+		return proto_gen.ChatEventType_MessageUpdatedEvent
+	}
+
+	return proto_gen.ChatEventType_MessageCreatedEvent
+}
+
+// NewEvent wraps the message into an envelope. Stored messages get an event id
+// derived from the message id, so a republished message keeps its id, and its
+// update gets an id of its own.
+func NewEvent(msg *proto_gen.Message, producer string) *proto_gen.ChatEvent {
+	occurredAt := msg.Timestamp
+	if occurredAt == nil {
+		occurredAt = timestamppb.Now()
+	}
+
+	eventType := EventType(msg)
+
+	return &proto_gen.ChatEvent{
+		Version:    EventVersion,
+		Id:         eventID(msg, eventType),
+		Type:       eventType,
+		OccurredAt: occurredAt,
+		Producer:   producer,
+		ChatId:     msg.ChatId,
+		Payload:    &proto_gen.ChatEvent_Message{Message: msg},
+	}
+}
+
+func eventID(msg *proto_gen.Message, eventType proto_gen.ChatEventType) string {
+	if msg.Id != 0 && eventType == proto_gen.ChatEventType_MessageUpdatedEvent {
+		return fmt.Sprintf("message-%d-updated", msg.Id)
+	}
+	if msg.Id != 0 {
+		return fmt.Sprintf("message-%d", msg.Id)
+	}
+
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+// Subject is the subject the events of a chat are published on.
+func Subject(chatID int64) string {
+	return fmt.Sprintf("chat.%d", chatID)
+}
+
+// EncodeEvent builds the NATS message for the event. The event id doubles as
+// the JetStream deduplication id.
+func EncodeEvent(event *proto_gen.ChatEvent) (*nats.Msg, error) {
+	data, err := proto.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	m := nats.NewMsg(Subject(event.ChatId))
+	m.Data = data
+	m.Header.Set(ContentTypeHeader, EventContentType)
+	m.Header.Set(nats.MsgIdHdr, event.Id)
+
+	return m, nil
+}
+
+// DecodeEvent reads a ChatEvent, or wraps a legacy JSON message into one.
+func DecodeEvent(m *nats.Msg) (*proto_gen.ChatEvent, error) {
+	if len(m.Data) > MaxEventSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrEventTooLarge, len(m.Data))
+	}
+
+	contentType := ""
+	if m.Header != nil {
+		contentType = m.Header.Get(ContentTypeHeader)
+	}
+
+	if strings.HasPrefix(contentType, "application/protobuf") {
+		var event proto_gen.ChatEvent
+		if err := proto.Unmarshal(m.Data, &event); err != nil {
+			return nil, err
+		}
+		if event.GetMessage() == nil {
+			return nil, fmt.Errorf("event %s of version %d has no known payload", event.Id, event.Version)
+		}
+		return &event, nil
+	}
+
+	var msg proto_gen.Message
+	if err := protojson.Unmarshal(m.Data, &msg); err != nil {
+		return nil, err
+	}
+
+	return NewEvent(&msg, ""), nil
+}
@@ -0,0 +1,178 @@
+package broker
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"chat-grpc/proto_gen"
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+const (
+	// StreamName is the stream that keeps the events of every chat, so durable
+	// consumers get what was published while they were down.
+	StreamName     = "CHAT"
+	StreamSubjects = "chat.>"
+
+	// Messages a consumer gave up on are moved to DeadLetterPrefix + the original
+	// subject, e.g. "dead.chat.42", and kept in their own stream for inspection.
+	DeadLetterStreamName = "CHAT_DEAD"
+	DeadLetterPrefix     = "dead."
+
+	DeadLetterSubjectHeader  = "Chat-Original-Subject"
+	DeadLetterConsumerHeader = "Chat-Consumer"
+	DeadLetterErrorHeader    = "Chat-Error"
+
+	// QuarantineStreamName keeps the undecodable messages set aside on QuarantinePrefix.
+	QuarantineStreamName = "CHAT_QUARANTINE"
+
+	redeliveryDelay = time.Second
+)
+
+type JetStreamConfig struct {
+	// MaxDeliver is how many times a message is delivered to a consumer before
+	// it is moved to the dead-letter subject.
+	MaxDeliver int
+	AckWait    time.Duration
+	// MaxAge is how long the stream keeps messages, zero keeps them forever.
+	MaxAge time.Duration
+}
+
+// jetStreamBroker publishes into the CHAT stream. Live subscriptions stay on
+// core NATS, only Consume uses durable JetStream consumers.
+type jetStreamBroker struct {
+	*natsBroker
+	cfg JetStreamConfig
+}
+
+// NewJetStreamBroker connects to a NATS server with JetStream enabled and creates
+// or updates the streams. The url may point to an embedded server in tests.
+func NewJetStreamBroker(url, producer string, cfg JetStreamConfig, log *zap.Logger) (Broker, error) {
+	nc, err := nats.Connect(url)
+	if err != nil {
+		return nil, err
+	}
+
+	js, err := nc.JetStream()
+	if err != nil {
+		nc.Close()
+		return nil, err
+	}
+
+	streams := []*nats.StreamConfig{
+		{Name: StreamName, Subjects: []string{StreamSubjects}, Storage: nats.FileStorage, MaxAge: cfg.MaxAge},
+		{Name: DeadLetterStreamName, Subjects: []string{DeadLetterPrefix + StreamSubjects}, Storage: nats.FileStorage},
+		{Name: QuarantineStreamName, Subjects: []string{QuarantinePrefix + StreamSubjects}, Storage: nats.FileStorage},
+	}
+	for _, stream := range streams {
+		if err := ensureStream(js, stream); err != nil {
+			nc.Close()
+			return nil, fmt.Errorf("failed to set up stream %s: %w", stream.Name, err)
+		}
+	}
+
+	return &jetStreamBroker{natsBroker: &natsBroker{conn: nc, producer: producer, log: log, js: js}, cfg: cfg}, nil
+}
+
+func ensureStream(js nats.JetStreamContext, cfg *nats.StreamConfig) error {
+	_, err := js.AddStream(cfg)
+	if errors.Is(err, nats.ErrStreamNameAlreadyInUse) {
+		_, err = js.UpdateStream(cfg)
+	}
+
+	return err
+}
+
+// Publish returns once the stream has stored the event. The stream drops an
+// event republished by the outbox within its duplicate window.
+func (b *jetStreamBroker) Publish(msg *proto_gen.Message) error {
+	m, err := EncodeEvent(NewEvent(msg, b.producer))
+	if err != nil {
+		return err
+	}
+
+	_, err = b.js.PublishMsg(m)
+	return err
+}
+
+// Consume binds to a durable consumer of the CHAT stream. A consumer created
+// for the first time starts with new messages instead of the whole history.
+// MaxDeliver is enforced here rather than by the server, so a message that could
+// not be dead-lettered is still redelivered instead of silently dropped.
+func (b *jetStreamBroker) Consume(subject, durable string, handler func(*proto_gen.ChatEvent) error) (Subscription, error) {
+	return subscription(b.js.QueueSubscribe(subject, durable, func(m *nats.Msg) {
+		b.process(m, durable, handler)
+	},
+		nats.BindStream(StreamName),
+		nats.Durable(durable),
+		nats.DeliverNew(),
+		nats.ManualAck(),
+		nats.AckExplicit(),
+		nats.AckWait(b.cfg.AckWait),
+	))
+}
+
+func (b *jetStreamBroker) process(m *nats.Msg, durable string, handler func(*proto_gen.ChatEvent) error) {
+	event, err := DecodeEvent(m)
+	if err != nil {
+		// a redelivery cannot fix a message that does not parse, but it is only
+		// dropped once the quarantine stream has it
+		if err := b.quarantine(m, err); err != nil {
+			m.NakWithDelay(redeliveryDelay)
+			return
+		}
+		if err := m.Term(); err != nil {
+			b.log.Warn("Failed to terminate message", zap.String("subject", m.Subject), zap.Error(err))
+		}
+		return
+	}
+
+	err = handler(event)
+	if err == nil {
+		if err := m.Ack(); err != nil {
+			b.log.Warn("Failed to ack message", zap.String("subject", m.Subject), zap.Error(err))
+		}
+		return
+	}
+
+	meta, metaErr := m.Metadata()
+	if metaErr == nil && int(meta.NumDelivered) >= b.cfg.MaxDeliver {
+		b.deadLetter(m, durable, err)
+		return
+	}
+
+	b.log.Warn("Consumer failed to handle message, redelivering", zap.String("consumer", durable),
+		zap.String("subject", m.Subject), zap.Error(err))
+	if err := m.NakWithDelay(redeliveryDelay); err != nil {
+		b.log.Warn("Failed to nak message", zap.String("subject", m.Subject), zap.Error(err))
+	}
+}
+
+// deadLetter moves the message to the dead-letter stream and terminates it. If
+// that fails the message is redelivered rather than lost.
+func (b *jetStreamBroker) deadLetter(m *nats.Msg, durable string, cause error) {
+	dead := nats.NewMsg(DeadLetterPrefix + m.Subject)
+	dead.Data = m.Data
+	for key, values := range m.Header {
+		dead.Header[key] = values
+	}
+	// the original id would make the dead-letter stream drop it as a duplicate
+	dead.Header.Del(nats.MsgIdHdr)
+	dead.Header.Set(DeadLetterSubjectHeader, m.Subject)
+	dead.Header.Set(DeadLetterConsumerHeader, durable)
+	dead.Header.Set(DeadLetterErrorHeader, cause.Error())
+
+	if _, err := b.js.PublishMsg(dead); err != nil {
+		b.log.Error("Failed to dead-letter message", zap.String("subject", m.Subject), zap.Error(err))
+		m.NakWithDelay(redeliveryDelay)
+		return
+	}
+
+	b.log.Error("Consumer moved message to the dead-letter stream", zap.String("consumer", durable),
+		zap.String("subject", m.Subject), zap.String("dead_subject", dead.Subject), zap.Error(cause))
+	if err := m.Term(); err != nil {
+		b.log.Warn("Failed to terminate message", zap.String("subject", m.Subject), zap.Error(err))
+	}
+}
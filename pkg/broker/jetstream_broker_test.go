@@ -0,0 +1,222 @@
+package broker
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"chat-grpc/proto_gen"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testTimeout = 5 * time.Second
+
+// runServer starts an embedded NATS server with JetStream in a temporary directory.
+// Its max payload is above MaxEventSize, so oversized events reach the subscribers.
+func runServer(t *testing.T) *server.Server {
+	t.Helper()
+
+	s, err := server.NewServer(&server.Options{
+		Host:       "127.0.0.1",
+		Port:       -1,
+		JetStream:  true,
+		StoreDir:   t.TempDir(),
+		MaxPayload: 2 * MaxEventSize,
+		NoLog:      true,
+		NoSigs:     true,
+	})
+	require.NoError(t, err)
+
+	go s.Start()
+	require.True(t, s.ReadyForConnections(testTimeout), "nats server did not start")
+	t.Cleanup(func() {
+		s.Shutdown()
+		s.WaitForShutdown()
+	})
+
+	return s
+}
+
+func newTestJetStream(t *testing.T, s *server.Server, maxDeliver int) Broker {
+	t.Helper()
+
+	b, err := NewJetStreamBroker(s.ClientURL(), "test", JetStreamConfig{MaxDeliver: maxDeliver, AckWait: 30 * time.Second}, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { b.Close() })
+
+	return b
+}
+
+func connect(t *testing.T, s *server.Server) *nats.Conn {
+	t.Helper()
+
+	nc, err := nats.Connect(s.ClientURL())
+	require.NoError(t, err)
+	t.Cleanup(nc.Close)
+
+	return nc
+}
+
+func receive(t *testing.T, events <-chan *proto_gen.ChatEvent) *proto_gen.ChatEvent {
+	t.Helper()
+
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(testTimeout):
+		t.Fatal("no event received")
+		return nil
+	}
+}
+
+func requireNoEvent(t *testing.T, events <-chan *proto_gen.ChatEvent, wait time.Duration) {
+	t.Helper()
+
+	select {
+	case event := <-events:
+		t.Fatalf("unexpected event %s: %q", event.Id, event.GetMessage().GetText())
+	case <-time.After(wait):
+	}
+}
+
+func TestJetStreamConsumeRedeliversFailedEvent(t *testing.T) {
+	s := runServer(t)
+	b := newTestJetStream(t, s, 5)
+
+	events := make(chan *proto_gen.ChatEvent, 10)
+	var calls atomic.Int32
+	_, err := b.Consume("chat.*", "test-consumer", func(event *proto_gen.ChatEvent) error {
+		events <- event
+		if calls.Add(1) == 1 {
+			return errors.New("temporary failure")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, b.Publish(&proto_gen.Message{ChatId: 7, Text: "hello"}))
+
+	first := receive(t, events)
+	second := receive(t, events)
+	require.Equal(t, first.Id, second.Id)
+	require.Equal(t, "hello", second.GetMessage().GetText())
+
+	// acked after the second delivery
+	requireNoEvent(t, events, 2*redeliveryDelay)
+}
+
+func TestJetStreamConsumeDeadLettersAfterMaxDeliver(t *testing.T) {
+	s := runServer(t)
+	const maxDeliver = 2
+	b := newTestJetStream(t, s, maxDeliver)
+
+	events := make(chan *proto_gen.ChatEvent, 10)
+	_, err := b.Consume("chat.*", "test-consumer", func(event *proto_gen.ChatEvent) error {
+		events <- event
+		return errors.New("cannot handle")
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, b.Publish(&proto_gen.Message{ChatId: 7, Text: "poison"}))
+	for range maxDeliver {
+		receive(t, events)
+	}
+
+	js, err := connect(t, s).JetStream()
+	require.NoError(t, err)
+
+	var dead *nats.RawStreamMsg
+	require.Eventually(t, func() bool {
+		dead, err = js.GetLastMsg(DeadLetterStreamName, DeadLetterPrefix+Subject(7))
+		return err == nil
+	}, testTimeout, 50*time.Millisecond)
+
+	require.Equal(t, Subject(7), dead.Header.Get(DeadLetterSubjectHeader))
+	require.Equal(t, "test-consumer", dead.Header.Get(DeadLetterConsumerHeader))
+	require.Equal(t, "cannot handle", dead.Header.Get(DeadLetterErrorHeader))
+
+	event, err := DecodeEvent(&nats.Msg{Data: dead.Data, Header: dead.Header})
+	require.NoError(t, err)
+	require.Equal(t, "poison", event.GetMessage().GetText())
+
+	// terminated, not redelivered
+	requireNoEvent(t, events, 2*redeliveryDelay)
+}
+
+func TestJetStreamNewConsumerStartsWithNewEvents(t *testing.T) {
+	s := runServer(t)
+	b := newTestJetStream(t, s, 5)
+
+	require.NoError(t, b.Publish(&proto_gen.Message{ChatId: 7, Text: "history"}))
+
+	events := make(chan *proto_gen.ChatEvent, 10)
+	_, err := b.Consume("chat.*", "test-consumer", func(event *proto_gen.ChatEvent) error {
+		events <- event
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, b.Publish(&proto_gen.Message{ChatId: 7, Text: "new"}))
+	require.Equal(t, "new", receive(t, events).GetMessage().GetText())
+	requireNoEvent(t, events, 200*time.Millisecond)
+}
+
+func TestJetStreamDurableConsumerResumesAfterRestart(t *testing.T) {
+	s := runServer(t)
+	publisher := newTestJetStream(t, s, 5)
+
+	consumer, err := NewJetStreamBroker(s.ClientURL(), "test", JetStreamConfig{MaxDeliver: 5, AckWait: 30 * time.Second}, zap.NewNop())
+	require.NoError(t, err)
+
+	events := make(chan *proto_gen.ChatEvent, 10)
+	handler := func(event *proto_gen.ChatEvent) error {
+		events <- event
+		return nil
+	}
+	_, err = consumer.Consume("chat.*", "test-consumer", handler)
+	require.NoError(t, err)
+
+	require.NoError(t, publisher.Publish(&proto_gen.Message{ChatId: 7, Text: "before"}))
+	require.Equal(t, "before", receive(t, events).GetMessage().GetText())
+
+	// closing the connection keeps the durable consumer on the server
+	require.NoError(t, consumer.Close())
+	js, err := connect(t, s).JetStream()
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		info, err := js.ConsumerInfo(StreamName, "test-consumer")
+		return err == nil && !info.PushBound
+	}, testTimeout, 50*time.Millisecond)
+
+	require.NoError(t, publisher.Publish(&proto_gen.Message{ChatId: 7, Text: "while down"}))
+
+	restarted := newTestJetStream(t, s, 5)
+	_, err = restarted.Consume("chat.*", "test-consumer", handler)
+	require.NoError(t, err)
+
+	require.Equal(t, "while down", receive(t, events).GetMessage().GetText())
+	requireNoEvent(t, events, 200*time.Millisecond)
+}
+
+func TestJetStreamPublishDropsRepublishedEvent(t *testing.T) {
+	s := runServer(t)
+	b := newTestJetStream(t, s, 5)
+
+	events := make(chan *proto_gen.ChatEvent, 10)
+	_, err := b.Consume("chat.*", "test-consumer", func(event *proto_gen.ChatEvent) error {
+		events <- event
+		return nil
+	})
+	require.NoError(t, err)
+
+	msg := &proto_gen.Message{Id: 42, ChatId: 7, Text: "stored"}
+	require.NoError(t, b.Publish(msg))
+	require.NoError(t, b.Publish(msg))
+
+	require.Equal(t, "message-42", receive(t, events).Id)
+	requireNoEvent(t, events, 200*time.Millisecond)
+}
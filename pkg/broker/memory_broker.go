@@ -0,0 +1,240 @@
+package broker
+
+import (
+	"strings"
+	"sync"
+
+	"chat-grpc/proto_gen"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+// MaxPendingEvents bounds the events queued for one subscription of the memory
+// broker. Like a slow consumer of core NATS, a subscription that falls further
+// behind loses the newest events.
+const MaxPendingEvents = 65536
+
+// MemoryHub is an in-process stand-in for a NATS server. Brokers connected to
+// the same hub see each other's events, so several services can run in one
+// process. Like core NATS it keeps nothing for absent subscribers.
+type MemoryHub struct {
+	mu   sync.Mutex
+	subs []*memorySubscription
+	next map[string]int
+
+	maxPending int
+}
+
+func NewMemoryHub() *MemoryHub {
+	return &MemoryHub{next: make(map[string]int), maxPending: MaxPendingEvents}
+}
+
+// NewMemoryBroker returns a broker with a hub of its own, e.g. for a test.
+func NewMemoryBroker(producer string, log *zap.Logger) Broker {
+	return NewMemoryHub().Connect(producer, log)
+}
+
+// Connect returns a broker publishing as producer on the hub.
+func (h *MemoryHub) Connect(producer string, log *zap.Logger) Broker {
+	return &memoryBroker{hub: h, producer: producer, log: log}
+}
+
+func (h *MemoryHub) add(sub *memorySubscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.subs = append(h.subs, sub)
+}
+
+func (h *MemoryHub) remove(sub *memorySubscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i, s := range h.subs {
+		if s == sub {
+			h.subs = append(h.subs[:i], h.subs[i+1:]...)
+			return
+		}
+	}
+}
+
+// publish hands the event to every matching subscription and to one member of
+// every matching queue group, taking turns between the members.
+func (h *MemoryHub) publish(subject string, event *proto_gen.ChatEvent) {
+	h.mu.Lock()
+	var targets []*memorySubscription
+	groups := make(map[string][]*memorySubscription)
+	for _, sub := range h.subs {
+		if !matchSubject(sub.subject, subject) {
+			continue
+		}
+		if sub.queue == "" {
+			targets = append(targets, sub)
+		} else {
+			groups[sub.queue] = append(groups[sub.queue], sub)
+		}
+	}
+	for queue, members := range groups {
+		targets = append(targets, members[h.next[queue]%len(members)])
+		h.next[queue]++
+	}
+	h.mu.Unlock()
+
+	for _, sub := range targets {
+		sub.push(proto.Clone(event).(*proto_gen.ChatEvent))
+	}
+}
+
+// matchSubject matches a subject against a NATS pattern, where "*" stands for
+// one token and a trailing ">" for one or more.
+func matchSubject(pattern, subject string) bool {
+	patternTokens := strings.Split(pattern, ".")
+	subjectTokens := strings.Split(subject, ".")
+
+	for i, token := range patternTokens {
+		if token == ">" {
+			return len(subjectTokens) > i
+		}
+		if i >= len(subjectTokens) || (token != "*" && token != subjectTokens[i]) {
+			return false
+		}
+	}
+
+	return len(patternTokens) == len(subjectTokens)
+}
+
+type memoryBroker struct {
+	hub      *MemoryHub
+	producer string
+	log      *zap.Logger
+
+	mu   sync.Mutex
+	subs []*memorySubscription
+}
+
+func (b *memoryBroker) Publish(msg *proto_gen.Message) error {
+	b.hub.publish(Subject(msg.ChatId), NewEvent(msg, b.producer))
+
+	return nil
+}
+
+func (b *memoryBroker) Subscribe(subject string, handler func(*proto_gen.ChatEvent)) (Subscription, error) {
+	return b.subscribe(subject, "", handler), nil
+}
+
+func (b *memoryBroker) QueueSubscribe(subject, queue string, handler func(*proto_gen.ChatEvent)) (Subscription, error) {
+	return b.subscribe(subject, queue, handler), nil
+}
+
+// Consume shares the events between the consumers of the same name. As with
+// core NATS a failed event is only logged.
+func (b *memoryBroker) Consume(subject, durable string, handler func(*proto_gen.ChatEvent) error) (Subscription, error) {
+	return b.subscribe(subject, durable, func(event *proto_gen.ChatEvent) {
+		if err := handler(event); err != nil {
+			b.log.Error("Consumer failed to handle event", zap.String("consumer", durable),
+				zap.String("event_id", event.Id), zap.Int64("chat_id", event.ChatId), zap.Error(err))
+		}
+	}), nil
+}
+
+func (b *memoryBroker) subscribe(subject, queue string, handler func(*proto_gen.ChatEvent)) *memorySubscription {
+	sub := &memorySubscription{hub: b.hub, log: b.log, subject: subject, queue: queue, handler: handler}
+	sub.cond = sync.NewCond(&sub.mu)
+
+	b.mu.Lock()
+	b.subs = append(b.subs, sub)
+	b.mu.Unlock()
+
+	b.hub.add(sub)
+	go sub.run()
+
+	return sub
+}
+
+// Flush has nothing to wait for, events are handed to the subscriptions in Publish.
+func (b *memoryBroker) Flush() error {
+	return nil
+}
+
+// Close removes the subscriptions of this broker, others on the hub keep working.
+func (b *memoryBroker) Close() error {
+	b.mu.Lock()
+	subs := b.subs
+	b.subs = nil
+	b.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.Unsubscribe()
+	}
+
+	return nil
+}
+
+// memorySubscription calls its handler from a goroutine of its own, one event
+// at a time and in publish order, so a slow handler never blocks a publisher.
+type memorySubscription struct {
+	hub     *MemoryHub
+	log     *zap.Logger
+	subject string
+	queue   string
+	handler func(*proto_gen.ChatEvent)
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	pending []*proto_gen.ChatEvent
+	closed  bool
+	// dropping is set while the queue is full, so a slow subscription is
+	// reported once rather than for every lost event
+	dropping bool
+}
+
+func (s *memorySubscription) push(event *proto_gen.ChatEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	if len(s.pending) >= s.hub.maxPending {
+		if !s.dropping {
+			s.log.Warn("Memory subscription is too slow, dropping events", zap.String("subject", s.subject),
+				zap.String("queue", s.queue), zap.Int("pending", len(s.pending)))
+			s.dropping = true
+		}
+		return
+	}
+	s.dropping = false
+	s.pending = append(s.pending, event)
+	s.cond.Signal()
+}
+
+func (s *memorySubscription) run() {
+	for {
+		s.mu.Lock()
+		for len(s.pending) == 0 && !s.closed {
+			s.cond.Wait()
+		}
+		if s.closed {
+			s.mu.Unlock()
+			return
+		}
+		event := s.pending[0]
+		s.pending = s.pending[1:]
+		s.mu.Unlock()
+
+		s.handler(event)
+	}
+}
+
+func (s *memorySubscription) Unsubscribe() error {
+	s.hub.remove(s)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	s.pending = nil
+	s.cond.Broadcast()
+
+	return nil
+}
@@ -0,0 +1,193 @@
+package broker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"chat-grpc/pkg/config"
+	"chat-grpc/proto_gen"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestNewRefusesMemoryDriver(t *testing.T) {
+	_, err := New(&config.Config{BrokerDriver: MemoryDriver}, "test", zap.NewNop())
+	require.ErrorIs(t, err, ErrMemoryDriver)
+}
+
+func TestMatchSubject(t *testing.T) {
+	tests := []struct {
+		pattern, subject string
+		match            bool
+	}{
+		{"chat.7", "chat.7", true},
+		{"chat.7", "chat.8", false},
+		{"chat.*", "chat.7", true},
+		{"chat.*", "chat.7.extra", false},
+		{"chat.>", "chat.7.extra", true},
+		{"chat.>", "chat", false},
+		{">", "chat.7", true},
+		{"chat", "chat.7", false},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.match, matchSubject(tt.pattern, tt.subject), "%s ~ %s", tt.pattern, tt.subject)
+	}
+}
+
+func TestMemoryBrokersOfOneHubShareEvents(t *testing.T) {
+	hub := NewMemoryHub()
+	publisher := hub.Connect("chat-service", zap.NewNop())
+	subscriber := hub.Connect("notification-service", zap.NewNop())
+	other := NewMemoryBroker("other", zap.NewNop())
+
+	events := make(chan *proto_gen.ChatEvent, 10)
+	_, err := subscriber.Subscribe("chat.*", func(event *proto_gen.ChatEvent) {
+		events <- event
+	})
+	require.NoError(t, err)
+	_, err = other.Subscribe("chat.*", func(event *proto_gen.ChatEvent) {
+		t.Errorf("event %s crossed hubs", event.Id)
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, publisher.Publish(&proto_gen.Message{Id: 42, ChatId: 7, Text: "hello"}))
+
+	event := receive(t, events)
+	require.Equal(t, "message-42", event.Id)
+	require.Equal(t, "chat-service", event.Producer)
+	require.Equal(t, int64(7), event.ChatId)
+	require.Equal(t, "hello", event.GetMessage().GetText())
+}
+
+func TestMemoryBrokerKeepsPublishOrder(t *testing.T) {
+	b := NewMemoryBroker("test", zap.NewNop())
+
+	events := make(chan *proto_gen.ChatEvent, 100)
+	_, err := b.Subscribe("chat.7", func(event *proto_gen.ChatEvent) {
+		events <- event
+	})
+	require.NoError(t, err)
+
+	for i := range 100 {
+		require.NoError(t, b.Publish(&proto_gen.Message{Id: int64(i + 1), ChatId: 7}))
+	}
+	for i := range 100 {
+		require.Equal(t, int64(i+1), receive(t, events).GetMessage().GetId())
+	}
+}
+
+func TestMemoryBrokerQueueGroupTakesTurns(t *testing.T) {
+	b := NewMemoryBroker("test", zap.NewNop())
+
+	first := make(chan *proto_gen.ChatEvent, 10)
+	second := make(chan *proto_gen.ChatEvent, 10)
+	_, err := b.QueueSubscribe("chat.*", "workers", func(event *proto_gen.ChatEvent) { first <- event })
+	require.NoError(t, err)
+	_, err = b.QueueSubscribe("chat.*", "workers", func(event *proto_gen.ChatEvent) { second <- event })
+	require.NoError(t, err)
+
+	for i := range 4 {
+		require.NoError(t, b.Publish(&proto_gen.Message{Id: int64(i + 1), ChatId: 7}))
+	}
+
+	for range 2 {
+		receive(t, first)
+		receive(t, second)
+	}
+	requireNoEvent(t, first, 50*time.Millisecond)
+	requireNoEvent(t, second, 50*time.Millisecond)
+}
+
+func TestMemoryBrokerConsumeContinuesAfterError(t *testing.T) {
+	b := NewMemoryBroker("test", zap.NewNop())
+
+	events := make(chan *proto_gen.ChatEvent, 10)
+	_, err := b.Consume("chat.*", "test-consumer", func(event *proto_gen.ChatEvent) error {
+		events <- event
+		if event.GetMessage().GetText() == "bad" {
+			return errors.New("cannot handle")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, b.Publish(&proto_gen.Message{ChatId: 7, Text: "bad"}))
+	require.NoError(t, b.Publish(&proto_gen.Message{ChatId: 7, Text: "good"}))
+
+	// a failed event is not redelivered
+	require.Equal(t, "bad", receive(t, events).GetMessage().GetText())
+	require.Equal(t, "good", receive(t, events).GetMessage().GetText())
+	requireNoEvent(t, events, 50*time.Millisecond)
+}
+
+func TestMemoryBrokerUnsubscribeAndClose(t *testing.T) {
+	hub := NewMemoryHub()
+	b := hub.Connect("test", zap.NewNop())
+	other := hub.Connect("other", zap.NewNop())
+
+	unsubscribed := make(chan *proto_gen.ChatEvent, 10)
+	closed := make(chan *proto_gen.ChatEvent, 10)
+	kept := make(chan *proto_gen.ChatEvent, 10)
+
+	sub, err := b.Subscribe("chat.*", func(event *proto_gen.ChatEvent) { unsubscribed <- event })
+	require.NoError(t, err)
+	_, err = b.Subscribe("chat.*", func(event *proto_gen.ChatEvent) { closed <- event })
+	require.NoError(t, err)
+	_, err = other.Subscribe("chat.*", func(event *proto_gen.ChatEvent) { kept <- event })
+	require.NoError(t, err)
+
+	require.NoError(t, sub.Unsubscribe())
+	require.NoError(t, b.Close())
+	require.NoError(t, other.Publish(&proto_gen.Message{ChatId: 7, Text: "hello"}))
+
+	require.Equal(t, "hello", receive(t, kept).GetMessage().GetText())
+	requireNoEvent(t, unsubscribed, 50*time.Millisecond)
+	requireNoEvent(t, closed, 50*time.Millisecond)
+}
+
+func TestMemorySubscriptionDropsEventsBeyondMaxPending(t *testing.T) {
+	hub := NewMemoryHub()
+	hub.maxPending = 2
+	b := hub.Connect("test", zap.NewNop())
+
+	release := make(chan struct{})
+	events := make(chan *proto_gen.ChatEvent, 10)
+	_, err := b.Subscribe("chat.*", func(event *proto_gen.ChatEvent) {
+		<-release
+		events <- event
+	})
+	require.NoError(t, err)
+
+	// the first event is taken by the blocked handler, the next two are queued
+	require.NoError(t, b.Publish(&proto_gen.Message{Id: 1, ChatId: 7}))
+	require.Eventually(t, func() bool {
+		return pendingEvents(hub) == 0
+	}, testTimeout, time.Millisecond)
+	for i := 2; i <= 5; i++ {
+		require.NoError(t, b.Publish(&proto_gen.Message{Id: int64(i), ChatId: 7}))
+	}
+	require.Equal(t, 2, pendingEvents(hub))
+
+	close(release)
+	for _, id := range []int64{1, 2, 3} {
+		require.Equal(t, id, receive(t, events).GetMessage().GetId())
+	}
+	requireNoEvent(t, events, 50*time.Millisecond)
+
+	// the queue has room again
+	require.NoError(t, b.Publish(&proto_gen.Message{Id: 6, ChatId: 7}))
+	require.Equal(t, int64(6), receive(t, events).GetMessage().GetId())
+}
+
+func pendingEvents(hub *MemoryHub) int {
+	hub.mu.Lock()
+	sub := hub.subs[0]
+	hub.mu.Unlock()
+
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	return len(sub.pending)
+}
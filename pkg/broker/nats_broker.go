@@ -0,0 +1,91 @@
+package broker
+
+import (
+	"time"
+
+	"chat-grpc/proto_gen"
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+type natsBroker struct {
+	conn     *nats.Conn
+	producer string
+	log      *zap.Logger
+	// js is set by the JetStream driver, quarantined messages are then stored
+	// in the CHAT_QUARANTINE stream.
+	js nats.JetStreamContext
+}
+
+func NewNatsBroker(url, producer string, log *zap.Logger) (Broker, error) {
+	nc, err := nats.Connect(url)
+	if err != nil {
+		return nil, err
+	}
+	return &natsBroker{conn: nc, producer: producer, log: log}, nil
+}
+
+func (b *natsBroker) Subscribe(subject string, handler func(*proto_gen.ChatEvent)) (Subscription, error) {
+	return subscription(b.conn.Subscribe(subject, func(m *nats.Msg) {
+		event, err := DecodeEvent(m)
+		if err != nil {
+			b.quarantine(m, err)
+			return
+		}
+		handler(event)
+	}))
+}
+
+// QueueSubscribe delivers each message to only one subscriber of the queue group,
+// so background workers are not duplicated when several Chat-service replicas run.
+func (b *natsBroker) QueueSubscribe(subject, queue string, handler func(*proto_gen.ChatEvent)) (Subscription, error) {
+	return subscription(b.conn.QueueSubscribe(subject, queue, func(m *nats.Msg) {
+		event, err := DecodeEvent(m)
+		if err != nil {
+			b.quarantine(m, err)
+			return
+		}
+		handler(event)
+	}))
+}
+
+// subscription keeps a failed subscribe from returning a non-nil Subscription
+// that wraps a nil *nats.Subscription.
+func subscription(sub *nats.Subscription, err error) (Subscription, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return sub, nil
+}
+
+// Consume has no durability with core NATS: messages published while nobody
+// listens are lost and a failed message is only logged.
+func (b *natsBroker) Consume(subject, durable string, handler func(*proto_gen.ChatEvent) error) (Subscription, error) {
+	return b.QueueSubscribe(subject, durable, func(event *proto_gen.ChatEvent) {
+		if err := handler(event); err != nil {
+			b.log.Error("Consumer failed to handle event", zap.String("consumer", durable),
+				zap.String("event_id", event.Id), zap.Int64("chat_id", event.ChatId), zap.Error(err))
+		}
+	})
+}
+
+func (b *natsBroker) Publish(msg *proto_gen.Message) error {
+	m, err := EncodeEvent(NewEvent(msg, b.producer))
+	if err != nil {
+		return err
+	}
+
+	return b.conn.PublishMsg(m)
+}
+
+// Flush waits until the server has received everything published so far.
+func (b *natsBroker) Flush() error {
+	return b.conn.FlushTimeout(5 * time.Second)
+}
+
+func (b *natsBroker) Close() error {
+	b.conn.Close()
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package broker
+
+import (
+	"errors"
+	"expvar"
+	"strconv"
+
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+const (
+	// MaxEventSize bounds one event, a larger payload is treated as poison.
+	MaxEventSize = 1 << 20
+
+	// Undecodable messages are moved to QuarantinePrefix + the original subject,
+	// e.g. "quarantine.chat.42", with the error and the original size in headers.
+	QuarantinePrefix      = "quarantine."
+	OriginalSizeHeader    = "Chat-Original-Size"
+	QuarantineErrorHeader = "Chat-Error"
+)
+
+var ErrEventTooLarge = errors.New("event exceeds the maximum size")
+
+var (
+	// decodeErrors counts undecodable messages by reason, "too_large" or "malformed".
+	decodeErrors = expvar.NewMap("broker_decode_errors")
+	quarantined  = expvar.NewInt("broker_quarantined_messages")
+)
+
+// quarantine sets an undecodable message aside so that the subscription keeps
+// running. The body of an oversized message is not copied. With JetStream the
+// message is stored in the quarantine stream, with core NATS only live
+// subscribers of the quarantine subject see it.
+func (b *natsBroker) quarantine(m *nats.Msg, cause error) error {
+	reason := "malformed"
+	if errors.Is(cause, ErrEventTooLarge) {
+		reason = "too_large"
+	}
+	decodeErrors.Add(reason, 1)
+	b.log.Warn("Quarantining undecodable event", zap.String("subject", m.Subject),
+		zap.Int("size", len(m.Data)), zap.Error(cause))
+
+	q := nats.NewMsg(QuarantinePrefix + m.Subject)
+	for key, values := range m.Header {
+		q.Header[key] = values
+	}
+	q.Header.Del(nats.MsgIdHdr)
+	q.Header.Set(OriginalSizeHeader, strconv.Itoa(len(m.Data)))
+	q.Header.Set(QuarantineErrorHeader, cause.Error())
+	if reason != "too_large" {
+		q.Data = m.Data
+	}
+
+	var err error
+	if b.js != nil {
+		_, err = b.js.PublishMsg(q)
+	} else {
+		err = b.conn.PublishMsg(q)
+	}
+	if err != nil {
+		b.log.Error("Failed to quarantine event", zap.String("subject", m.Subject), zap.Error(err))
+		return err
+	}
+	quarantined.Add(1)
+
+	return nil
+}
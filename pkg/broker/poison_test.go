@@ -0,0 +1,160 @@
+package broker
+
+import (
+	"bytes"
+	"expvar"
+	"strconv"
+	"testing"
+	"time"
+
+	"chat-grpc/proto_gen"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func decodeErrorCount(reason string) int64 {
+	if v, ok := decodeErrors.Get(reason).(*expvar.Int); ok {
+		return v.Value()
+	}
+
+	return 0
+}
+
+// lastQuarantined waits until the quarantine stream has a message for the chat.
+func lastQuarantined(t *testing.T, js nats.JetStreamContext, chatID int64) *nats.RawStreamMsg {
+	t.Helper()
+
+	var msg *nats.RawStreamMsg
+	require.Eventually(t, func() bool {
+		var err error
+		msg, err = js.GetLastMsg(QuarantineStreamName, QuarantinePrefix+Subject(chatID))
+		return err == nil
+	}, testTimeout, 50*time.Millisecond)
+
+	return msg
+}
+
+func TestSubscriptionQuarantinesMalformedEventAndKeepsRunning(t *testing.T) {
+	s := runServer(t)
+	b := newTestJetStream(t, s, 5)
+	nc := connect(t, s)
+	js, err := nc.JetStream()
+	require.NoError(t, err)
+
+	events := make(chan *proto_gen.ChatEvent, 10)
+	_, err = b.Subscribe("chat.*", func(event *proto_gen.ChatEvent) {
+		events <- event
+	})
+	require.NoError(t, err)
+	require.NoError(t, b.Flush())
+
+	malformed := decodeErrorCount("malformed")
+	quarantinedBefore := quarantined.Value()
+
+	bad := nats.NewMsg(Subject(7))
+	bad.Data = []byte{0xff, 0xff, 0xff}
+	bad.Header.Set(ContentTypeHeader, EventContentType)
+	bad.Header.Set("Trace-Id", "abc")
+	require.NoError(t, nc.PublishMsg(bad))
+	require.NoError(t, b.Publish(&proto_gen.Message{ChatId: 7, Text: "after"}))
+
+	require.Equal(t, "after", receive(t, events).GetMessage().GetText())
+	requireNoEvent(t, events, 200*time.Millisecond)
+
+	q := lastQuarantined(t, js, 7)
+	require.Equal(t, bad.Data, q.Data)
+	require.Equal(t, "3", q.Header.Get(OriginalSizeHeader))
+	require.NotEmpty(t, q.Header.Get(QuarantineErrorHeader))
+	require.Equal(t, EventContentType, q.Header.Get(ContentTypeHeader))
+	require.Equal(t, "abc", q.Header.Get("Trace-Id"))
+
+	require.Equal(t, malformed+1, decodeErrorCount("malformed"))
+	require.Equal(t, quarantinedBefore+1, quarantined.Value())
+}
+
+func TestQuarantineDropsBodyOfOversizedEvent(t *testing.T) {
+	s := runServer(t)
+	b := newTestJetStream(t, s, 5)
+	nc := connect(t, s)
+	js, err := nc.JetStream()
+	require.NoError(t, err)
+
+	_, err = b.Subscribe("chat.*", func(event *proto_gen.ChatEvent) {
+		t.Errorf("oversized event %s delivered", event.Id)
+	})
+	require.NoError(t, err)
+	require.NoError(t, b.Flush())
+
+	tooLarge := decodeErrorCount("too_large")
+
+	size := MaxEventSize + 1
+	require.NoError(t, nc.Publish(Subject(7), bytes.Repeat([]byte("x"), size)))
+
+	q := lastQuarantined(t, js, 7)
+	require.Empty(t, q.Data)
+	require.Equal(t, strconv.Itoa(size), q.Header.Get(OriginalSizeHeader))
+	require.Contains(t, q.Header.Get(QuarantineErrorHeader), ErrEventTooLarge.Error())
+	require.Equal(t, tooLarge+1, decodeErrorCount("too_large"))
+}
+
+func TestConsumeTerminatesQuarantinedEvent(t *testing.T) {
+	s := runServer(t)
+	b := newTestJetStream(t, s, 5)
+	js, err := connect(t, s).JetStream()
+	require.NoError(t, err)
+
+	events := make(chan *proto_gen.ChatEvent, 10)
+	_, err = b.Consume("chat.*", "test-consumer", func(event *proto_gen.ChatEvent) error {
+		events <- event
+		return nil
+	})
+	require.NoError(t, err)
+
+	// stored in the CHAT stream like any event
+	_, err = js.Publish(Subject(7), []byte("not an event"))
+	require.NoError(t, err)
+	require.NoError(t, b.Publish(&proto_gen.Message{ChatId: 7, Text: "after"}))
+
+	require.Equal(t, "after", receive(t, events).GetMessage().GetText())
+
+	q := lastQuarantined(t, js, 7)
+	require.Equal(t, []byte("not an event"), q.Data)
+
+	// terminated rather than redelivered
+	requireNoEvent(t, events, 2*redeliveryDelay)
+	info, err := js.ConsumerInfo(StreamName, "test-consumer")
+	require.NoError(t, err)
+	require.Zero(t, info.NumAckPending)
+	require.Zero(t, info.NumRedelivered)
+}
+
+func TestCoreNatsQuarantinesMalformedEvent(t *testing.T) {
+	s := runServer(t)
+	b, err := NewNatsBroker(s.ClientURL(), "test", zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { b.Close() })
+	nc := connect(t, s)
+
+	quarantine, err := nc.SubscribeSync(QuarantinePrefix + "chat.>")
+	require.NoError(t, err)
+	require.NoError(t, nc.Flush())
+
+	events := make(chan *proto_gen.ChatEvent, 10)
+	_, err = b.Subscribe("chat.*", func(event *proto_gen.ChatEvent) {
+		events <- event
+	})
+	require.NoError(t, err)
+	require.NoError(t, b.Flush())
+
+	require.NoError(t, nc.Publish(Subject(7), []byte("not an event")))
+	require.NoError(t, b.Publish(&proto_gen.Message{ChatId: 7, Text: "after"}))
+
+	require.Equal(t, "after", receive(t, events).GetMessage().GetText())
+
+	q, err := quarantine.NextMsg(testTimeout)
+	require.NoError(t, err)
+	require.Equal(t, QuarantinePrefix+Subject(7), q.Subject)
+	require.Equal(t, []byte("not an event"), q.Data)
+	require.Equal(t, "12", q.Header.Get(OriginalSizeHeader))
+}
@@ -4,34 +4,68 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
 type Config struct {
-	AuthServiceAddr         string
-	ServerPortAuth          string
-	ServerPortChat          string
-	NatsUrl                 string
-	DBHost                  string
-	DBHostUsers             string
-	DBPort                  string
-	DBPortUsers             string
-	DBUser                  string
-	DBUserUsers             string
-	DBPassword              string
-	DBPasswordUsers         string
-	DBName                  string
-	DBNameUsers             string
-	SmtpUser                string
-	SmtpPass                string
-	SmtpHost                string
-	SmtpPort                string
-	JWTSecret               string
-	AccessTokenDuration     time.Duration
-	RefreshTokenDuration    time.Duration
-	SagaPort                string
-	NotificationServiceAddr string
-	NotificationPort        string
+	AuthServiceAddr          string
+	ServerPortAuth           string
+	ServerPortChat           string
+	NatsUrl                  string
+	BrokerDriver             string
+	JetStreamMaxDeliver      int
+	JetStreamAckWait         time.Duration
+	JetStreamMaxAge          time.Duration
+	DBHost                   string
+	DBHostUsers              string
+	DBPort                   string
+	DBPortUsers              string
+	DBUser                   string
+	DBUserUsers              string
+	DBPassword               string
+	DBPasswordUsers          string
+	DBName                   string
+	DBNameUsers              string
+	SmtpUser                 string
+	SmtpPass                 string
+	SmtpHost                 string
+	SmtpPort                 string
+	JWTKeysDir               string
+	JWTActiveKeyID           string
+	JWTRefreshSecret         string
+	AuthHTTPPort             string
+	AccessTokenDuration      time.Duration
+	RefreshTokenDuration     time.Duration
+	RevocationSyncInterval   time.Duration
+	RevocationCheckInterval  time.Duration
+	AuthFailOpen             bool
+	ServiceToken             string
+	JWKSRefreshInterval      time.Duration
+	PolicySyncInterval       time.Duration
+	PasswordResetURL         string
+	PasswordResetTTL         time.Duration
+	SagaPort                 string
+	NotificationServiceAddr  string
+	NotificationPort         string
+	WebhookTimeout           time.Duration
+	WebhookWorkers           int
+	WebhookMaxAttempts       int
+	WebhookMaxFailures       int
+	IncomingWebhookPort      string
+	IncomingWebhookRate      int
+	IncomingWebhookBurst     int
+	MetricsAddr              string
+	ModerationWords          []string
+	ModerationBlockedDomains []string
+	RateLimitUserPerMinute   int
+	RateLimitUserBurst       int
+	RateLimitChatPerMinute   int
+	RateLimitChatBurst       int
+	RateLimitGlobalPerMinute int
+	RateLimitGlobalBurst     int
+	StreamQueueSize          int
+	StreamOverflowPolicy     string
 }
 
 func LoadConfig() *Config {
@@ -41,6 +75,11 @@ func LoadConfig() *Config {
 		ServerPortChat:  getEnv("SERVER_PORT_CHAT", "50052"),
 		NatsUrl:         getEnv("NATS_URL", "nats://nats:4222"),
 
+		BrokerDriver:        getEnv("BROKER_DRIVER", "nats"),
+		JetStreamMaxDeliver: getEnvAsInt("JETSTREAM_MAX_DELIVER", 5),
+		JetStreamAckWait:    getEnvAsDuration("JETSTREAM_ACK_WAIT", time.Second*30),
+		JetStreamMaxAge:     getEnvAsDuration("JETSTREAM_MAX_AGE", time.Hour*24*7),
+
 		DBHost:     getEnv("DB_HOST", "localhost"),
 		DBPort:     getEnv("DB_PORT", "5432"),
 		DBUser:     getEnv("DB_USER", "auth_user"),
@@ -58,13 +97,52 @@ func LoadConfig() *Config {
 		SmtpUser: getEnv("SMTP_USER", "user@gmail.com"),
 		SmtpPass: getEnv("SMTP_PASS", "pass-user"),
 
-		JWTSecret:            getEnv("JWT_SECRET", "default_key"),
+		JWTKeysDir:       getEnv("JWT_KEYS_DIR", ""),
+		JWTActiveKeyID:   getEnv("JWT_ACTIVE_KEY_ID", ""),
+		JWTRefreshSecret: getEnv("JWT_REFRESH_SECRET", "default_refresh_key"),
+		AuthHTTPPort:     getEnv("AUTH_HTTP_PORT", "8081"),
+
 		AccessTokenDuration:  getEnvAsDuration("ACCESS_TOKEN_DURATION", time.Minute*15),
 		RefreshTokenDuration: getEnvAsDuration("REFRESH_TOKEN_DURATION", time.Hour*24),
 
+		RevocationSyncInterval:  getEnvAsDuration("REVOCATION_SYNC_INTERVAL", time.Second*10),
+		RevocationCheckInterval: getEnvAsDuration("REVOCATION_CHECK_INTERVAL", time.Second*30),
+		AuthFailOpen:            getEnvAsBool("AUTH_FAIL_OPEN", false),
+		ServiceToken:            getEnv("SERVICE_TOKEN", ""),
+		JWKSRefreshInterval:     getEnvAsDuration("JWKS_REFRESH_INTERVAL", time.Minute*5),
+		PolicySyncInterval:      getEnvAsDuration("POLICY_SYNC_INTERVAL", time.Minute),
+
+		PasswordResetURL: getEnv("PASSWORD_RESET_URL", "http://localhost:3000/reset-password"),
+		PasswordResetTTL: getEnvAsDuration("PASSWORD_RESET_TTL", time.Hour),
+
 		SagaPort:                getEnv("SAGA_PORT", "50053"),
 		NotificationPort:        getEnv("NOTIFICATION_PORT", "50054"),
 		NotificationServiceAddr: getEnv("NOTIFICATION_SERVICE_ADDR", "notification-service:50054"),
+
+		WebhookTimeout:     getEnvAsDuration("WEBHOOK_TIMEOUT", time.Second*10),
+		WebhookWorkers:     getEnvAsInt("WEBHOOK_WORKERS", 8),
+		WebhookMaxAttempts: getEnvAsInt("WEBHOOK_MAX_ATTEMPTS", 5),
+		WebhookMaxFailures: getEnvAsInt("WEBHOOK_MAX_FAILURES", 10),
+
+		IncomingWebhookPort:  getEnv("INCOMING_WEBHOOK_PORT", "8080"),
+		IncomingWebhookRate:  getEnvAsInt("INCOMING_WEBHOOK_RATE", 30),
+		IncomingWebhookBurst: getEnvAsInt("INCOMING_WEBHOOK_BURST", 10),
+
+		MetricsAddr: getEnv("METRICS_ADDR", "127.0.0.1:9090"),
+
+		ModerationWords:          getEnvAsList("MODERATION_WORDS"),
+		ModerationBlockedDomains: getEnvAsList("MODERATION_BLOCKED_DOMAINS"),
+
+		RateLimitUserPerMinute: getEnvAsInt("RATE_LIMIT_USER_PER_MINUTE", 30),
+		RateLimitUserBurst:     getEnvAsInt("RATE_LIMIT_USER_BURST", 10),
+		RateLimitChatPerMinute: getEnvAsInt("RATE_LIMIT_CHAT_PER_MINUTE", 300),
+		RateLimitChatBurst:     getEnvAsInt("RATE_LIMIT_CHAT_BURST", 50),
+
+		RateLimitGlobalPerMinute: getEnvAsInt("RATE_LIMIT_USER_GLOBAL_PER_MINUTE", 60),
+		RateLimitGlobalBurst:     getEnvAsInt("RATE_LIMIT_USER_GLOBAL_BURST", 20),
+
+		StreamQueueSize:      getEnvAsInt("STREAM_QUEUE_SIZE", 256),
+		StreamOverflowPolicy: getEnv("STREAM_OVERFLOW_POLICY", "drop_oldest"),
 	}
 }
 
@@ -90,3 +168,43 @@ func getEnvAsDuration(key string, defaultVal time.Duration) time.Duration {
 
 	return time.Duration(valInt) * time.Second
 }
+
+func getEnvAsInt(key string, defaultVal int) int {
+	valStr := os.Getenv(key)
+	if valStr == "" {
+		return defaultVal
+	}
+	val, err := strconv.Atoi(valStr)
+	if err != nil {
+		log.Printf("Invalid number for %s: %s, using default\n", key, valStr)
+		return defaultVal
+	}
+
+	return val
+}
+
+func getEnvAsBool(key string, defaultVal bool) bool {
+	valStr := os.Getenv(key)
+	if valStr == "" {
+		return defaultVal
+	}
+	val, err := strconv.ParseBool(valStr)
+	if err != nil {
+		log.Printf("Invalid boolean for %s: %s, using default\n", key, valStr)
+		return defaultVal
+	}
+
+	return val
+}
+
+// getEnvAsList reads a comma separated list, empty items are skipped.
+func getEnvAsList(key string) []string {
+	var list []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
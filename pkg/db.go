@@ -8,12 +8,17 @@ import (
 	"go.uber.org/zap"
 )
 
-func NewDbChat(log *zap.Logger) (*sql.DB, error) {
+// ChatConnString is the connection string of the chat DB, also used by LISTEN connections.
+func ChatConnString() string {
 	cfg := config.LoadConfig()
-	connStr := fmt.Sprintf(
+	return fmt.Sprintf(
 		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
 		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName,
 	)
+}
+
+func NewDbChat(log *zap.Logger) (*sql.DB, error) {
+	connStr := ChatConnString()
 	log.Info("Connecting to chat DB", zap.String("connStr", connStr))
 
 	db, err := sql.Open("postgres", connStr)
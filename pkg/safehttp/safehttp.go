@@ -0,0 +1,100 @@
+// Package safehttp sends requests to URLs supplied by users. Its client does not
+// connect to loopback, private or link-local addresses, so a user can not make
+// the service reach into the internal network.
+package safehttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+var ErrForbiddenAddress = errors.New("address is not allowed")
+
+// forbiddenPrefixes are the ranges not covered by the checks of netip.Addr.
+var forbiddenPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// Allowed reports whether requests may be sent to the address.
+func Allowed(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+
+	for _, prefix := range forbiddenPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NewClient returns a client with the timeout that refuses to connect to
+// addresses that are not Allowed. The address is checked when connecting, so
+// redirects and DNS answers that change after a check are covered as well.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: control}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// a proxy would be the one to connect, out of reach of the check
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// dialAllowed decides whether the client may connect, tests replace it to have
+// a server on loopback stand for a public one.
+var dialAllowed = func(addrPort netip.AddrPort) bool {
+	return Allowed(addrPort.Addr())
+}
+
+func control(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !dialAllowed(addrPort) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addrPort.Addr())
+	}
+
+	return nil
+}
+
+// CheckURL resolves the host of an http(s) URL and returns ErrForbiddenAddress
+// if any of its addresses is not Allowed. It lets a bad URL be refused when it
+// is saved, the requests are still checked by the client.
+func CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return errors.New("url must be an absolute http(s) url")
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if !Allowed(addr) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrForbiddenAddress, u.Hostname(), addr)
+		}
+	}
+
+	return nil
+}
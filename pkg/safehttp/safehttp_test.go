@@ -0,0 +1,99 @@
+package safehttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowed(t *testing.T) {
+	tests := []struct {
+		addr    string
+		allowed bool
+	}{
+		{"127.0.0.1", false},
+		{"127.1.2.3", false},
+		{"::1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"10.0.0.1", false},
+		{"172.16.5.4", false},
+		{"192.168.1.1", false},
+		{"fd00::1", false},
+		{"100.64.0.1", false},
+		{"224.0.0.1", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:169.254.169.254", false},
+		{"::ffff:10.0.0.1", false},
+		{"64:ff9b::a00:1", false},
+		{"8.8.8.8", true},
+		{"::ffff:8.8.8.8", true},
+		{"2606:4700:4700::1111", true},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.allowed, Allowed(netip.MustParseAddr(tt.addr)), tt.addr)
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	ctx := context.Background()
+
+	require.ErrorIs(t, CheckURL(ctx, "http://127.0.0.1:8080/hook"), ErrForbiddenAddress)
+	require.ErrorIs(t, CheckURL(ctx, "http://[::ffff:127.0.0.1]/hook"), ErrForbiddenAddress)
+	// a name that resolves to an internal address
+	require.ErrorIs(t, CheckURL(ctx, "http://localhost/hook"), ErrForbiddenAddress)
+
+	require.Error(t, CheckURL(ctx, "ftp://example.com/file"))
+	require.Error(t, CheckURL(ctx, "/relative/path"))
+}
+
+func TestClientRefusesInternalAddress(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	client := NewClient(time.Second)
+	_, err = client.Get(server.URL)
+	require.ErrorIs(t, err, ErrForbiddenAddress)
+
+	// by name the connection is refused all the same
+	_, err = client.Get("http://localhost:" + u.Port())
+	require.ErrorIs(t, err, ErrForbiddenAddress)
+	require.False(t, called)
+}
+
+func TestClientRefusesRedirectToInternalAddress(t *testing.T) {
+	internalCalled := false
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		internalCalled = true
+	}))
+	defer internal.Close()
+
+	public := httptest.NewServer(http.RedirectHandler(internal.URL+"/admin", http.StatusFound))
+	defer public.Close()
+
+	// the public server stands for an address on the internet
+	publicAddr := netip.MustParseAddrPort(public.Listener.Addr().String())
+	defer func(allowed func(netip.AddrPort) bool) { dialAllowed = allowed }(dialAllowed)
+	dialAllowed = func(addrPort netip.AddrPort) bool {
+		return addrPort == publicAddr
+	}
+
+	_, err := NewClient(time.Second).Get(public.URL)
+	require.ErrorIs(t, err, ErrForbiddenAddress)
+	require.False(t, internalCalled)
+}
@@ -0,0 +1,19 @@
+package verifier
+
+import "github.com/golang-jwt/jwt/v5"
+
+const (
+	AccessTokenType  = "access"
+	RefreshTokenType = "refresh"
+)
+
+// Claims are the claims of the tokens issued by the Auth service.
+type Claims struct {
+	UserID int64
+	Role   string
+	// SessionID is the login the token belongs to, zero for bot tokens.
+	SessionID int64
+	// Type keeps a refresh token from being used as an access token and back.
+	Type string `json:"typ"`
+	jwt.RegisteredClaims
+}
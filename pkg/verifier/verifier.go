@@ -0,0 +1,169 @@
+// Package verifier checks access tokens of the Auth service without calling
+// it: the public keys are fetched from its JWK set and cached.
+package verifier
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"chat-grpc/proto_gen"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRefreshInterval keeps tokens with made up key ids from making the
+// verifier fetch the keys on every call.
+const minRefreshInterval = 10 * time.Second
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// KeySource fetches the current JWK set.
+type KeySource func(ctx context.Context) ([]JWK, error)
+
+// FromAuthService fetches the keys with the GetJWKS RPC.
+func FromAuthService(client proto_gen.AuthServiceClient) KeySource {
+	return func(ctx context.Context) ([]JWK, error) {
+		resp, err := client.GetJWKS(ctx, &proto_gen.AuthEmpty{})
+		if err != nil {
+			return nil, err
+		}
+
+		keys := make([]JWK, 0, len(resp.Keys))
+		for _, k := range resp.Keys {
+			keys = append(keys, JWKFromProto(k))
+		}
+		return keys, nil
+	}
+}
+
+// FromURL fetches the keys from a /.well-known/jwks.json endpoint.
+func FromURL(url string) KeySource {
+	return func(ctx context.Context) ([]JWK, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("jwks endpoint returned %s", resp.Status)
+		}
+
+		var jwks JWKS
+		if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+			return nil, err
+		}
+		return jwks.Keys, nil
+	}
+}
+
+// Verifier checks the signature, expiry and type of access tokens. Revocation
+// is not its business, the revocation list lives in the Auth service.
+//
+// The keys are refetched once they are older than the refresh interval or when
+// a token names a key the verifier has not seen, e.g. right after a rotation.
+// If the source is unreachable the cached keys keep working.
+type Verifier struct {
+	source  KeySource
+	refresh time.Duration
+
+	fetchMu     sync.Mutex
+	lastAttempt time.Time
+
+	mu        sync.RWMutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+func New(source KeySource, refresh time.Duration) *Verifier {
+	return &Verifier{
+		source:  source,
+		refresh: refresh,
+		keys:    make(map[string]crypto.PublicKey),
+	}
+}
+
+// Verify returns the claims of a valid access token.
+func (v *Verifier) Verify(ctx context.Context, tokenStr string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return v.key(ctx, kid)
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg(), jwt.SigningMethodRS256.Alg()}))
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, errors.New("incorrect token")
+	}
+	if claims.Type != AccessTokenType {
+		return nil, fmt.Errorf("%s token used as access token", claims.Type)
+	}
+
+	return claims, nil
+}
+
+func (v *Verifier) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	v.mu.RLock()
+	key, ok := v.keys[kid]
+	stale := time.Since(v.fetchedAt) > v.refresh
+	v.mu.RUnlock()
+
+	if ok && !stale {
+		return key, nil
+	}
+
+	if err := v.fetch(ctx); err != nil && !ok {
+		return nil, err
+	}
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	if key, ok := v.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownKey, kid)
+}
+
+func (v *Verifier) fetch(ctx context.Context) error {
+	v.fetchMu.Lock()
+	defer v.fetchMu.Unlock()
+
+	if time.Since(v.lastAttempt) < minRefreshInterval {
+		return nil
+	}
+	v.lastAttempt = time.Now()
+
+	jwks, err := v.source(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(jwks))
+	for _, jwk := range jwks {
+		key, err := jwk.PublicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	v.mu.Lock()
+	v.keys = keys
+	v.fetchedAt = time.Now()
+	v.mu.Unlock()
+
+	return nil
+}
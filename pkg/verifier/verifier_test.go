@@ -0,0 +1,140 @@
+package verifier
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// keySource serves the public keys of signers and counts the fetches.
+type keySource struct {
+	keys    atomic.Pointer[[]JWK]
+	fetches atomic.Int32
+}
+
+func (s *keySource) set(t *testing.T, public map[string]crypto.PublicKey) {
+	t.Helper()
+
+	var keys []JWK
+	for kid, key := range public {
+		jwk, err := NewJWK(kid, key)
+		require.NoError(t, err)
+		keys = append(keys, jwk)
+	}
+	s.keys.Store(&keys)
+}
+
+func (s *keySource) fetch(ctx context.Context) ([]JWK, error) {
+	s.fetches.Add(1)
+	return *s.keys.Load(), nil
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key crypto.Signer, tokenType string) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, &Claims{
+		UserID: 1,
+		Role:   "user",
+		Type:   tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	})
+	token.Header["kid"] = kid
+
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	return signed
+}
+
+func newEd25519(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	return public, private
+}
+
+func TestVerifyAccessToken(t *testing.T) {
+	public, private := newEd25519(t)
+	source := &keySource{}
+	source.set(t, map[string]crypto.PublicKey{"k1": public})
+	v := New(source.fetch, time.Hour)
+
+	claims, err := v.Verify(context.Background(), sign(t, jwt.SigningMethodEdDSA, "k1", private, AccessTokenType))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), claims.UserID)
+	require.Equal(t, int32(1), source.fetches.Load())
+}
+
+func TestVerifyRejectsRefreshToken(t *testing.T) {
+	public, private := newEd25519(t)
+	source := &keySource{}
+	source.set(t, map[string]crypto.PublicKey{"k1": public})
+	v := New(source.fetch, time.Hour)
+
+	// signed with the access token key but typed as refresh token
+	_, err := v.Verify(context.Background(), sign(t, jwt.SigningMethodEdDSA, "k1", private, RefreshTokenType))
+	require.ErrorContains(t, err, "refresh token used as access token")
+
+	// refresh tokens of the Auth service are signed with a secret
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{UserID: 1, Type: RefreshTokenType})
+	signed, err := token.SignedString([]byte("refresh_secret_key"))
+	require.NoError(t, err)
+	_, err = v.Verify(context.Background(), signed)
+	require.ErrorIs(t, err, jwt.ErrTokenSignatureInvalid)
+}
+
+func TestVerifyRejectsAlgorithmOfOtherKeyType(t *testing.T) {
+	public, _ := newEd25519(t)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	source := &keySource{}
+	source.set(t, map[string]crypto.PublicKey{"ed": public})
+	v := New(source.fetch, time.Hour)
+
+	// an RS256 header naming the Ed25519 key
+	_, err = v.Verify(context.Background(), sign(t, jwt.SigningMethodRS256, "ed", rsaKey, AccessTokenType))
+	require.Error(t, err)
+}
+
+func TestUnknownKidRefetchesKeysAtMostOncePerInterval(t *testing.T) {
+	oldPublic, _ := newEd25519(t)
+	newPublic, newPrivate := newEd25519(t)
+	source := &keySource{}
+	source.set(t, map[string]crypto.PublicKey{"old": oldPublic})
+	v := New(source.fetch, time.Hour)
+
+	ctx := context.Background()
+	_, err := v.key(ctx, "old")
+	require.NoError(t, err)
+	require.Equal(t, int32(1), source.fetches.Load())
+
+	// made up key ids do not make the verifier fetch again right away
+	_, err = v.Verify(ctx, sign(t, jwt.SigningMethodEdDSA, "made-up", newPrivate, AccessTokenType))
+	require.ErrorIs(t, err, ErrUnknownKey)
+	_, err = v.Verify(ctx, sign(t, jwt.SigningMethodEdDSA, "made-up-too", newPrivate, AccessTokenType))
+	require.ErrorIs(t, err, ErrUnknownKey)
+	require.Equal(t, int32(1), source.fetches.Load())
+
+	// after a rotation the new kid is fetched once the interval has passed
+	source.set(t, map[string]crypto.PublicKey{"old": oldPublic, "new": newPublic})
+	v.fetchMu.Lock()
+	v.lastAttempt = time.Now().Add(-minRefreshInterval)
+	v.fetchMu.Unlock()
+
+	_, err = v.Verify(ctx, sign(t, jwt.SigningMethodEdDSA, "new", newPrivate, AccessTokenType))
+	require.NoError(t, err)
+	require.Equal(t, int32(2), source.fetches.Load())
+}
@@ -19,19 +19,30 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	AuthService_Create_FullMethodName             = "/auth.AuthService/Create"
-	AuthService_Get_FullMethodName                = "/auth.AuthService/Get"
-	AuthService_GetList_FullMethodName            = "/auth.AuthService/GetList"
-	AuthService_Update_FullMethodName             = "/auth.AuthService/Update"
-	AuthService_Delete_FullMethodName             = "/auth.AuthService/Delete"
-	AuthService_Login_FullMethodName              = "/auth.AuthService/Login"
-	AuthService_GetRefreshToken_FullMethodName    = "/auth.AuthService/GetRefreshToken"
-	AuthService_GetAccessToken_FullMethodName     = "/auth.AuthService/GetAccessToken"
-	AuthService_Check_FullMethodName              = "/auth.AuthService/Check"
-	AuthService_CheckToken_FullMethodName         = "/auth.AuthService/CheckToken"
-	AuthService_GetChatUsersEmails_FullMethodName = "/auth.AuthService/GetChatUsersEmails"
-	AuthService_GetChatUsers_FullMethodName       = "/auth.AuthService/GetChatUsers"
-	AuthService_GetUsersEmailsByID_FullMethodName = "/auth.AuthService/GetUsersEmailsByID"
+	AuthService_Create_FullMethodName                 = "/auth.AuthService/Create"
+	AuthService_Get_FullMethodName                    = "/auth.AuthService/Get"
+	AuthService_GetList_FullMethodName                = "/auth.AuthService/GetList"
+	AuthService_Update_FullMethodName                 = "/auth.AuthService/Update"
+	AuthService_Delete_FullMethodName                 = "/auth.AuthService/Delete"
+	AuthService_Login_FullMethodName                  = "/auth.AuthService/Login"
+	AuthService_GetRefreshToken_FullMethodName        = "/auth.AuthService/GetRefreshToken"
+	AuthService_GetAccessToken_FullMethodName         = "/auth.AuthService/GetAccessToken"
+	AuthService_Check_FullMethodName                  = "/auth.AuthService/Check"
+	AuthService_CheckToken_FullMethodName             = "/auth.AuthService/CheckToken"
+	AuthService_GetChatUsersEmails_FullMethodName     = "/auth.AuthService/GetChatUsersEmails"
+	AuthService_GetChatUsers_FullMethodName           = "/auth.AuthService/GetChatUsers"
+	AuthService_GetUsersEmailsByID_FullMethodName     = "/auth.AuthService/GetUsersEmailsByID"
+	AuthService_CreateBot_FullMethodName              = "/auth.AuthService/CreateBot"
+	AuthService_RotateBotKey_FullMethodName           = "/auth.AuthService/RotateBotKey"
+	AuthService_AuthenticateBot_FullMethodName        = "/auth.AuthService/AuthenticateBot"
+	AuthService_ListSessions_FullMethodName           = "/auth.AuthService/ListSessions"
+	AuthService_RevokeSession_FullMethodName          = "/auth.AuthService/RevokeSession"
+	AuthService_RevokeAllOtherSessions_FullMethodName = "/auth.AuthService/RevokeAllOtherSessions"
+	AuthService_Logout_FullMethodName                 = "/auth.AuthService/Logout"
+	AuthService_GetJWKS_FullMethodName                = "/auth.AuthService/GetJWKS"
+	AuthService_ChangePassword_FullMethodName         = "/auth.AuthService/ChangePassword"
+	AuthService_RequestPasswordReset_FullMethodName   = "/auth.AuthService/RequestPasswordReset"
+	AuthService_ResetPassword_FullMethodName          = "/auth.AuthService/ResetPassword"
 )
 
 // AuthServiceClient is the client API for AuthService service.
@@ -51,6 +62,17 @@ type AuthServiceClient interface {
 	GetChatUsersEmails(ctx context.Context, in *GetChatUsersEmailsRequest, opts ...grpc.CallOption) (*GetChatUsersEmailsResponse, error)
 	GetChatUsers(ctx context.Context, in *GetChatUsersRequest, opts ...grpc.CallOption) (*GetChatUsersResponse, error)
 	GetUsersEmailsByID(ctx context.Context, in *GetUsersEmailsByIDRequest, opts ...grpc.CallOption) (*GetUsersEmailsByIDResponse, error)
+	CreateBot(ctx context.Context, in *CreateBotRequest, opts ...grpc.CallOption) (*BotKeyResponse, error)
+	RotateBotKey(ctx context.Context, in *RotateBotKeyRequest, opts ...grpc.CallOption) (*BotKeyResponse, error)
+	AuthenticateBot(ctx context.Context, in *AuthenticateBotRequest, opts ...grpc.CallOption) (*AccessTokenResponse, error)
+	ListSessions(ctx context.Context, in *AuthEmpty, opts ...grpc.CallOption) (*ListSessionsResponse, error)
+	RevokeSession(ctx context.Context, in *RevokeSessionRequest, opts ...grpc.CallOption) (*AuthEmpty, error)
+	RevokeAllOtherSessions(ctx context.Context, in *AuthEmpty, opts ...grpc.CallOption) (*AuthEmpty, error)
+	Logout(ctx context.Context, in *AuthEmpty, opts ...grpc.CallOption) (*AuthEmpty, error)
+	GetJWKS(ctx context.Context, in *AuthEmpty, opts ...grpc.CallOption) (*JWKSResponse, error)
+	ChangePassword(ctx context.Context, in *ChangePasswordRequest, opts ...grpc.CallOption) (*AuthEmpty, error)
+	RequestPasswordReset(ctx context.Context, in *RequestPasswordResetRequest, opts ...grpc.CallOption) (*AuthEmpty, error)
+	ResetPassword(ctx context.Context, in *ResetPasswordRequest, opts ...grpc.CallOption) (*AuthEmpty, error)
 }
 
 type authServiceClient struct {
@@ -191,6 +213,116 @@ func (c *authServiceClient) GetUsersEmailsByID(ctx context.Context, in *GetUsers
 	return out, nil
 }
 
+func (c *authServiceClient) CreateBot(ctx context.Context, in *CreateBotRequest, opts ...grpc.CallOption) (*BotKeyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BotKeyResponse)
+	err := c.cc.Invoke(ctx, AuthService_CreateBot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authServiceClient) RotateBotKey(ctx context.Context, in *RotateBotKeyRequest, opts ...grpc.CallOption) (*BotKeyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BotKeyResponse)
+	err := c.cc.Invoke(ctx, AuthService_RotateBotKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authServiceClient) AuthenticateBot(ctx context.Context, in *AuthenticateBotRequest, opts ...grpc.CallOption) (*AccessTokenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AccessTokenResponse)
+	err := c.cc.Invoke(ctx, AuthService_AuthenticateBot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authServiceClient) ListSessions(ctx context.Context, in *AuthEmpty, opts ...grpc.CallOption) (*ListSessionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSessionsResponse)
+	err := c.cc.Invoke(ctx, AuthService_ListSessions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authServiceClient) RevokeSession(ctx context.Context, in *RevokeSessionRequest, opts ...grpc.CallOption) (*AuthEmpty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AuthEmpty)
+	err := c.cc.Invoke(ctx, AuthService_RevokeSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authServiceClient) RevokeAllOtherSessions(ctx context.Context, in *AuthEmpty, opts ...grpc.CallOption) (*AuthEmpty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AuthEmpty)
+	err := c.cc.Invoke(ctx, AuthService_RevokeAllOtherSessions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authServiceClient) Logout(ctx context.Context, in *AuthEmpty, opts ...grpc.CallOption) (*AuthEmpty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AuthEmpty)
+	err := c.cc.Invoke(ctx, AuthService_Logout_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authServiceClient) GetJWKS(ctx context.Context, in *AuthEmpty, opts ...grpc.CallOption) (*JWKSResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JWKSResponse)
+	err := c.cc.Invoke(ctx, AuthService_GetJWKS_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authServiceClient) ChangePassword(ctx context.Context, in *ChangePasswordRequest, opts ...grpc.CallOption) (*AuthEmpty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AuthEmpty)
+	err := c.cc.Invoke(ctx, AuthService_ChangePassword_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authServiceClient) RequestPasswordReset(ctx context.Context, in *RequestPasswordResetRequest, opts ...grpc.CallOption) (*AuthEmpty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AuthEmpty)
+	err := c.cc.Invoke(ctx, AuthService_RequestPasswordReset_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authServiceClient) ResetPassword(ctx context.Context, in *ResetPasswordRequest, opts ...grpc.CallOption) (*AuthEmpty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AuthEmpty)
+	err := c.cc.Invoke(ctx, AuthService_ResetPassword_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AuthServiceServer is the server API for AuthService service.
 // All implementations must embed UnimplementedAuthServiceServer
 // for forward compatibility.
@@ -208,6 +340,17 @@ type AuthServiceServer interface {
 	GetChatUsersEmails(context.Context, *GetChatUsersEmailsRequest) (*GetChatUsersEmailsResponse, error)
 	GetChatUsers(context.Context, *GetChatUsersRequest) (*GetChatUsersResponse, error)
 	GetUsersEmailsByID(context.Context, *GetUsersEmailsByIDRequest) (*GetUsersEmailsByIDResponse, error)
+	CreateBot(context.Context, *CreateBotRequest) (*BotKeyResponse, error)
+	RotateBotKey(context.Context, *RotateBotKeyRequest) (*BotKeyResponse, error)
+	AuthenticateBot(context.Context, *AuthenticateBotRequest) (*AccessTokenResponse, error)
+	ListSessions(context.Context, *AuthEmpty) (*ListSessionsResponse, error)
+	RevokeSession(context.Context, *RevokeSessionRequest) (*AuthEmpty, error)
+	RevokeAllOtherSessions(context.Context, *AuthEmpty) (*AuthEmpty, error)
+	Logout(context.Context, *AuthEmpty) (*AuthEmpty, error)
+	GetJWKS(context.Context, *AuthEmpty) (*JWKSResponse, error)
+	ChangePassword(context.Context, *ChangePasswordRequest) (*AuthEmpty, error)
+	RequestPasswordReset(context.Context, *RequestPasswordResetRequest) (*AuthEmpty, error)
+	ResetPassword(context.Context, *ResetPasswordRequest) (*AuthEmpty, error)
 	mustEmbedUnimplementedAuthServiceServer()
 }
 
@@ -257,6 +400,39 @@ func (UnimplementedAuthServiceServer) GetChatUsers(context.Context, *GetChatUser
 func (UnimplementedAuthServiceServer) GetUsersEmailsByID(context.Context, *GetUsersEmailsByIDRequest) (*GetUsersEmailsByIDResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetUsersEmailsByID not implemented")
 }
+func (UnimplementedAuthServiceServer) CreateBot(context.Context, *CreateBotRequest) (*BotKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateBot not implemented")
+}
+func (UnimplementedAuthServiceServer) RotateBotKey(context.Context, *RotateBotKeyRequest) (*BotKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RotateBotKey not implemented")
+}
+func (UnimplementedAuthServiceServer) AuthenticateBot(context.Context, *AuthenticateBotRequest) (*AccessTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AuthenticateBot not implemented")
+}
+func (UnimplementedAuthServiceServer) ListSessions(context.Context, *AuthEmpty) (*ListSessionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSessions not implemented")
+}
+func (UnimplementedAuthServiceServer) RevokeSession(context.Context, *RevokeSessionRequest) (*AuthEmpty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeSession not implemented")
+}
+func (UnimplementedAuthServiceServer) RevokeAllOtherSessions(context.Context, *AuthEmpty) (*AuthEmpty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeAllOtherSessions not implemented")
+}
+func (UnimplementedAuthServiceServer) Logout(context.Context, *AuthEmpty) (*AuthEmpty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Logout not implemented")
+}
+func (UnimplementedAuthServiceServer) GetJWKS(context.Context, *AuthEmpty) (*JWKSResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetJWKS not implemented")
+}
+func (UnimplementedAuthServiceServer) ChangePassword(context.Context, *ChangePasswordRequest) (*AuthEmpty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChangePassword not implemented")
+}
+func (UnimplementedAuthServiceServer) RequestPasswordReset(context.Context, *RequestPasswordResetRequest) (*AuthEmpty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestPasswordReset not implemented")
+}
+func (UnimplementedAuthServiceServer) ResetPassword(context.Context, *ResetPasswordRequest) (*AuthEmpty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResetPassword not implemented")
+}
 func (UnimplementedAuthServiceServer) mustEmbedUnimplementedAuthServiceServer() {}
 func (UnimplementedAuthServiceServer) testEmbeddedByValue()                     {}
 
@@ -512,6 +688,204 @@ func _AuthService_GetUsersEmailsByID_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AuthService_CreateBot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateBotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).CreateBot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthService_CreateBot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).CreateBot(ctx, req.(*CreateBotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthService_RotateBotKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RotateBotKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).RotateBotKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthService_RotateBotKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).RotateBotKey(ctx, req.(*RotateBotKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthService_AuthenticateBot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuthenticateBotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).AuthenticateBot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthService_AuthenticateBot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).AuthenticateBot(ctx, req.(*AuthenticateBotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthService_ListSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuthEmpty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).ListSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthService_ListSessions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).ListSessions(ctx, req.(*AuthEmpty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthService_RevokeSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).RevokeSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthService_RevokeSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).RevokeSession(ctx, req.(*RevokeSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthService_RevokeAllOtherSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuthEmpty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).RevokeAllOtherSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthService_RevokeAllOtherSessions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).RevokeAllOtherSessions(ctx, req.(*AuthEmpty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthService_Logout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuthEmpty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).Logout(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthService_Logout_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).Logout(ctx, req.(*AuthEmpty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthService_GetJWKS_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuthEmpty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).GetJWKS(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthService_GetJWKS_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).GetJWKS(ctx, req.(*AuthEmpty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthService_ChangePassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangePasswordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).ChangePassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthService_ChangePassword_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).ChangePassword(ctx, req.(*ChangePasswordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthService_RequestPasswordReset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestPasswordResetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).RequestPasswordReset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthService_RequestPasswordReset_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).RequestPasswordReset(ctx, req.(*RequestPasswordResetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthService_ResetPassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResetPasswordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).ResetPassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthService_ResetPassword_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).ResetPassword(ctx, req.(*ResetPasswordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // AuthService_ServiceDesc is the grpc.ServiceDesc for AuthService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -571,6 +945,50 @@ var AuthService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetUsersEmailsByID",
 			Handler:    _AuthService_GetUsersEmailsByID_Handler,
 		},
+		{
+			MethodName: "CreateBot",
+			Handler:    _AuthService_CreateBot_Handler,
+		},
+		{
+			MethodName: "RotateBotKey",
+			Handler:    _AuthService_RotateBotKey_Handler,
+		},
+		{
+			MethodName: "AuthenticateBot",
+			Handler:    _AuthService_AuthenticateBot_Handler,
+		},
+		{
+			MethodName: "ListSessions",
+			Handler:    _AuthService_ListSessions_Handler,
+		},
+		{
+			MethodName: "RevokeSession",
+			Handler:    _AuthService_RevokeSession_Handler,
+		},
+		{
+			MethodName: "RevokeAllOtherSessions",
+			Handler:    _AuthService_RevokeAllOtherSessions_Handler,
+		},
+		{
+			MethodName: "Logout",
+			Handler:    _AuthService_Logout_Handler,
+		},
+		{
+			MethodName: "GetJWKS",
+			Handler:    _AuthService_GetJWKS_Handler,
+		},
+		{
+			MethodName: "ChangePassword",
+			Handler:    _AuthService_ChangePassword_Handler,
+		},
+		{
+			MethodName: "RequestPasswordReset",
+			Handler:    _AuthService_RequestPasswordReset_Handler,
+		},
+		{
+			MethodName: "ResetPassword",
+			Handler:    _AuthService_ResetPassword_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto_files/auth.proto",
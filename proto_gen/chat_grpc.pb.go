@@ -19,12 +19,31 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	ChatService_Create_FullMethodName            = "/chat.ChatService/Create"
-	ChatService_Delete_FullMethodName            = "/chat.ChatService/Delete"
-	ChatService_SendMessage_FullMethodName       = "/chat.ChatService/SendMessage"
-	ChatService_Connect_FullMethodName           = "/chat.ChatService/Connect"
-	ChatService_GetMessages_FullMethodName       = "/chat.ChatService/GetMessages"
-	ChatService_CancelSendMessage_FullMethodName = "/chat.ChatService/CancelSendMessage"
+	ChatService_Create_FullMethodName                = "/chat.ChatService/Create"
+	ChatService_Delete_FullMethodName                = "/chat.ChatService/Delete"
+	ChatService_SendMessage_FullMethodName           = "/chat.ChatService/SendMessage"
+	ChatService_Connect_FullMethodName               = "/chat.ChatService/Connect"
+	ChatService_GetMessages_FullMethodName           = "/chat.ChatService/GetMessages"
+	ChatService_CancelSendMessage_FullMethodName     = "/chat.ChatService/CancelSendMessage"
+	ChatService_CreatePoll_FullMethodName            = "/chat.ChatService/CreatePoll"
+	ChatService_Vote_FullMethodName                  = "/chat.ChatService/Vote"
+	ChatService_ClosePoll_FullMethodName             = "/chat.ChatService/ClosePoll"
+	ChatService_AddBot_FullMethodName                = "/chat.ChatService/AddBot"
+	ChatService_BotConnect_FullMethodName            = "/chat.ChatService/BotConnect"
+	ChatService_RegisterWebhook_FullMethodName       = "/chat.ChatService/RegisterWebhook"
+	ChatService_DeleteWebhook_FullMethodName         = "/chat.ChatService/DeleteWebhook"
+	ChatService_ListWebhooks_FullMethodName          = "/chat.ChatService/ListWebhooks"
+	ChatService_CreateIncomingWebhook_FullMethodName = "/chat.ChatService/CreateIncomingWebhook"
+	ChatService_RotateIncomingWebhook_FullMethodName = "/chat.ChatService/RotateIncomingWebhook"
+	ChatService_RevokeIncomingWebhook_FullMethodName = "/chat.ChatService/RevokeIncomingWebhook"
+	ChatService_ReportMessage_FullMethodName         = "/chat.ChatService/ReportMessage"
+	ChatService_SetModerationSettings_FullMethodName = "/chat.ChatService/SetModerationSettings"
+	ChatService_GetFlaggedMessages_FullMethodName    = "/chat.ChatService/GetFlaggedMessages"
+	ChatService_ReviewMessage_FullMethodName         = "/chat.ChatService/ReviewMessage"
+	ChatService_BanUser_FullMethodName               = "/chat.ChatService/BanUser"
+	ChatService_UnbanUser_FullMethodName             = "/chat.ChatService/UnbanUser"
+	ChatService_SetRateLimits_FullMethodName         = "/chat.ChatService/SetRateLimits"
+	ChatService_GetRateLimits_FullMethodName         = "/chat.ChatService/GetRateLimits"
 )
 
 // ChatServiceClient is the client API for ChatService service.
@@ -33,10 +52,29 @@ const (
 type ChatServiceClient interface {
 	Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*CreateResponse, error)
 	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*ChatEmpty, error)
-	SendMessage(ctx context.Context, in *SendMessageRequest, opts ...grpc.CallOption) (*ChatEmpty, error)
+	SendMessage(ctx context.Context, in *SendMessageRequest, opts ...grpc.CallOption) (*SendMessageResponse, error)
 	Connect(ctx context.Context, in *ConnectRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Message], error)
 	GetMessages(ctx context.Context, in *GetMessagesRequest, opts ...grpc.CallOption) (*GetMessagesResponse, error)
 	CancelSendMessage(ctx context.Context, in *CancelSendMessageRequest, opts ...grpc.CallOption) (*ChatEmpty, error)
+	CreatePoll(ctx context.Context, in *CreatePollRequest, opts ...grpc.CallOption) (*CreatePollResponse, error)
+	Vote(ctx context.Context, in *VoteRequest, opts ...grpc.CallOption) (*ChatEmpty, error)
+	ClosePoll(ctx context.Context, in *ClosePollRequest, opts ...grpc.CallOption) (*ChatEmpty, error)
+	AddBot(ctx context.Context, in *AddBotRequest, opts ...grpc.CallOption) (*ChatEmpty, error)
+	BotConnect(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[BotRequest, Message], error)
+	RegisterWebhook(ctx context.Context, in *RegisterWebhookRequest, opts ...grpc.CallOption) (*RegisterWebhookResponse, error)
+	DeleteWebhook(ctx context.Context, in *DeleteWebhookRequest, opts ...grpc.CallOption) (*ChatEmpty, error)
+	ListWebhooks(ctx context.Context, in *ListWebhooksRequest, opts ...grpc.CallOption) (*ListWebhooksResponse, error)
+	CreateIncomingWebhook(ctx context.Context, in *CreateIncomingWebhookRequest, opts ...grpc.CallOption) (*IncomingWebhookTokenResponse, error)
+	RotateIncomingWebhook(ctx context.Context, in *IncomingWebhookRequest, opts ...grpc.CallOption) (*IncomingWebhookTokenResponse, error)
+	RevokeIncomingWebhook(ctx context.Context, in *IncomingWebhookRequest, opts ...grpc.CallOption) (*ChatEmpty, error)
+	ReportMessage(ctx context.Context, in *ReportMessageRequest, opts ...grpc.CallOption) (*ChatEmpty, error)
+	SetModerationSettings(ctx context.Context, in *ModerationSettingsRequest, opts ...grpc.CallOption) (*ChatEmpty, error)
+	GetFlaggedMessages(ctx context.Context, in *GetFlaggedMessagesRequest, opts ...grpc.CallOption) (*GetFlaggedMessagesResponse, error)
+	ReviewMessage(ctx context.Context, in *ReviewMessageRequest, opts ...grpc.CallOption) (*ChatEmpty, error)
+	BanUser(ctx context.Context, in *BanUserRequest, opts ...grpc.CallOption) (*ChatEmpty, error)
+	UnbanUser(ctx context.Context, in *BanUserRequest, opts ...grpc.CallOption) (*ChatEmpty, error)
+	SetRateLimits(ctx context.Context, in *SetRateLimitsRequest, opts ...grpc.CallOption) (*ChatEmpty, error)
+	GetRateLimits(ctx context.Context, in *GetRateLimitsRequest, opts ...grpc.CallOption) (*RateLimits, error)
 }
 
 type chatServiceClient struct {
@@ -67,9 +105,9 @@ func (c *chatServiceClient) Delete(ctx context.Context, in *DeleteRequest, opts
 	return out, nil
 }
 
-func (c *chatServiceClient) SendMessage(ctx context.Context, in *SendMessageRequest, opts ...grpc.CallOption) (*ChatEmpty, error) {
+func (c *chatServiceClient) SendMessage(ctx context.Context, in *SendMessageRequest, opts ...grpc.CallOption) (*SendMessageResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(ChatEmpty)
+	out := new(SendMessageResponse)
 	err := c.cc.Invoke(ctx, ChatService_SendMessage_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
@@ -116,16 +154,228 @@ func (c *chatServiceClient) CancelSendMessage(ctx context.Context, in *CancelSen
 	return out, nil
 }
 
+func (c *chatServiceClient) CreatePoll(ctx context.Context, in *CreatePollRequest, opts ...grpc.CallOption) (*CreatePollResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreatePollResponse)
+	err := c.cc.Invoke(ctx, ChatService_CreatePoll_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) Vote(ctx context.Context, in *VoteRequest, opts ...grpc.CallOption) (*ChatEmpty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChatEmpty)
+	err := c.cc.Invoke(ctx, ChatService_Vote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) ClosePoll(ctx context.Context, in *ClosePollRequest, opts ...grpc.CallOption) (*ChatEmpty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChatEmpty)
+	err := c.cc.Invoke(ctx, ChatService_ClosePoll_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) AddBot(ctx context.Context, in *AddBotRequest, opts ...grpc.CallOption) (*ChatEmpty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChatEmpty)
+	err := c.cc.Invoke(ctx, ChatService_AddBot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) BotConnect(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[BotRequest, Message], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ChatService_ServiceDesc.Streams[1], ChatService_BotConnect_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[BotRequest, Message]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ChatService_BotConnectClient = grpc.BidiStreamingClient[BotRequest, Message]
+
+func (c *chatServiceClient) RegisterWebhook(ctx context.Context, in *RegisterWebhookRequest, opts ...grpc.CallOption) (*RegisterWebhookResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RegisterWebhookResponse)
+	err := c.cc.Invoke(ctx, ChatService_RegisterWebhook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) DeleteWebhook(ctx context.Context, in *DeleteWebhookRequest, opts ...grpc.CallOption) (*ChatEmpty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChatEmpty)
+	err := c.cc.Invoke(ctx, ChatService_DeleteWebhook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) ListWebhooks(ctx context.Context, in *ListWebhooksRequest, opts ...grpc.CallOption) (*ListWebhooksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListWebhooksResponse)
+	err := c.cc.Invoke(ctx, ChatService_ListWebhooks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) CreateIncomingWebhook(ctx context.Context, in *CreateIncomingWebhookRequest, opts ...grpc.CallOption) (*IncomingWebhookTokenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(IncomingWebhookTokenResponse)
+	err := c.cc.Invoke(ctx, ChatService_CreateIncomingWebhook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) RotateIncomingWebhook(ctx context.Context, in *IncomingWebhookRequest, opts ...grpc.CallOption) (*IncomingWebhookTokenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(IncomingWebhookTokenResponse)
+	err := c.cc.Invoke(ctx, ChatService_RotateIncomingWebhook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) RevokeIncomingWebhook(ctx context.Context, in *IncomingWebhookRequest, opts ...grpc.CallOption) (*ChatEmpty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChatEmpty)
+	err := c.cc.Invoke(ctx, ChatService_RevokeIncomingWebhook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) ReportMessage(ctx context.Context, in *ReportMessageRequest, opts ...grpc.CallOption) (*ChatEmpty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChatEmpty)
+	err := c.cc.Invoke(ctx, ChatService_ReportMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) SetModerationSettings(ctx context.Context, in *ModerationSettingsRequest, opts ...grpc.CallOption) (*ChatEmpty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChatEmpty)
+	err := c.cc.Invoke(ctx, ChatService_SetModerationSettings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) GetFlaggedMessages(ctx context.Context, in *GetFlaggedMessagesRequest, opts ...grpc.CallOption) (*GetFlaggedMessagesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetFlaggedMessagesResponse)
+	err := c.cc.Invoke(ctx, ChatService_GetFlaggedMessages_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) ReviewMessage(ctx context.Context, in *ReviewMessageRequest, opts ...grpc.CallOption) (*ChatEmpty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChatEmpty)
+	err := c.cc.Invoke(ctx, ChatService_ReviewMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) BanUser(ctx context.Context, in *BanUserRequest, opts ...grpc.CallOption) (*ChatEmpty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChatEmpty)
+	err := c.cc.Invoke(ctx, ChatService_BanUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) UnbanUser(ctx context.Context, in *BanUserRequest, opts ...grpc.CallOption) (*ChatEmpty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChatEmpty)
+	err := c.cc.Invoke(ctx, ChatService_UnbanUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) SetRateLimits(ctx context.Context, in *SetRateLimitsRequest, opts ...grpc.CallOption) (*ChatEmpty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChatEmpty)
+	err := c.cc.Invoke(ctx, ChatService_SetRateLimits_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) GetRateLimits(ctx context.Context, in *GetRateLimitsRequest, opts ...grpc.CallOption) (*RateLimits, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RateLimits)
+	err := c.cc.Invoke(ctx, ChatService_GetRateLimits_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ChatServiceServer is the server API for ChatService service.
 // All implementations must embed UnimplementedChatServiceServer
 // for forward compatibility.
 type ChatServiceServer interface {
 	Create(context.Context, *CreateRequest) (*CreateResponse, error)
 	Delete(context.Context, *DeleteRequest) (*ChatEmpty, error)
-	SendMessage(context.Context, *SendMessageRequest) (*ChatEmpty, error)
+	SendMessage(context.Context, *SendMessageRequest) (*SendMessageResponse, error)
 	Connect(*ConnectRequest, grpc.ServerStreamingServer[Message]) error
 	GetMessages(context.Context, *GetMessagesRequest) (*GetMessagesResponse, error)
 	CancelSendMessage(context.Context, *CancelSendMessageRequest) (*ChatEmpty, error)
+	CreatePoll(context.Context, *CreatePollRequest) (*CreatePollResponse, error)
+	Vote(context.Context, *VoteRequest) (*ChatEmpty, error)
+	ClosePoll(context.Context, *ClosePollRequest) (*ChatEmpty, error)
+	AddBot(context.Context, *AddBotRequest) (*ChatEmpty, error)
+	BotConnect(grpc.BidiStreamingServer[BotRequest, Message]) error
+	RegisterWebhook(context.Context, *RegisterWebhookRequest) (*RegisterWebhookResponse, error)
+	DeleteWebhook(context.Context, *DeleteWebhookRequest) (*ChatEmpty, error)
+	ListWebhooks(context.Context, *ListWebhooksRequest) (*ListWebhooksResponse, error)
+	CreateIncomingWebhook(context.Context, *CreateIncomingWebhookRequest) (*IncomingWebhookTokenResponse, error)
+	RotateIncomingWebhook(context.Context, *IncomingWebhookRequest) (*IncomingWebhookTokenResponse, error)
+	RevokeIncomingWebhook(context.Context, *IncomingWebhookRequest) (*ChatEmpty, error)
+	ReportMessage(context.Context, *ReportMessageRequest) (*ChatEmpty, error)
+	SetModerationSettings(context.Context, *ModerationSettingsRequest) (*ChatEmpty, error)
+	GetFlaggedMessages(context.Context, *GetFlaggedMessagesRequest) (*GetFlaggedMessagesResponse, error)
+	ReviewMessage(context.Context, *ReviewMessageRequest) (*ChatEmpty, error)
+	BanUser(context.Context, *BanUserRequest) (*ChatEmpty, error)
+	UnbanUser(context.Context, *BanUserRequest) (*ChatEmpty, error)
+	SetRateLimits(context.Context, *SetRateLimitsRequest) (*ChatEmpty, error)
+	GetRateLimits(context.Context, *GetRateLimitsRequest) (*RateLimits, error)
 	mustEmbedUnimplementedChatServiceServer()
 }
 
@@ -142,7 +392,7 @@ func (UnimplementedChatServiceServer) Create(context.Context, *CreateRequest) (*
 func (UnimplementedChatServiceServer) Delete(context.Context, *DeleteRequest) (*ChatEmpty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
 }
-func (UnimplementedChatServiceServer) SendMessage(context.Context, *SendMessageRequest) (*ChatEmpty, error) {
+func (UnimplementedChatServiceServer) SendMessage(context.Context, *SendMessageRequest) (*SendMessageResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SendMessage not implemented")
 }
 func (UnimplementedChatServiceServer) Connect(*ConnectRequest, grpc.ServerStreamingServer[Message]) error {
@@ -154,6 +404,63 @@ func (UnimplementedChatServiceServer) GetMessages(context.Context, *GetMessagesR
 func (UnimplementedChatServiceServer) CancelSendMessage(context.Context, *CancelSendMessageRequest) (*ChatEmpty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CancelSendMessage not implemented")
 }
+func (UnimplementedChatServiceServer) CreatePoll(context.Context, *CreatePollRequest) (*CreatePollResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePoll not implemented")
+}
+func (UnimplementedChatServiceServer) Vote(context.Context, *VoteRequest) (*ChatEmpty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Vote not implemented")
+}
+func (UnimplementedChatServiceServer) ClosePoll(context.Context, *ClosePollRequest) (*ChatEmpty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClosePoll not implemented")
+}
+func (UnimplementedChatServiceServer) AddBot(context.Context, *AddBotRequest) (*ChatEmpty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddBot not implemented")
+}
+func (UnimplementedChatServiceServer) BotConnect(grpc.BidiStreamingServer[BotRequest, Message]) error {
+	return status.Errorf(codes.Unimplemented, "method BotConnect not implemented")
+}
+func (UnimplementedChatServiceServer) RegisterWebhook(context.Context, *RegisterWebhookRequest) (*RegisterWebhookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterWebhook not implemented")
+}
+func (UnimplementedChatServiceServer) DeleteWebhook(context.Context, *DeleteWebhookRequest) (*ChatEmpty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteWebhook not implemented")
+}
+func (UnimplementedChatServiceServer) ListWebhooks(context.Context, *ListWebhooksRequest) (*ListWebhooksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWebhooks not implemented")
+}
+func (UnimplementedChatServiceServer) CreateIncomingWebhook(context.Context, *CreateIncomingWebhookRequest) (*IncomingWebhookTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateIncomingWebhook not implemented")
+}
+func (UnimplementedChatServiceServer) RotateIncomingWebhook(context.Context, *IncomingWebhookRequest) (*IncomingWebhookTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RotateIncomingWebhook not implemented")
+}
+func (UnimplementedChatServiceServer) RevokeIncomingWebhook(context.Context, *IncomingWebhookRequest) (*ChatEmpty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeIncomingWebhook not implemented")
+}
+func (UnimplementedChatServiceServer) ReportMessage(context.Context, *ReportMessageRequest) (*ChatEmpty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportMessage not implemented")
+}
+func (UnimplementedChatServiceServer) SetModerationSettings(context.Context, *ModerationSettingsRequest) (*ChatEmpty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetModerationSettings not implemented")
+}
+func (UnimplementedChatServiceServer) GetFlaggedMessages(context.Context, *GetFlaggedMessagesRequest) (*GetFlaggedMessagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFlaggedMessages not implemented")
+}
+func (UnimplementedChatServiceServer) ReviewMessage(context.Context, *ReviewMessageRequest) (*ChatEmpty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReviewMessage not implemented")
+}
+func (UnimplementedChatServiceServer) BanUser(context.Context, *BanUserRequest) (*ChatEmpty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BanUser not implemented")
+}
+func (UnimplementedChatServiceServer) UnbanUser(context.Context, *BanUserRequest) (*ChatEmpty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnbanUser not implemented")
+}
+func (UnimplementedChatServiceServer) SetRateLimits(context.Context, *SetRateLimitsRequest) (*ChatEmpty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetRateLimits not implemented")
+}
+func (UnimplementedChatServiceServer) GetRateLimits(context.Context, *GetRateLimitsRequest) (*RateLimits, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRateLimits not implemented")
+}
 func (UnimplementedChatServiceServer) mustEmbedUnimplementedChatServiceServer() {}
 func (UnimplementedChatServiceServer) testEmbeddedByValue()                     {}
 
@@ -276,6 +583,337 @@ func _ChatService_CancelSendMessage_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ChatService_CreatePoll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreatePollRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).CreatePoll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_CreatePoll_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).CreatePoll(ctx, req.(*CreatePollRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_Vote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).Vote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_Vote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).Vote(ctx, req.(*VoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_ClosePoll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClosePollRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).ClosePoll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_ClosePoll_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).ClosePoll(ctx, req.(*ClosePollRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_AddBot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddBotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).AddBot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_AddBot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).AddBot(ctx, req.(*AddBotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_BotConnect_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ChatServiceServer).BotConnect(&grpc.GenericServerStream[BotRequest, Message]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ChatService_BotConnectServer = grpc.BidiStreamingServer[BotRequest, Message]
+
+func _ChatService_RegisterWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).RegisterWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_RegisterWebhook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).RegisterWebhook(ctx, req.(*RegisterWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_DeleteWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).DeleteWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_DeleteWebhook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).DeleteWebhook(ctx, req.(*DeleteWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_ListWebhooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWebhooksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).ListWebhooks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_ListWebhooks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).ListWebhooks(ctx, req.(*ListWebhooksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_CreateIncomingWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateIncomingWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).CreateIncomingWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_CreateIncomingWebhook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).CreateIncomingWebhook(ctx, req.(*CreateIncomingWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_RotateIncomingWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IncomingWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).RotateIncomingWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_RotateIncomingWebhook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).RotateIncomingWebhook(ctx, req.(*IncomingWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_RevokeIncomingWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IncomingWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).RevokeIncomingWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_RevokeIncomingWebhook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).RevokeIncomingWebhook(ctx, req.(*IncomingWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_ReportMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).ReportMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_ReportMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).ReportMessage(ctx, req.(*ReportMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_SetModerationSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ModerationSettingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).SetModerationSettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_SetModerationSettings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).SetModerationSettings(ctx, req.(*ModerationSettingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_GetFlaggedMessages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFlaggedMessagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).GetFlaggedMessages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_GetFlaggedMessages_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).GetFlaggedMessages(ctx, req.(*GetFlaggedMessagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_ReviewMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReviewMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).ReviewMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_ReviewMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).ReviewMessage(ctx, req.(*ReviewMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_BanUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BanUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).BanUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_BanUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).BanUser(ctx, req.(*BanUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_UnbanUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BanUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).UnbanUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_UnbanUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).UnbanUser(ctx, req.(*BanUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_SetRateLimits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetRateLimitsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).SetRateLimits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_SetRateLimits_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).SetRateLimits(ctx, req.(*SetRateLimitsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_GetRateLimits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRateLimitsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).GetRateLimits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_GetRateLimits_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).GetRateLimits(ctx, req.(*GetRateLimitsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ChatService_ServiceDesc is the grpc.ServiceDesc for ChatService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -303,6 +941,78 @@ var ChatService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CancelSendMessage",
 			Handler:    _ChatService_CancelSendMessage_Handler,
 		},
+		{
+			MethodName: "CreatePoll",
+			Handler:    _ChatService_CreatePoll_Handler,
+		},
+		{
+			MethodName: "Vote",
+			Handler:    _ChatService_Vote_Handler,
+		},
+		{
+			MethodName: "ClosePoll",
+			Handler:    _ChatService_ClosePoll_Handler,
+		},
+		{
+			MethodName: "AddBot",
+			Handler:    _ChatService_AddBot_Handler,
+		},
+		{
+			MethodName: "RegisterWebhook",
+			Handler:    _ChatService_RegisterWebhook_Handler,
+		},
+		{
+			MethodName: "DeleteWebhook",
+			Handler:    _ChatService_DeleteWebhook_Handler,
+		},
+		{
+			MethodName: "ListWebhooks",
+			Handler:    _ChatService_ListWebhooks_Handler,
+		},
+		{
+			MethodName: "CreateIncomingWebhook",
+			Handler:    _ChatService_CreateIncomingWebhook_Handler,
+		},
+		{
+			MethodName: "RotateIncomingWebhook",
+			Handler:    _ChatService_RotateIncomingWebhook_Handler,
+		},
+		{
+			MethodName: "RevokeIncomingWebhook",
+			Handler:    _ChatService_RevokeIncomingWebhook_Handler,
+		},
+		{
+			MethodName: "ReportMessage",
+			Handler:    _ChatService_ReportMessage_Handler,
+		},
+		{
+			MethodName: "SetModerationSettings",
+			Handler:    _ChatService_SetModerationSettings_Handler,
+		},
+		{
+			MethodName: "GetFlaggedMessages",
+			Handler:    _ChatService_GetFlaggedMessages_Handler,
+		},
+		{
+			MethodName: "ReviewMessage",
+			Handler:    _ChatService_ReviewMessage_Handler,
+		},
+		{
+			MethodName: "BanUser",
+			Handler:    _ChatService_BanUser_Handler,
+		},
+		{
+			MethodName: "UnbanUser",
+			Handler:    _ChatService_UnbanUser_Handler,
+		},
+		{
+			MethodName: "SetRateLimits",
+			Handler:    _ChatService_SetRateLimits_Handler,
+		},
+		{
+			MethodName: "GetRateLimits",
+			Handler:    _ChatService_GetRateLimits_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -310,6 +1020,12 @@ var ChatService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _ChatService_Connect_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "BotConnect",
+			Handler:       _ChatService_BotConnect_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "proto_files/chat.proto",
 }